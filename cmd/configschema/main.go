@@ -0,0 +1,162 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/blend/go-sdk/configutil"
+	"github.com/blend/go-sdk/db"
+	"github.com/blend/go-sdk/logger"
+	"github.com/blend/go-sdk/oauth"
+	"github.com/blend/go-sdk/redis"
+	"github.com/blend/go-sdk/vault"
+	"github.com/blend/go-sdk/web"
+)
+
+// sdkConfigs are the sdk config types schemas can be generated for by name.
+var sdkConfigs = map[string]func() configutil.Any{
+	"db":     func() configutil.Any { return new(db.Config) },
+	"logger": func() configutil.Any { return new(logger.Config) },
+	"oauth":  func() configutil.Any { return new(oauth.Config) },
+	"redis":  func() configutil.Any { return new(redis.Config) },
+	"vault":  func() configutil.Any { return new(vault.Config) },
+	"web":    func() configutil.Any { return new(web.Config) },
+}
+
+func sdkConfigNames() string {
+	names := make([]string, 0, len(sdkConfigs))
+	for name := range sdkConfigs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func generateCommand() *cobra.Command {
+	var flagFormat, flagTag string
+	generate := &cobra.Command{
+		Use:   "generate <config>",
+		Short: "Generate the schema of an sdk config.",
+		Long:  fmt.Sprintf("Generate the json schema or markdown reference of an sdk config; one of: %s.", sdkConfigNames()),
+		Example: `
+# Generate the json schema for the db config
+configschema generate db > db.schema.json
+
+# Generate a markdown reference table for the web config
+configschema generate web --format=markdown`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			newConfig, ok := sdkConfigs[args[0]]
+			if !ok {
+				return fmt.Errorf("unknown config %q; should be one of: %s", args[0], sdkConfigNames())
+			}
+			schema := configutil.GenerateSchema(newConfig(), configutil.OptSchemaTitle(args[0]), configutil.OptSchemaTag(flagTag))
+			switch flagFormat {
+			case "json":
+				contents, err := json.MarshalIndent(schema, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(contents))
+			case "markdown":
+				fmt.Fprint(cmd.OutOrStdout(), schema.Markdown())
+			default:
+				return fmt.Errorf("unknown format %q; should be one of: json, markdown", flagFormat)
+			}
+			return nil
+		},
+	}
+	generate.Flags().StringVarP(&flagFormat, "format", "f", "json", "The output format; one of: json, markdown")
+	generate.Flags().StringVar(&flagTag, "tag", "yaml", "The struct tag keys are named by; one of: yaml, json")
+	return generate
+}
+
+func validateCommand() *cobra.Command {
+	var flagSchema string
+	validate := &cobra.Command{
+		Use:   "validate <file> [files...]",
+		Short: "Validate config files against a schema.",
+		Long: `Validate yaml, json or toml config files against a json schema generated with configutil.GenerateSchema,
+or against the schema of an sdk config by name. Unknown keys and values of the wrong type are reported.
+
+Json config files are read by json tags, so a schema file for them must be generated with the json tag.`,
+		Example: `
+# Validate a config file against a service's schema
+configschema validate --schema=config.schema.json config.yml
+
+# Validate a db config file
+configschema validate --schema=db db.yml`,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var failed int
+			for _, path := range args {
+				schema, err := readSchema(flagSchema, configutil.SchemaTagForPath(path))
+				if err != nil {
+					return err
+				}
+				violations, err := schema.ValidateFile(path)
+				if err != nil {
+					return err
+				}
+				for _, violation := range violations {
+					fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", path, violation.String())
+				}
+				if len(violations) > 0 {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d config files failed validation", failed, len(args))
+			}
+			return nil
+		},
+	}
+	validate.Flags().StringVarP(&flagSchema, "schema", "s", "", fmt.Sprintf("The json schema file, or an sdk config; one of: %s", sdkConfigNames()))
+	_ = validate.MarkFlagRequired("schema")
+	return validate
+}
+
+// readSchema reads a json schema file, or generates the schema for an sdk config by name with a given struct tag.
+func readSchema(schemaPath, tag string) (*configutil.Schema, error) {
+	if newConfig, ok := sdkConfigs[schemaPath]; ok {
+		return configutil.GenerateSchema(newConfig(), configutil.OptSchemaTag(tag)), nil
+	}
+	contents, err := os.ReadFile(schemaPath)
+	if err != nil {
+		return nil, err
+	}
+	var schema configutil.Schema
+	if err = json.Unmarshal(contents, &schema); err != nil {
+		return nil, fmt.Errorf("invalid schema %s: %w", schemaPath, err)
+	}
+	return &schema, nil
+}
+
+func command() *cobra.Command {
+	root := &cobra.Command{
+		Use:   "configschema",
+		Short: "Generate config schemas and validate config files.",
+		Long:  "Generate json schemas and markdown references for sdk configs, and validate config files against schemas.",
+	}
+	root.AddCommand(generateCommand(), validateCommand())
+	return root
+}
+
+func main() {
+	if err := command().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
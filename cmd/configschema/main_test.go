@@ -0,0 +1,107 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/configutil"
+	"github.com/blend/go-sdk/ex"
+)
+
+func execute(args ...string) (string, error) {
+	output := new(bytes.Buffer)
+	cmd := command()
+	cmd.SetOut(output)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return output.String(), err
+}
+
+func writeFile(t *testing.T, dir, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestGenerate(t *testing.T) {
+	its := assert.New(t)
+
+	output, err := execute("generate", "web")
+	its.Nil(err)
+	var schema configutil.Schema
+	its.Nil(json.Unmarshal([]byte(output), &schema))
+	its.Equal("web", schema.Title)
+	its.Equal(configutil.SchemaTypeString, schema.Properties["shutdownGracePeriod"].Type)
+
+	output, err = execute("generate", "web", "--tag=json")
+	its.Nil(err)
+	its.Nil(json.Unmarshal([]byte(output), &schema))
+	its.Equal(configutil.SchemaTypeInteger, schema.Properties["shutdownGracePeriod"].Type)
+
+	output, err = execute("generate", "web", "--format=markdown")
+	its.Nil(err)
+	its.Contains(output, "shutdownGracePeriod")
+
+	_, err = execute("generate", "bogus")
+	its.NotNil(err)
+	_, err = execute("generate", "web", "--format=bogus")
+	its.NotNil(err)
+}
+
+func TestValidate(t *testing.T) {
+	its := assert.New(t)
+
+	dir := t.TempDir()
+	validYAML := writeFile(t, dir, "valid.yml", "shutdownGracePeriod: 5s\n")
+	validJSON := writeFile(t, dir, "valid.json", `{"shutdownGracePeriod":5000000000}`)
+	invalidJSON := writeFile(t, dir, "invalid.json", `{"shutdownGracePeriod":"5s","bogus":true}`)
+
+	output, err := execute("validate", "--schema=web", validYAML, validJSON)
+	its.Nil(err)
+	its.Empty(output)
+
+	output, err = execute("validate", "--schema=web", validYAML, invalidJSON)
+	its.NotNil(err)
+	its.Contains(err.Error(), "1 of 2 config files failed validation")
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	its.Equal([]string{
+		invalidJSON + ": bogus: unknown key",
+		invalidJSON + ": shutdownGracePeriod: expected an integer; got a string",
+	}, lines)
+}
+
+func TestValidateSchemaFile(t *testing.T) {
+	its := assert.New(t)
+
+	dir := t.TempDir()
+	schemaOutput, err := execute("generate", "web")
+	its.Nil(err)
+	schemaPath := writeFile(t, dir, "web.schema.json", schemaOutput)
+	config := writeFile(t, dir, "config.yml", "port: 8080\n")
+
+	_, err = execute("validate", "--schema="+schemaPath, config)
+	its.Nil(err)
+
+	// a json config file must be validated against a schema generated with the json tag
+	_, err = execute("validate", "--schema="+schemaPath, writeFile(t, dir, "config.json", `{"port":8080}`))
+	its.True(ex.Is(err, configutil.ErrSchemaTagMismatch))
+
+	_, err = execute("validate", "--schema="+filepath.Join(dir, "missing.json"), config)
+	its.NotNil(err)
+}
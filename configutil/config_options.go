@@ -9,6 +9,7 @@ package configutil
 
 import (
 	"context"
+	"flag"
 	"io"
 
 	"github.com/blend/go-sdk/env"
@@ -21,6 +22,12 @@ type ConfigOptions struct {
 	Contents  []ConfigContents
 	FilePaths []string
 	Env       env.Vars
+	// Flags are command line flags applied as the last layer by `ReadLayered`.
+	Flags *flag.FlagSet
+	// SliceMerge is how `ReadLayered` merges slices found in multiple layers.
+	SliceMerge SliceMerge
+	// Provenance is set by `ReadLayered` to the source of each config value, if it is not nil.
+	Provenance *Provenance
 }
 
 // ConfigContents are literal contents to read from.
@@ -30,6 +37,8 @@ type ConfigContents struct {
 }
 
 // Background yields a context for a config options set.
+//
+// Each call returns a context with a new read cache.
 func (co ConfigOptions) Background() context.Context {
 	var background context.Context
 	if co.Context != nil {
@@ -40,5 +49,7 @@ func (co ConfigOptions) Background() context.Context {
 
 	background = WithConfigPaths(background, co.FilePaths)
 	background = env.WithVars(background, co.Env)
+	background = WithLogger(background, co.Log)
+	background = WithReadCache(background, NewReadCache())
 	return background
 }
@@ -16,6 +16,8 @@ const (
 	ExtensionYAML = ".yaml"
 	// ExtensionYML is a file extension.
 	ExtensionYML = ".yml"
+	// ExtensionTOML is a file extension.
+	ExtensionTOML = ".toml"
 )
 
 var (
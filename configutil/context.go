@@ -27,3 +27,37 @@ func GetConfigPaths(ctx context.Context) []string {
 	}
 	return nil
 }
+
+type loggerKey struct{}
+
+// WithLogger adds the configutil logger to the context.
+func WithLogger(ctx context.Context, log Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, log)
+}
+
+// GetLogger gets the configutil logger from a context.
+func GetLogger(ctx context.Context) Logger {
+	if raw := ctx.Value(loggerKey{}); raw != nil {
+		if typed, ok := raw.(Logger); ok {
+			return typed
+		}
+	}
+	return nil
+}
+
+type readCacheKey struct{}
+
+// WithReadCache adds a read cache to the context.
+func WithReadCache(ctx context.Context, cache *ReadCache) context.Context {
+	return context.WithValue(ctx, readCacheKey{}, cache)
+}
+
+// GetReadCache gets the read cache from a context.
+func GetReadCache(ctx context.Context) *ReadCache {
+	if raw := ctx.Value(readCacheKey{}); raw != nil {
+		if typed, ok := raw.(*ReadCache); ok {
+			return typed
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package configutil
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+// Deserializer decodes config contents into a reference.
+type Deserializer func(r io.Reader, ref Any) error
+
+var (
+	deserializersMu sync.RWMutex
+	deserializers   = map[string]Deserializer{
+		ExtensionJSON: DeserializeJSON,
+		ExtensionYAML: DeserializeYAML,
+		ExtensionYML:  DeserializeYAML,
+		ExtensionTOML: DeserializeTOML,
+	}
+)
+
+// RegisterDeserializer registers a deserializer for a file extension, e.g. `.ini`,
+// replacing any deserializer already registered for the extension.
+//
+// Registered extensions are read by `Read` and `ReadLayered` from config paths and contents.
+// `ReadLayered` merges each layer by deserializing it into a `map[string]interface{}`, so
+// deserializers for formats other than json and yaml must support decoding into maps.
+func RegisterDeserializer(ext string, deserializer Deserializer) {
+	deserializersMu.Lock()
+	defer deserializersMu.Unlock()
+	deserializers[normalizeExtension(ext)] = deserializer
+}
+
+// GetDeserializer returns the deserializer registered for a file extension.
+func GetDeserializer(ext string) (Deserializer, bool) {
+	deserializersMu.RLock()
+	defer deserializersMu.RUnlock()
+	deserializer, ok := deserializers[normalizeExtension(ext)]
+	return deserializer, ok
+}
+
+// DeserializerExtensions returns the file extensions that have registered deserializers, sorted.
+func DeserializerExtensions() []string {
+	deserializersMu.RLock()
+	defer deserializersMu.RUnlock()
+	output := make([]string, 0, len(deserializers))
+	for ext := range deserializers {
+		output = append(output, ext)
+	}
+	sort.Strings(output)
+	return output
+}
+
+// DeserializeJSON decodes json contents into a reference.
+func DeserializeJSON(r io.Reader, ref Any) error {
+	return ex.New(json.NewDecoder(r).Decode(ref))
+}
+
+// DeserializeYAML decodes yaml contents into a reference.
+func DeserializeYAML(r io.Reader, ref Any) error {
+	return ex.New(yaml.NewDecoder(r).Decode(ref))
+}
+
+// normalizeExtension makes sure the extension starts with a "." and is lower case.
+func normalizeExtension(ext string) string {
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return strings.ToLower(ext)
+}
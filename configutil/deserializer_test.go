@@ -0,0 +1,71 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package configutil
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/env"
+)
+
+// deserializeProperties is a test deserializer for `key=value` lines.
+func deserializeProperties(r io.Reader, ref Any) error {
+	values := make(map[string]interface{})
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if key, value, ok := strings.Cut(scanner.Text(), "="); ok {
+			values[key] = value
+		}
+	}
+	var node yaml.Node
+	if err := node.Encode(values); err != nil {
+		return err
+	}
+	return node.Decode(ref)
+}
+
+func TestRegisterDeserializer(t *testing.T) {
+	its := assert.New(t)
+
+	_, ok := GetDeserializer("properties")
+	its.False(ok)
+	RegisterDeserializer("PROPERTIES", deserializeProperties)
+	defer func() {
+		deserializersMu.Lock()
+		delete(deserializers, ".properties")
+		deserializersMu.Unlock()
+	}()
+	_, ok = GetDeserializer(".properties")
+	its.True(ok)
+	its.Equal([]string{".json", ".properties", ".toml", ".yaml", ".yml"}, DeserializerExtensions())
+
+	var cfg config
+	_, err := Read(&cfg, OptUnsetPaths(), OptEnv(env.Vars{}), OptAddContentString("properties", "env=test\nother=foo"))
+	its.Nil(err)
+	its.Equal("test", cfg.Environment)
+	its.Equal("foo", cfg.Other)
+
+	var layered config
+	var provenance Provenance
+	_, err = ReadLayered(&layered, OptUnsetPaths(), OptEnv(env.Vars{}), OptProvenance(&provenance),
+		OptAddContentString("yml", "env: first\nbase: base"),
+		OptAddContentString("properties", "env=second"),
+	)
+	its.Nil(err)
+	its.Equal("second", layered.Environment)
+	its.Equal("base", layered.Base)
+	source, ok := provenance.Source("env")
+	its.True(ok)
+	its.Equal("contents properties", source.String())
+}
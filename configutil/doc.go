@@ -41,5 +41,24 @@ If we want to also read from the environment, we can do so by adding a resolver,
 In the above, the environment variable `BIND_ADDR` takes precedence over the string value found in any configuration file(s).
 
 Note, we also "resolve" each of the attached configs first, in case they also have environment variables they read from etc.
+
+Alternatively, `configutil.ReadLayered` deep merges explicit layers of defaults, the config file(s), environment specific
+config file(s) (e.g. `config.prod.yml` when `SERVICE_ENV=prod`), environment variables (by `env` tags) and command line flags,
+recording where each value came from with `configutil.OptProvenance(&provenance)`. `configutil.Explain(&cfg, provenance)`
+reports each value and its source, with secrets redacted.
+
+To reload a config while running, `configutil.NewWatcher` re-reads the config when its config files (or environment specific
+config files) are created or change, or when the process receives one of the signals set with `configutil.OptWatcherSignals`
+(`SIGHUP` by default),
+validates it, and only then swaps it in and notifies subscribers.
+
+Secrets can be read from vault with the value sources in `configutil/vaultconfig`.
+
+Configs can be json, yaml or toml, by file extension. Other formats can be supported by registering a
+deserializer for their extension with `configutil.RegisterDeserializer`.
+
+`configutil.GenerateSchema(&cfg)` generates a json schema for a config type from its tags, with defaults from its `Resolve`,
+and `Markdown()` renders the schema as a reference table. `Schema.ValidateFile` reports unknown keys and values of the wrong
+type in a config file; the `cmd/configschema` tool does the same from the command line.
 */
 package configutil // import "github.com/blend/go-sdk/configutil"
@@ -10,6 +10,7 @@ package configutil
 import (
 	"bytes"
 	"context"
+	"flag"
 	"io"
 
 	"github.com/blend/go-sdk/env"
@@ -111,3 +112,31 @@ func OptEnv(vars env.Vars) Option {
 		return nil
 	}
 }
+
+// OptFlags sets the command line flags that `ReadLayered` applies as the last layer.
+//
+// Only flags that were set are applied, and flags are matched to config fields
+// by their dot separated yaml path, e.g. `-db.host`.
+func OptFlags(flags *flag.FlagSet) Option {
+	return func(co *ConfigOptions) error {
+		co.Flags = flags
+		return nil
+	}
+}
+
+// OptProvenance sets a reference that `ReadLayered` sets to the source of each config value,
+// e.g. to report them with `Explain`.
+func OptProvenance(provenance *Provenance) Option {
+	return func(co *ConfigOptions) error {
+		co.Provenance = provenance
+		return nil
+	}
+}
+
+// OptSliceMerge sets how `ReadLayered` merges slices found in multiple layers.
+func OptSliceMerge(sliceMerge SliceMerge) Option {
+	return func(co *ConfigOptions) error {
+		co.SliceMerge = sliceMerge
+		return nil
+	}
+}
@@ -0,0 +1,314 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package configutil
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RedactedValue is the value secrets are replaced with by `Explain`.
+const RedactedValue = "[REDACTED]"
+
+// SecretTag is the struct tag that marks a field as a secret, e.g. `secret:"password"`.
+//
+// It is the same tag `vault` uses to map fields to secret keys; a value of `-` is not a secret.
+const SecretTag = "secret"
+
+var (
+	// SecretKeys are the trailing words of config keys that are treated as secrets by `Explain`
+	// even if the field is not tagged, e.g. `password` or `apiToken`, but not `maxTokens` or `tokenURL`.
+	// Keys are split into words on camel case and `_`, `-` separators, and matched case insensitively.
+	SecretKeys = []string{"password", "secret", "token", "apikey", "api_key", "privatekey", "private_key"}
+)
+
+// SourceKind is a kind of config source.
+type SourceKind string
+
+// SourceKinds
+const (
+	SourceKindDefaults SourceKind = "defaults"
+	SourceKindContents SourceKind = "contents"
+	SourceKindFile     SourceKind = "file"
+	SourceKindEnv      SourceKind = "env"
+	SourceKindFlag     SourceKind = "flag"
+)
+
+// Source is where a config value came from.
+type Source struct {
+	// Kind is the kind of source.
+	Kind SourceKind
+	// Name is the file path, environment variable or flag name, depending on the kind.
+	Name string
+}
+
+// String returns a description of the source, e.g. `config.prod.yml` or `PORT env`.
+func (s Source) String() string {
+	switch s.Kind {
+	case SourceKindFile:
+		return s.Name
+	case SourceKindEnv:
+		return s.Name + " env"
+	case SourceKindFlag:
+		return "-" + s.Name + " flag"
+	case SourceKindContents:
+		return "contents " + s.Name
+	default:
+		return string(s.Kind)
+	}
+}
+
+// Provenance is the source of each config value, keyed by its dot separated yaml path, e.g. `db.host`.
+//
+// Slices are treated as single values.
+type Provenance map[string]Source
+
+// Source returns the source of the value at a path, or of its closest parent path that has a source.
+func (p Provenance) Source(path string) (Source, bool) {
+	for {
+		if source, ok := p[path]; ok {
+			return source, true
+		}
+		index := strings.LastIndex(path, ".")
+		if index < 0 {
+			return Source{}, false
+		}
+		path = path[:index]
+	}
+}
+
+// record sets the source of the value at a path, replacing the sources of any values below the path.
+func (p Provenance) record(path string, node *yaml.Node, source Source) {
+	if p == nil {
+		return
+	}
+	for existing := range p {
+		if existing == path || strings.HasPrefix(existing, path+".") {
+			delete(p, existing)
+		}
+	}
+	if node != nil && node.Kind == yaml.MappingNode {
+		for index := 0; index+1 < len(node.Content); index += 2 {
+			p.record(joinPath(path, node.Content[index].Value), node.Content[index+1], source)
+		}
+		return
+	}
+	p[path] = source
+}
+
+// Explain returns a report of each value of a config and where it came from, one per line, e.g.
+//
+//	db.host = db.example.com from config.prod.yml
+//	db.password = [REDACTED] from DB_PASSWORD env
+//	port = 8080 from PORT env
+//
+// Sources are those recorded by `ReadLayered` (see `OptProvenance`); values set elsewhere (e.g. by a `Resolve` method)
+// are reported as from an unknown source. Values of fields with a `secret` tag, or with keys that match `SecretKeys`,
+// are redacted.
+func Explain(ref Any, provenance Provenance) string {
+	var node yaml.Node
+	if err := node.Encode(ref); err != nil {
+		return fmt.Sprintf("error: %v\n", err)
+	}
+	secrets := make(map[string]bool)
+	walkFields(reflect.TypeOf(ref), "", true, func(path string, field reflect.StructField) {
+		if tag, ok := field.Tag.Lookup(SecretTag); ok && tag != "-" {
+			secrets[path] = true
+		}
+	})
+
+	output := new(strings.Builder)
+	walkLeaves(unwrapDocument(&node), "", func(path string, leaf *yaml.Node) {
+		value := formatLeaf(leaf)
+		if value != "" && isSecretPath(path, secrets) {
+			value = RedactedValue
+		}
+		source := "unknown source"
+		if found, ok := provenance.Source(path); ok {
+			source = found.String()
+		}
+		fmt.Fprintf(output, "%s = %s from %s\n", path, value, source)
+	})
+	return output.String()
+}
+
+// isSecretPath returns if a path or any of its parent paths is a secret.
+func isSecretPath(path string, secrets map[string]bool) bool {
+	for {
+		if secrets[path] {
+			return true
+		}
+		key := path
+		if index := strings.LastIndex(path, "."); index >= 0 {
+			key = path[index+1:]
+		}
+		if isSecretKey(key) {
+			return true
+		}
+		index := strings.LastIndex(path, ".")
+		if index < 0 {
+			return false
+		}
+		path = path[:index]
+	}
+}
+
+// isSecretKey returns if the trailing words of a key match one of the `SecretKeys`.
+func isSecretKey(key string) bool {
+	words := splitKeyWords(key)
+	for index := range words {
+		suffix := strings.Join(words[index:], "")
+		for _, secretKey := range SecretKeys {
+			if suffix == strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(secretKey)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitKeyWords splits a key into lower case words on camel case and `_`, `-` separators,
+// e.g. `dbAPIToken` into `db`, `api`, `token`.
+func splitKeyWords(key string) (words []string) {
+	runes := []rune(key)
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = nil
+		}
+	}
+	for index, r := range runes {
+		switch {
+		case r == '_' || r == '-':
+			flush()
+			continue
+		case unicode.IsUpper(r) && index > 0:
+			previousLower := !unicode.IsUpper(runes[index-1])
+			nextLower := index+1 < len(runes) && unicode.IsLower(runes[index+1])
+			if previousLower || nextLower {
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+	return
+}
+
+// walkLeaves calls a function for each scalar or sequence within a node with its dot separated path.
+func walkLeaves(node *yaml.Node, path string, fn func(string, *yaml.Node)) {
+	if node.Kind == yaml.MappingNode {
+		for index := 0; index+1 < len(node.Content); index += 2 {
+			walkLeaves(node.Content[index+1], joinPath(path, node.Content[index].Value), fn)
+		}
+		return
+	}
+	if path != "" {
+		fn(path, node)
+	}
+}
+
+// formatLeaf formats a leaf value on a single line.
+func formatLeaf(node *yaml.Node) string {
+	if node.Kind == yaml.ScalarNode {
+		if node.Tag == "!!null" {
+			return ""
+		}
+		return node.Value
+	}
+	flow := *node
+	flow.Style = yaml.FlowStyle
+	contents, err := yaml.Marshal(&flow)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(contents))
+}
+
+var typeTime = reflect.TypeOf(time.Time{})
+
+// walkFields calls a function for each exported field of a struct type, and its nested struct fields,
+// with the dot separated yaml path of the field.
+func walkFields(t reflect.Type, prefix string, followPointers bool, fn func(string, reflect.StructField)) {
+	walkFieldsVisited(t, prefix, followPointers, fn, make(map[reflect.Type]bool))
+}
+
+func walkFieldsVisited(t reflect.Type, prefix string, followPointers bool, fn func(string, reflect.StructField), visited map[reflect.Type]bool) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || t == typeTime || visited[t] {
+		return
+	}
+	visited[t] = true
+	defer delete(visited, t)
+
+	for index := 0; index < t.NumField(); index++ {
+		field := t.Field(index)
+		if field.PkgPath != "" {
+			continue
+		}
+		name, inline, skip := yamlFieldName(field)
+		if skip {
+			continue
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr && !followPointers {
+			fieldType = nil
+		}
+		if inline {
+			if fieldType != nil {
+				walkFieldsVisited(fieldType, prefix, followPointers, fn, visited)
+			}
+			continue
+		}
+		path := joinPath(prefix, name)
+		fn(path, field)
+		if fieldType != nil {
+			walkFieldsVisited(fieldType, path, followPointers, fn, visited)
+		}
+	}
+}
+
+// yamlFieldName returns the yaml key of a field, following the rules of the yaml decoder.
+func yamlFieldName(field reflect.StructField) (name string, inline, skip bool) {
+	tag := field.Tag.Get("yaml")
+	if tag == "-" {
+		return "", false, true
+	}
+	pieces := strings.Split(tag, ",")
+	for _, flag := range pieces[1:] {
+		if flag == "inline" {
+			inline = true
+		}
+	}
+	if pieces[0] != "" {
+		return pieces[0], inline, false
+	}
+	return strings.ToLower(field.Name), inline, false
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func unwrapDocument(node *yaml.Node) *yaml.Node {
+	if node != nil && node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		return node.Content[0]
+	}
+	return node
+}
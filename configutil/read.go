@@ -8,13 +8,9 @@ Use of this source code is governed by a MIT license that can be found in the LI
 package configutil
 
 import (
-	"encoding/json"
 	"io"
 	"os"
 	"path/filepath"
-	"strings"
-
-	"gopkg.in/yaml.v3"
 
 	"github.com/blend/go-sdk/env"
 	"github.com/blend/go-sdk/ex"
@@ -113,20 +109,11 @@ func createConfigOptions(options ...Option) (configOptions ConfigOptions, err er
 	return
 }
 
-// deserialize deserializes a config.
+// deserialize deserializes a config with the deserializer registered for its extension.
 func deserialize(ext string, r io.Reader, ref Any) error {
-	// make sure the extension starts with a "."
-	if !strings.HasPrefix(ext, ".") {
-		ext = "." + ext
-	}
-
-	// based off the extension, use the appropriate deserializer
-	switch strings.ToLower(ext) {
-	case ExtensionJSON:
-		return ex.New(json.NewDecoder(r).Decode(ref))
-	case ExtensionYAML, ExtensionYML:
-		return ex.New(yaml.NewDecoder(r).Decode(ref))
-	default: // return an error if we're passed a weird extension
-		return ex.New(ErrInvalidConfigExtension, ex.OptMessagef("extension: %s", ext))
+	deserializer, ok := GetDeserializer(ext)
+	if !ok { // return an error if we're passed a weird extension
+		return ex.New(ErrInvalidConfigExtension, ex.OptMessagef("extension: %s", normalizeExtension(ext)))
 	}
+	return deserializer(r, ref)
 }
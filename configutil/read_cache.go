@@ -0,0 +1,47 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package configutil
+
+import "sync"
+
+// NewReadCache returns a new read cache.
+func NewReadCache() *ReadCache {
+	return &ReadCache{
+		values: make(map[string]interface{}),
+	}
+}
+
+// ReadCache caches values fetched by value sources within a single read.
+//
+// A new cache is added to the context passed to `Resolve` by each `Read` (see `GetReadCache`),
+// so sources that fetch remote values (e.g. a vault secret with many fields) only fetch each value once per read.
+type ReadCache struct {
+	mu     sync.Mutex
+	values map[string]interface{}
+}
+
+// Get returns the cached value for a key, calling the fetch function and caching its value
+// if the key is not cached. Errors are not cached.
+//
+// It also returns whether the value was cached. A nil cache always calls the fetch function.
+func (rc *ReadCache) Get(key string, fetch func() (interface{}, error)) (value interface{}, cached bool, err error) {
+	if rc == nil {
+		value, err = fetch()
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if value, cached = rc.values[key]; cached {
+		return
+	}
+	if value, err = fetch(); err != nil {
+		return
+	}
+	rc.values[key] = value
+	return
+}
@@ -0,0 +1,60 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package configutil
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/env"
+)
+
+func TestReadCache(t *testing.T) {
+	its := assert.New(t)
+
+	var fetches int
+	fetch := func() (interface{}, error) {
+		fetches++
+		return fetches, nil
+	}
+
+	cache := NewReadCache()
+	value, cached, err := cache.Get("key", fetch)
+	its.Nil(err)
+	its.False(cached)
+	its.Equal(1, value)
+
+	value, cached, err = cache.Get("key", fetch)
+	its.Nil(err)
+	its.True(cached)
+	its.Equal(1, value)
+
+	_, _, err = cache.Get("error", func() (interface{}, error) { return nil, fmt.Errorf("error") })
+	its.NotNil(err)
+	_, cached, _ = cache.Get("error", fetch)
+	its.False(cached)
+
+	var nilCache *ReadCache
+	value, cached, err = nilCache.Get("key", fetch)
+	its.Nil(err)
+	its.False(cached)
+	its.Equal(3, value)
+}
+
+func TestConfigOptionsBackground(t *testing.T) {
+	its := assert.New(t)
+
+	options := ConfigOptions{Env: env.Vars{}}
+	first := options.Background()
+	its.NotNil(GetReadCache(first))
+	its.Nil(GetLogger(first))
+	its.True(GetReadCache(first) != GetReadCache(options.Background()))
+	its.Nil(GetReadCache(context.Background()))
+}
@@ -0,0 +1,276 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package configutil
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/blend/go-sdk/env"
+	"github.com/blend/go-sdk/ex"
+)
+
+// SliceMerge is how slices found in multiple layers are merged.
+type SliceMerge int
+
+// SliceMerge values.
+const (
+	// SliceMergeReplace replaces a slice with the slice from the later layer.
+	SliceMergeReplace SliceMerge = iota
+	// SliceMergeAppend appends the slice from the later layer.
+	SliceMergeAppend
+	// SliceMergeIndex deep merges the elements of the slices by index.
+	SliceMergeIndex
+)
+
+// ReadLayered reads a config from layers of sources, deep merging each layer over the previous layers,
+// returning the paths read from (in the order visited), and an error if there were any issues.
+/*
+The layers are, in order:
+
+- The defaults, i.e. the values of the ref before it is read.
+- Any literal contents (see `OptAddContent`).
+- Each config path that exists (see `OptPaths`), each followed by its environment specific path if it exists,
+  e.g. `config.prod.yml` after `config.yml` if the `SERVICE_ENV` environment variable is `prod`.
+- Environment variables, matched to fields by their `env` tags.
+- Command line flags that were set (see `OptFlags`), matched to fields by their dot separated yaml path, e.g. `-db.host`.
+
+Maps are merged key by key, and slices are replaced by default (see `OptSliceMerge`). Config files are
+merged as yaml (which json is a subset of), so fields are matched by their `yaml` tags.
+
+Where each value came from is recorded if `OptProvenance` is given, and can be reported with `Explain`:
+
+	var provenance configutil.Provenance
+	paths, err := configutil.ReadLayered(&cfg, configutil.OptFlags(flag.CommandLine), configutil.OptProvenance(&provenance))
+	...
+	fmt.Print(configutil.Explain(&cfg, provenance))
+
+As with `Read`, if the ref type is a `Resolver` the `Resolve(context.Context) error` method will be called
+after the layers are applied.
+*/
+func ReadLayered(ref Any, options ...Option) (paths []string, err error) {
+	var configOptions ConfigOptions
+	configOptions, err = createConfigOptions(options...)
+	if err != nil {
+		return
+	}
+
+	provenance := make(Provenance)
+	var merged yaml.Node
+	if err = merged.Encode(ref); err != nil {
+		err = ex.New(err)
+		return
+	}
+	provenance.record("", unwrapDocument(&merged), Source{Kind: SourceKindDefaults})
+	root := unwrapDocument(&merged)
+
+	for _, contents := range configOptions.Contents {
+		MaybeDebugf(configOptions.Log, "reading config contents with extension `%s`", contents.Ext)
+		var layer *yaml.Node
+		if layer, err = parseLayer(contents.Ext, contents.Contents); err != nil {
+			return
+		}
+		root = mergeLayer(root, layer, "", Source{Kind: SourceKindContents, Name: contents.Ext}, configOptions.SliceMerge, provenance)
+	}
+
+	serviceEnv := configOptions.Env.ServiceEnv()
+	for _, path := range configOptions.FilePaths {
+		if path == "" {
+			continue
+		}
+		layerPaths := []string{path}
+		if serviceEnv != "" {
+			layerPaths = append(layerPaths, EnvPath(path, serviceEnv))
+		}
+		for _, layerPath := range layerPaths {
+			MaybeDebugf(configOptions.Log, "checking for config path: %s", layerPath)
+			var layer *yaml.Node
+			layer, err = readLayer(layerPath)
+			if IsNotExist(err) {
+				err = nil
+				continue
+			}
+			if err != nil {
+				return
+			}
+			MaybeDebugf(configOptions.Log, "reading config path: %s", layerPath)
+			root = mergeLayer(root, layer, "", Source{Kind: SourceKindFile, Name: layerPath}, configOptions.SliceMerge, provenance)
+			paths = append(paths, layerPath)
+		}
+	}
+	if err = root.Decode(ref); err != nil {
+		err = ex.New(err)
+		return
+	}
+
+	if configOptions.Env != nil {
+		walkFields(reflect.TypeOf(ref), "", false, func(path string, field reflect.StructField) {
+			name := strings.Split(field.Tag.Get(env.ReflectTagName), ",")[0]
+			if name != "" && configOptions.Env.Has(name) {
+				provenance.record(path, nil, Source{Kind: SourceKindEnv, Name: name})
+			}
+		})
+		if err = configOptions.Env.ReadInto(ref); err != nil {
+			err = ex.New(err)
+			return
+		}
+	}
+
+	if configOptions.Flags != nil {
+		flags := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		configOptions.Flags.Visit(func(f *flag.Flag) {
+			setPath(flags, strings.Split(f.Name, "."), &yaml.Node{Kind: yaml.ScalarNode, Value: f.Value.String()})
+			provenance.record(f.Name, nil, Source{Kind: SourceKindFlag, Name: f.Name})
+		})
+		if err = flags.Decode(ref); err != nil {
+			err = ex.New(err)
+			return
+		}
+	}
+	if configOptions.Provenance != nil {
+		*configOptions.Provenance = provenance
+	}
+
+	if typed, ok := ref.(Resolver); ok {
+		MaybeDebugf(configOptions.Log, "calling config resolver")
+		if resolveErr := typed.Resolve(configOptions.Background()); resolveErr != nil {
+			MaybeErrorf(configOptions.Log, "calling resolver error: %+v", resolveErr)
+			err = resolveErr
+			return
+		}
+	}
+	return
+}
+
+// EnvPath returns the environment specific path for a config path, e.g. `config.prod.yml` for `config.yml`.
+func EnvPath(path, serviceEnv string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + serviceEnv + ext
+}
+
+// readLayer reads a config file as a yaml node.
+func readLayer(path string) (*yaml.Node, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, ex.New(err)
+	}
+	defer f.Close()
+	return parseLayer(filepath.Ext(path), f)
+}
+
+// parseLayer parses config contents as a yaml node; empty contents are an empty mapping.
+//
+// Json and yaml are parsed directly; other formats are deserialized into a map with their registered deserializer.
+func parseLayer(ext string, r io.Reader) (*yaml.Node, error) {
+	ext = normalizeExtension(ext)
+	switch ext {
+	case ExtensionJSON, ExtensionYAML, ExtensionYML:
+		var node yaml.Node
+		if err := yaml.NewDecoder(r).Decode(&node); err != nil {
+			if errors.Is(err, io.EOF) {
+				return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+			}
+			return nil, ex.New(err)
+		}
+		return unwrapDocument(&node), nil
+	}
+	deserializer, ok := GetDeserializer(ext)
+	if !ok {
+		return nil, ex.New(ErrInvalidConfigExtension, ex.OptMessagef("extension: %s", ext))
+	}
+	contents := make(map[string]interface{})
+	if err := deserializer(r, &contents); err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	if err := node.Encode(contents); err != nil {
+		return nil, ex.New(err)
+	}
+	return unwrapDocument(&node), nil
+}
+
+// mergeLayer deep merges a layer node over a node, recording the source of the merged values.
+func mergeLayer(dst, src *yaml.Node, path string, source Source, sliceMerge SliceMerge, provenance Provenance) *yaml.Node {
+	if dst == nil {
+		provenance.record(path, src, source)
+		return src
+	}
+	switch {
+	case dst.Kind == yaml.MappingNode && src.Kind == yaml.MappingNode:
+		for index := 0; index+1 < len(src.Content); index += 2 {
+			key, value := src.Content[index], src.Content[index+1]
+			childPath := joinPath(path, key.Value)
+			if existing := mappingIndex(dst, key.Value); existing >= 0 {
+				dst.Content[existing+1] = mergeLayer(dst.Content[existing+1], value, childPath, source, sliceMerge, provenance)
+				continue
+			}
+			dst.Content = append(dst.Content, key, value)
+			provenance.record(childPath, value, source)
+		}
+		return dst
+	case dst.Kind == yaml.SequenceNode && src.Kind == yaml.SequenceNode && sliceMerge == SliceMergeAppend:
+		dst.Content = append(dst.Content, src.Content...)
+		provenance.record(path, dst, source)
+		return dst
+	case dst.Kind == yaml.SequenceNode && src.Kind == yaml.SequenceNode && sliceMerge == SliceMergeIndex:
+		for index, item := range src.Content {
+			if index < len(dst.Content) {
+				dst.Content[index] = mergeLayer(dst.Content[index], item, path, source, sliceMerge, nil)
+				continue
+			}
+			dst.Content = append(dst.Content, item)
+		}
+		provenance.record(path, dst, source)
+		return dst
+	default:
+		provenance.record(path, src, source)
+		return src
+	}
+}
+
+// mappingIndex returns the index of a key within a mapping node's content, or -1.
+func mappingIndex(node *yaml.Node, key string) int {
+	for index := 0; index+1 < len(node.Content); index += 2 {
+		if node.Content[index].Value == key {
+			return index
+		}
+	}
+	return -1
+}
+
+// setPath sets a value at a path within a mapping node, creating intermediate mappings.
+func setPath(node *yaml.Node, path []string, value *yaml.Node) {
+	key := path[0]
+	index := mappingIndex(node, key)
+	if len(path) == 1 {
+		if index >= 0 {
+			node.Content[index+1] = value
+			return
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+		return
+	}
+	if index < 0 || node.Content[index+1].Kind != yaml.MappingNode {
+		child := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		if index >= 0 {
+			node.Content[index+1] = child
+		} else {
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, child)
+		}
+		setPath(child, path[1:], value)
+		return
+	}
+	setPath(node.Content[index+1], path[1:], value)
+}
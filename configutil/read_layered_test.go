@@ -0,0 +1,157 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package configutil
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/env"
+)
+
+type layeredConfig struct {
+	Name   string            `yaml:"name"`
+	Port   int               `yaml:"port" env:"PORT"`
+	Debug  bool              `yaml:"debug"`
+	DB     layeredDBConfig   `yaml:"db"`
+	Hosts  []string          `yaml:"hosts"`
+	Labels map[string]string `yaml:"labels"`
+}
+
+type layeredDBConfig struct {
+	Host     string `yaml:"host" env:"DB_HOST"`
+	Database string `yaml:"database"`
+	Password string `yaml:"password" env:"DB_PASSWORD"`
+	Key      string `yaml:"key" secret:"key"`
+}
+
+func TestReadLayered(t *testing.T) {
+	its := assert.New(t)
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.Bool("debug", false, "")
+	flags.String("db.database", "", "")
+	flags.String("name", "", "")
+	its.Nil(flags.Parse([]string{"-debug", "-db.database=flags"}))
+
+	cfg := layeredConfig{Name: "default-name", DB: layeredDBConfig{Key: "default-key"}}
+	var provenance Provenance
+	paths, err := ReadLayered(&cfg,
+		OptPaths("testdata/layered/config.yml"),
+		OptEnv(env.Vars{env.VarServiceEnv: "prod", "PORT": "9090", "DB_PASSWORD": "env-password"}),
+		OptFlags(flags),
+		OptProvenance(&provenance),
+	)
+	its.Nil(err)
+	its.Equal([]string{"testdata/layered/config.yml", "testdata/layered/config.prod.yml"}, paths)
+
+	its.Equal("default-name", cfg.Name)
+	its.Equal(9090, cfg.Port)
+	its.True(cfg.Debug)
+	its.Equal("db.prod.example.com", cfg.DB.Host)
+	its.Equal("flags", cfg.DB.Database)
+	its.Equal("env-password", cfg.DB.Password)
+	its.Equal("default-key", cfg.DB.Key)
+	its.Equal([]string{"c"}, cfg.Hosts)
+	its.Equal(map[string]string{"team": "platform", "tier": "api"}, cfg.Labels)
+
+	its.Equal(Source{Kind: SourceKindDefaults}, provenance["name"])
+	its.Equal(Source{Kind: SourceKindEnv, Name: "PORT"}, provenance["port"])
+	its.Equal(Source{Kind: SourceKindFlag, Name: "debug"}, provenance["debug"])
+	its.Equal(Source{Kind: SourceKindFile, Name: "testdata/layered/config.prod.yml"}, provenance["db.host"])
+	its.Equal(Source{Kind: SourceKindFlag, Name: "db.database"}, provenance["db.database"])
+	its.Equal(Source{Kind: SourceKindFile, Name: "testdata/layered/config.yml"}, provenance["labels.team"])
+	its.Equal(Source{Kind: SourceKindFile, Name: "testdata/layered/config.prod.yml"}, provenance["labels.tier"])
+}
+
+func TestReadLayeredSliceMerge(t *testing.T) {
+	its := assert.New(t)
+
+	var cfg layeredConfig
+	_, err := ReadLayered(&cfg,
+		OptPaths("testdata/layered/config.yml"),
+		OptEnv(env.Vars{env.VarServiceEnv: "prod"}),
+		OptSliceMerge(SliceMergeAppend),
+	)
+	its.Nil(err)
+	its.Equal([]string{"a", "b", "c"}, cfg.Hosts)
+
+	cfg = layeredConfig{}
+	_, err = ReadLayered(&cfg,
+		OptPaths("testdata/layered/config.yml"),
+		OptEnv(env.Vars{env.VarServiceEnv: "prod"}),
+		OptSliceMerge(SliceMergeIndex),
+	)
+	its.Nil(err)
+	its.Equal([]string{"c", "b"}, cfg.Hosts)
+}
+
+func TestReadLayeredContents(t *testing.T) {
+	its := assert.New(t)
+
+	var cfg layeredConfig
+	paths, err := ReadLayered(&cfg,
+		OptUnsetPaths(),
+		OptEnv(env.Vars{}),
+		OptAddContentString("json", `{"port":80,"db":{"host":"json-host"}}`),
+		OptAddContentString("yml", "db:\n  database: yml-database\n"),
+	)
+	its.Nil(err)
+	its.Empty(paths)
+	its.Equal(80, cfg.Port)
+	its.Equal("json-host", cfg.DB.Host)
+	its.Equal("yml-database", cfg.DB.Database)
+
+	_, err = ReadLayered(&cfg, OptUnsetPaths(), OptAddContentString("ini", "port = 80"))
+	its.True(IsInvalidConfigExtension(err))
+}
+
+func TestExplain(t *testing.T) {
+	its := assert.New(t)
+
+	cfg := layeredConfig{DB: layeredDBConfig{Key: "default-key"}}
+	var provenance Provenance
+	_, err := ReadLayered(&cfg,
+		OptPaths("testdata/layered/config.yml"),
+		OptEnv(env.Vars{env.VarServiceEnv: "prod", "PORT": "9090"}),
+		OptProvenance(&provenance),
+	)
+	its.Nil(err)
+
+	explained := Explain(&cfg, provenance)
+	its.Contains(explained, "port = 9090 from PORT env\n")
+	its.Contains(explained, "db.host = db.prod.example.com from testdata/layered/config.prod.yml\n")
+	its.Contains(explained, "db.password = [REDACTED] from testdata/layered/config.yml\n")
+	its.Contains(explained, "db.key = [REDACTED] from defaults\n")
+	its.Contains(explained, "hosts = [c] from testdata/layered/config.prod.yml\n")
+	its.Contains(explained, "name =  from defaults\n")
+	its.NotContains(explained, "base-password")
+	its.NotContains(explained, "default-key")
+
+	its.Contains(Explain(&layeredConfig{Port: 1}, nil), "port = 1 from unknown source\n")
+}
+
+func TestIsSecretKey(t *testing.T) {
+	its := assert.New(t)
+
+	for _, key := range []string{"password", "dbPassword", "apiToken", "API_TOKEN", "clientSecret", "apiKey", "api-key", "privateKey", "dbAPIToken"} {
+		its.True(isSecretKey(key), key)
+	}
+	for _, key := range []string{"maxTokens", "tokenURL", "secretName", "passwordFile", "keyspace", "host"} {
+		its.False(isSecretKey(key), key)
+	}
+}
+
+func TestEnvPath(t *testing.T) {
+	its := assert.New(t)
+
+	its.Equal("config.prod.yml", EnvPath("config.yml", "prod"))
+	its.Equal("/var/secrets/config.dev.json", EnvPath("/var/secrets/config.json", "dev"))
+	its.Equal("config.prod", EnvPath("config", "prod"))
+}
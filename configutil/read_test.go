@@ -216,3 +216,15 @@ child:
 	assert.Equal("child-field2-contents2", cfg.Child.Field2)
 	assert.Equal("child-field3-contents1", cfg.Child.Field3)
 }
+
+func TestTryReadTOML(t *testing.T) {
+	assert := assert.New(t)
+
+	var cfg config
+	paths, err := Read(&cfg, OptPaths("testdata/config.toml"))
+	assert.Nil(err)
+	assert.Len(paths, 1)
+	assert.Equal("testdata/config.toml", paths[0])
+	assert.Equal("test_toml", cfg.Environment)
+	assert.Equal("bar", cfg.Other)
+}
@@ -0,0 +1,441 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package configutil
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/blend/go-sdk/env"
+)
+
+// Schema constants.
+const (
+	// SchemaDraft is the json schema draft generated schemas conform to.
+	SchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+	SchemaTypeObject  = "object"
+	SchemaTypeArray   = "array"
+	SchemaTypeString  = "string"
+	SchemaTypeInteger = "integer"
+	SchemaTypeNumber  = "number"
+	SchemaTypeBoolean = "boolean"
+
+	SchemaFormatDuration = "duration"
+	SchemaFormatDateTime = "date-time"
+)
+
+var (
+	typeDuration        = reflect.TypeOf(time.Duration(0))
+	typeYAMLUnmarshaler = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+	typeJSONUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	typeTextUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Schema is a json schema for a config.
+//
+// Only the parts of json schema needed to describe configs are supported. Each value has
+// an `x-env` extension with the environment variable it can be set with, if any, and the
+// root schema has an `x-tag` extension with the struct tag keys are named by.
+type Schema struct {
+	Schema    string      `json:"$schema,omitempty"`
+	Title     string      `json:"title,omitempty"`
+	Tag       string      `json:"x-tag,omitempty"`
+	Type      string      `json:"type,omitempty"`
+	Format    string      `json:"format,omitempty"`
+	Default   interface{} `json:"default,omitempty"`
+	WriteOnly bool        `json:"writeOnly,omitempty"`
+	Env       string      `json:"x-env,omitempty"`
+
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+	// AdditionalProperties is the schema for the values of maps.
+	AdditionalProperties *Schema `json:"-"`
+	// Closed is if keys other than the properties are not allowed, i.e. `"additionalProperties": false`.
+	Closed bool `json:"-"`
+}
+
+type schemaAlias Schema
+
+// MarshalJSON implements json.Marshaler.
+func (s Schema) MarshalJSON() ([]byte, error) {
+	output := struct {
+		schemaAlias
+		AdditionalProperties interface{} `json:"additionalProperties,omitempty"`
+	}{schemaAlias: schemaAlias(s)}
+	if s.Closed {
+		output.AdditionalProperties = false
+	} else if s.AdditionalProperties != nil {
+		output.AdditionalProperties = s.AdditionalProperties
+	}
+	return json.Marshal(output)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *Schema) UnmarshalJSON(contents []byte) error {
+	input := struct {
+		*schemaAlias
+		AdditionalProperties json.RawMessage `json:"additionalProperties"`
+	}{schemaAlias: (*schemaAlias)(s)}
+	if err := json.Unmarshal(contents, &input); err != nil {
+		return err
+	}
+	switch additional := bytes.TrimSpace(input.AdditionalProperties); string(additional) {
+	case "", "null", "true":
+	case "false":
+		s.Closed = true
+	default:
+		s.AdditionalProperties = new(Schema)
+		return json.Unmarshal(additional, s.AdditionalProperties)
+	}
+	return nil
+}
+
+// SchemaOptions are options for generating schemas.
+type SchemaOptions struct {
+	// Title is the title of the schema.
+	Title string
+	// Tag is the struct tag keys are named by, i.e. `yaml` (the default) or `json`.
+	Tag string
+}
+
+// SchemaOption mutates schema options.
+type SchemaOption func(*SchemaOptions)
+
+// OptSchemaTitle sets the schema title.
+func OptSchemaTitle(title string) SchemaOption {
+	return func(so *SchemaOptions) { so.Title = title }
+}
+
+// OptSchemaTag sets the struct tag keys are named by, i.e. `yaml` (the default) or `json`.
+//
+// Yaml and toml configs are decoded by `yaml` tags, and json configs read with `Read` by `json` tags,
+// with durations as integer nanoseconds.
+func OptSchemaTag(tag string) SchemaOption {
+	return func(so *SchemaOptions) { so.Tag = tag }
+}
+
+// GenerateSchema generates a json schema for a config type from its `yaml` (or `json`) and `env` tags.
+/*
+Nested structs are described as nested objects that do not allow unknown keys. Durations are strings
+with a `duration` format, e.g. `5s` (or integer nanoseconds with the `json` tag), and fields with a `secret` tag, or with keys that match `SecretKeys`,
+are marked `writeOnly`.
+
+Defaults are the values set on the ref, and those set by the `Resolve` methods of the config and
+any nested configs (e.g. `db.Config`) when resolved with no environment variables; defaults of secrets
+are omitted. The ref itself is not modified.
+
+	schema := configutil.GenerateSchema(&Config{}, configutil.OptSchemaTitle("my-service"))
+	contents, err := json.MarshalIndent(schema, "", "  ")
+	...
+	fmt.Print(schema.Markdown())
+*/
+func GenerateSchema(ref Any, options ...SchemaOption) *Schema {
+	schemaOptions := SchemaOptions{Tag: "yaml"}
+	for _, option := range options {
+		option(&schemaOptions)
+	}
+	g := schemaGenerator{
+		tag:      schemaOptions.Tag,
+		visiting: make(map[reflect.Type]bool),
+	}
+	defaults := schemaDefaults(ref)
+	output := g.generate(defaults.Type(), defaults, "")
+	output.Schema = SchemaDraft
+	output.Title = schemaOptions.Title
+	output.Tag = schemaOptions.Tag
+	return output
+}
+
+// schemaDefaults returns a resolved copy of the ref.
+func schemaDefaults(ref Any) reflect.Value {
+	value := reflect.ValueOf(ref)
+	for value.Kind() == reflect.Ptr && !value.IsNil() && value.Elem().Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	var copied reflect.Value
+	switch {
+	case value.Kind() == reflect.Ptr && value.IsNil():
+		copied = reflect.New(value.Type().Elem())
+	case value.Kind() == reflect.Ptr:
+		copied = reflect.New(value.Type().Elem())
+		copied.Elem().Set(value.Elem())
+	default:
+		copied = reflect.New(value.Type())
+		copied.Elem().Set(value)
+	}
+	resolveDefaults(env.WithVars(context.Background(), env.Vars{}), copied)
+	return copied.Elem()
+}
+
+// resolveDefaults calls the `Resolve` methods of a value and the nested structs of the value, ignoring errors.
+func resolveDefaults(ctx context.Context, value reflect.Value) {
+	if typed, ok := value.Interface().(Resolver); ok {
+		_ = typed.Resolve(ctx)
+	}
+	elem := value.Elem()
+	if elem.Kind() != reflect.Struct || elem.Type() == typeTime {
+		return
+	}
+	for index := 0; index < elem.NumField(); index++ {
+		field := elem.Field(index)
+		if !field.CanSet() {
+			continue
+		}
+		switch {
+		case field.Kind() == reflect.Struct:
+			resolveDefaults(ctx, field.Addr())
+		case field.Kind() == reflect.Ptr && !field.IsNil() && field.Elem().Kind() == reflect.Struct:
+			resolveDefaults(ctx, field)
+		}
+	}
+}
+
+type schemaGenerator struct {
+	tag      string
+	visiting map[reflect.Type]bool
+}
+
+// generate returns the schema for a type, with defaults from a value of the type if it is valid.
+func (g schemaGenerator) generate(t reflect.Type, value reflect.Value, path string) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		if value.IsValid() && !value.IsNil() {
+			value = value.Elem()
+		} else {
+			value = reflect.Value{}
+		}
+	}
+
+	switch {
+	case t == typeDuration && g.tag == "json":
+		output := &Schema{Type: SchemaTypeInteger, Format: SchemaFormatDuration}
+		if value.IsValid() && !value.IsZero() {
+			output.Default = int64(value.Interface().(time.Duration))
+		}
+		return output
+	case t == typeDuration:
+		output := &Schema{Type: SchemaTypeString, Format: SchemaFormatDuration}
+		if value.IsValid() && !value.IsZero() {
+			output.Default = value.Interface().(time.Duration).String()
+		}
+		return output
+	case t == typeTime:
+		output := &Schema{Type: SchemaTypeString, Format: SchemaFormatDateTime}
+		if value.IsValid() && !value.IsZero() {
+			output.Default = value.Interface().(time.Time).Format(time.RFC3339Nano)
+		}
+		return output
+	case g.tag != "json" && reflect.PtrTo(t).Implements(typeYAMLUnmarshaler):
+		return new(Schema)
+	case g.tag == "json" && reflect.PtrTo(t).Implements(typeJSONUnmarshaler):
+		return new(Schema)
+	case reflect.PtrTo(t).Implements(typeTextUnmarshaler):
+		return &Schema{Type: SchemaTypeString}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return g.generateStruct(t, value, path)
+	case reflect.Map:
+		output := &Schema{Type: SchemaTypeObject, AdditionalProperties: g.generate(t.Elem(), reflect.Value{}, path)}
+		output.Default = schemaDefault(value)
+		return output
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: SchemaTypeString}
+		}
+		output := &Schema{Type: SchemaTypeArray, Items: g.generate(t.Elem(), reflect.Value{}, path)}
+		output.Default = schemaDefault(value)
+		return output
+	case reflect.String:
+		return &Schema{Type: SchemaTypeString, Default: schemaDefault(value)}
+	case reflect.Bool:
+		return &Schema{Type: SchemaTypeBoolean, Default: schemaDefault(value)}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: SchemaTypeInteger, Default: schemaDefault(value)}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: SchemaTypeNumber, Default: schemaDefault(value)}
+	default:
+		return new(Schema)
+	}
+}
+
+func (g schemaGenerator) generateStruct(t reflect.Type, value reflect.Value, path string) *Schema {
+	output := &Schema{Type: SchemaTypeObject, Properties: make(map[string]*Schema), Closed: true}
+	if g.visiting[t] {
+		return &Schema{Type: SchemaTypeObject}
+	}
+	g.visiting[t] = true
+	defer delete(g.visiting, t)
+
+	for index := 0; index < t.NumField(); index++ {
+		field := t.Field(index)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		name, inline, skip := g.fieldName(field)
+		if skip {
+			continue
+		}
+		var fieldValue reflect.Value
+		if value.IsValid() {
+			fieldValue = value.Field(index)
+		}
+		if inline {
+			for key, property := range g.generate(field.Type, fieldValue, path).Properties {
+				output.Properties[key] = property
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+
+		fieldPath := joinPath(path, name)
+		property := g.generate(field.Type, fieldValue, fieldPath)
+		property.Env = strings.Split(field.Tag.Get(env.ReflectTagName), ",")[0]
+		if tag, ok := field.Tag.Lookup(SecretTag); (ok && tag != "-") || isSecretPath(name, nil) {
+			property.WriteOnly = true
+			property.Default = nil
+		}
+		output.Properties[name] = property
+	}
+	return output
+}
+
+// fieldName returns the key of a field by the generator tag, following the rules of the yaml or json decoder.
+func (g schemaGenerator) fieldName(field reflect.StructField) (name string, inline, skip bool) {
+	if g.tag != "json" {
+		return yamlFieldName(field)
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	name = strings.Split(tag, ",")[0]
+	if name == "" && field.Anonymous {
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct {
+			return "", true, false
+		}
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, false, false
+}
+
+// schemaDefault returns a value as a default, or nil if it is unset or empty.
+func schemaDefault(value reflect.Value) interface{} {
+	if !value.IsValid() || value.IsZero() {
+		return nil
+	}
+	if (value.Kind() == reflect.Slice || value.Kind() == reflect.Map) && value.Len() == 0 {
+		return nil
+	}
+	return value.Interface()
+}
+
+// Markdown returns a reference table of the keys of a schema, with their types,
+// environment variables and defaults, e.g.
+//
+//	| Key | Type | Env | Default |
+//	| --- | --- | --- | --- |
+//	| `db.host` | string | `DB_HOST` | `localhost` |
+//	| `db.timeout` | duration | `DB_TIMEOUT` | `5s` |
+func (s *Schema) Markdown() string {
+	output := new(strings.Builder)
+	if s.Title != "" {
+		fmt.Fprintf(output, "# %s\n\n", s.Title)
+	}
+	fmt.Fprintln(output, "| Key | Type | Env | Default |")
+	fmt.Fprintln(output, "| --- | --- | --- | --- |")
+	s.walkKeys("", func(path string, schema *Schema) {
+		fmt.Fprintf(output, "| %s | %s | %s | %s |\n",
+			markdownCode(path),
+			schema.typeName(),
+			markdownCode(schema.Env),
+			markdownCode(schema.defaultString()),
+		)
+	})
+	return output.String()
+}
+
+// walkKeys calls a function for each key of a schema that is not an object with properties, in order.
+func (s *Schema) walkKeys(path string, fn func(string, *Schema)) {
+	if len(s.Properties) == 0 {
+		if path != "" {
+			fn(path, s)
+		}
+		return
+	}
+	keys := make([]string, 0, len(s.Properties))
+	for key := range s.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s.Properties[key].walkKeys(joinPath(path, key), fn)
+	}
+}
+
+// typeName returns a description of the type of a schema, e.g. `duration` or `array of string`.
+func (s *Schema) typeName() string {
+	switch {
+	case s.Format == SchemaFormatDuration && s.Type == SchemaTypeInteger:
+		return "duration (nanoseconds)"
+	case s.Format == SchemaFormatDuration:
+		return "duration"
+	case s.Format != "":
+		return s.Type + " (" + s.Format + ")"
+	case s.Type == SchemaTypeArray && s.Items != nil && s.Items.Type != "":
+		return "array of " + s.Items.typeName()
+	case s.Type == SchemaTypeObject && s.AdditionalProperties != nil && s.AdditionalProperties.Type != "":
+		return "map of " + s.AdditionalProperties.typeName()
+	case s.Type == "":
+		return "any"
+	default:
+		return s.Type
+	}
+}
+
+func (s *Schema) defaultString() string {
+	switch typed := s.Default.(type) {
+	case nil:
+		return ""
+	case string:
+		return typed
+	default:
+		contents, err := json.Marshal(typed)
+		if err != nil {
+			return fmt.Sprint(typed)
+		}
+		return string(contents)
+	}
+}
+
+func markdownCode(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "`" + strings.ReplaceAll(value, "|", `\|`) + "`"
+}
@@ -0,0 +1,239 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package configutil
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/ex"
+)
+
+type schemaDBConfig struct {
+	Host     string        `json:"host" yaml:"host" env:"DB_HOST"`
+	Port     int           `json:"port" yaml:"port" env:"DB_PORT"`
+	Password string        `json:"password" yaml:"password" env:"DB_PASSWORD"`
+	Timeout  time.Duration `json:"timeout" yaml:"timeout"`
+}
+
+func (c *schemaDBConfig) Resolve(ctx context.Context) error {
+	return Resolve(ctx,
+		SetString(&c.Host, Env("DB_HOST"), String(c.Host), String("localhost")),
+		SetInt(&c.Port, Env("DB_PORT"), Int(c.Port), Int(5432)),
+		SetString(&c.Password, Env("DB_PASSWORD"), String(c.Password), String("hunter2")),
+	)
+}
+
+type schemaConfig struct {
+	Name    string            `json:"serviceName" yaml:"name" env:"SERVICE_NAME"`
+	Debug   bool              `json:"debug" yaml:"debug"`
+	Ratio   float64           `json:"ratio" yaml:"ratio"`
+	Hosts   []string          `json:"hosts" yaml:"hosts"`
+	Labels  map[string]string `json:"labels" yaml:"labels"`
+	Token   string            `json:"-" yaml:"apiKey" secret:"true"`
+	DB      schemaDBConfig    `json:"db" yaml:"db"`
+	Ignored string            `json:"-" yaml:"-"`
+}
+
+func (c *schemaConfig) Resolve(ctx context.Context) error {
+	return Resolve(ctx,
+		(&c.DB).Resolve,
+		SetString(&c.Name, Env("SERVICE_NAME"), String(c.Name), String("example")),
+	)
+}
+
+func TestGenerateSchema(t *testing.T) {
+	its := assert.New(t)
+
+	ref := &schemaConfig{DB: schemaDBConfig{Timeout: 5 * time.Second}}
+	schema := GenerateSchema(ref, OptSchemaTitle("example"))
+	its.Equal(SchemaDraft, schema.Schema)
+	its.Equal("example", schema.Title)
+	its.Equal(SchemaTypeObject, schema.Type)
+	its.True(schema.Closed)
+	its.Len(schema.Properties, 7)
+	its.Empty(ref.Name, "the ref should not be modified")
+
+	its.Equal(SchemaTypeString, schema.Properties["name"].Type)
+	its.Equal("SERVICE_NAME", schema.Properties["name"].Env)
+	its.Equal("example", schema.Properties["name"].Default)
+	its.Equal(SchemaTypeBoolean, schema.Properties["debug"].Type)
+	its.Nil(schema.Properties["debug"].Default)
+	its.Equal(SchemaTypeNumber, schema.Properties["ratio"].Type)
+	its.Equal(SchemaTypeArray, schema.Properties["hosts"].Type)
+	its.Equal(SchemaTypeString, schema.Properties["hosts"].Items.Type)
+	its.Equal(SchemaTypeObject, schema.Properties["labels"].Type)
+	its.False(schema.Properties["labels"].Closed)
+	its.Equal(SchemaTypeString, schema.Properties["labels"].AdditionalProperties.Type)
+	its.True(schema.Properties["apiKey"].WriteOnly)
+
+	db := schema.Properties["db"]
+	its.Equal(SchemaTypeObject, db.Type)
+	its.True(db.Closed)
+	its.Equal("localhost", db.Properties["host"].Default)
+	its.Equal("DB_HOST", db.Properties["host"].Env)
+	its.Equal(SchemaTypeInteger, db.Properties["port"].Type)
+	its.Equal(5432, db.Properties["port"].Default)
+	its.True(db.Properties["password"].WriteOnly)
+	its.Nil(db.Properties["password"].Default)
+	its.Equal(SchemaFormatDuration, db.Properties["timeout"].Format)
+	its.Equal("5s", db.Properties["timeout"].Default)
+}
+
+func TestGenerateSchemaJSONTag(t *testing.T) {
+	its := assert.New(t)
+
+	schema := GenerateSchema(&schemaConfig{}, OptSchemaTag("json"))
+	its.NotNil(schema.Properties["serviceName"])
+	its.Nil(schema.Properties["name"])
+	its.Nil(schema.Properties["apiKey"])
+	its.Len(schema.Properties, 6)
+	its.Equal("json", schema.Tag)
+	timeout := schema.Properties["db"].Properties["timeout"]
+	its.Equal(SchemaTypeInteger, timeout.Type)
+	its.Equal(SchemaFormatDuration, timeout.Format)
+}
+
+func TestSchemaJSON(t *testing.T) {
+	its := assert.New(t)
+
+	schema := GenerateSchema(&schemaConfig{})
+	contents, err := json.Marshal(schema)
+	its.Nil(err)
+
+	var raw map[string]interface{}
+	its.Nil(json.Unmarshal(contents, &raw))
+	its.Equal(false, raw["additionalProperties"])
+	labels := raw["properties"].(map[string]interface{})["labels"].(map[string]interface{})
+	its.Equal(map[string]interface{}{"type": "string"}, labels["additionalProperties"])
+
+	var verify Schema
+	its.Nil(json.Unmarshal(contents, &verify))
+	its.True(verify.Closed)
+	its.True(verify.Properties["db"].Closed)
+	its.Equal(SchemaTypeString, verify.Properties["labels"].AdditionalProperties.Type)
+	its.Equal("DB_HOST", verify.Properties["db"].Properties["host"].Env)
+	its.Equal(schema.Validate(map[string]interface{}{"extra": true}), verify.Validate(map[string]interface{}{"extra": true}))
+}
+
+func TestSchemaMarkdown(t *testing.T) {
+	its := assert.New(t)
+
+	markdown := GenerateSchema(&schemaConfig{DB: schemaDBConfig{Timeout: time.Second}}, OptSchemaTitle("example")).Markdown()
+	lines := strings.Split(strings.TrimSpace(markdown), "\n")
+	its.Equal("# example", lines[0])
+	its.Equal("| Key | Type | Env | Default |", lines[2])
+	its.Len(lines, 4+10)
+	its.Contains(markdown, "| `db.host` | string | `DB_HOST` | `localhost` |")
+	its.Contains(markdown, "| `db.timeout` | duration |  | `1s` |")
+	its.Contains(markdown, "| `db.password` | string | `DB_PASSWORD` |  |")
+	its.Contains(markdown, "| `hosts` | array of string |  |  |")
+	its.Contains(markdown, "| `labels` | map of string |  |  |")
+}
+
+func TestSchemaValidate(t *testing.T) {
+	its := assert.New(t)
+
+	schema := GenerateSchema(&schemaConfig{})
+	its.Empty(schema.Validate(map[string]interface{}{
+		"name":   "example",
+		"debug":  true,
+		"ratio":  1,
+		"hosts":  []interface{}{"a", 1},
+		"labels": map[string]interface{}{"team": "platform"},
+		"db": map[string]interface{}{
+			"host":    nil,
+			"port":    5432,
+			"timeout": "5s",
+		},
+	}))
+
+	violations := schema.Validate(map[string]interface{}{
+		"debug":  "yes",
+		"hosts":  "a",
+		"labels": map[string]interface{}{"team": []interface{}{}},
+		"db": map[string]interface{}{
+			"port":    1.5,
+			"timeout": 5,
+			"user":    "postgres",
+		},
+		"unknown": true,
+	})
+	its.Len(violations, 7)
+	its.Equal("db.port: expected an integer; got a number", violations[0].String())
+	its.Equal("db.timeout", violations[1].Path)
+	its.Equal("db.user: unknown key", violations[2].String())
+	its.Equal("debug: expected a boolean; got a string", violations[3].String())
+	its.Equal("hosts: expected an array; got a string", violations[4].String())
+	its.Equal("labels.team: expected a string; got an array", violations[5].String())
+	its.Equal("unknown: unknown key", violations[6].String())
+
+	violations = schema.Validate("name")
+	its.Len(violations, 1)
+	its.Equal("expected an object; got a string", violations[0].String())
+}
+
+func TestSchemaValidateFile(t *testing.T) {
+	its := assert.New(t)
+
+	schema := GenerateSchema(&schemaConfig{})
+	path := filepath.Join(t.TempDir(), "config.yml")
+	its.Nil(os.WriteFile(path, []byte("name: example\ndb:\n  host: db.local\n  timeout: soon\n  extra: 1\n"), 0644))
+
+	violations, err := schema.ValidateFile(path)
+	its.Nil(err)
+	its.Len(violations, 2)
+	its.Equal("db.extra", violations[0].Path)
+	its.Equal("db.timeout", violations[1].Path)
+
+	empty := filepath.Join(t.TempDir(), "empty.yml")
+	its.Nil(os.WriteFile(empty, nil, 0644))
+	violations, err = schema.ValidateFile(empty)
+	its.Nil(err)
+	its.Empty(violations)
+
+	_, err = schema.ValidateFile(filepath.Join(t.TempDir(), "config.ini"))
+	its.True(IsInvalidConfigExtension(err))
+}
+
+func TestSchemaValidateFileJSON(t *testing.T) {
+	its := assert.New(t)
+
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.json")
+	its.Nil(os.WriteFile(valid, []byte(`{"serviceName":"example","db":{"timeout":5000000000}}`), 0644))
+	invalid := filepath.Join(dir, "invalid.json")
+	its.Nil(os.WriteFile(invalid, []byte(`{"name":"example","debug":true,"db":{"timeout":"5s","host":5}}`), 0644))
+
+	var cfg schemaConfig
+	_, err := Read(&cfg, OptUnsetPaths(), OptPaths(valid))
+	its.Nil(err)
+	_, err = Read(&cfg, OptUnsetPaths(), OptPaths(invalid))
+	its.NotNil(err)
+
+	_, err = GenerateSchema(&schemaConfig{}).ValidateFile(valid)
+	its.True(ex.Is(err, ErrSchemaTagMismatch))
+
+	schema := GenerateSchema(&schemaConfig{}, OptSchemaTag("json"))
+	violations, err := schema.ValidateFile(valid)
+	its.Nil(err)
+	its.Empty(violations)
+
+	violations, err = schema.ValidateFile(invalid)
+	its.Nil(err)
+	its.Len(violations, 3)
+	its.Equal("db.host: expected a string; got a number", violations[0].String())
+	its.Equal("db.timeout: expected an integer; got a string", violations[1].String())
+	its.Equal("name: unknown key", violations[2].String())
+}
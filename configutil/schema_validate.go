@@ -0,0 +1,221 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package configutil
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+// ErrSchemaTagMismatch is returned when a config file is validated against a schema
+// generated with a different struct tag than the file is read by.
+const ErrSchemaTagMismatch ex.Class = "configutil; schema tag does not match the config file"
+
+// SchemaTagForPath returns the struct tag a config file is read by, i.e. `json` for json files
+// (as read with `Read`), and `yaml` otherwise.
+func SchemaTagForPath(path string) string {
+	if normalizeExtension(filepath.Ext(path)) == ExtensionJSON {
+		return "json"
+	}
+	return "yaml"
+}
+
+// SchemaViolation is a config value that does not match a schema.
+type SchemaViolation struct {
+	// Path is the dot separated path of the value, e.g. `db.host` or `hosts[0]`.
+	Path string
+	// Message describes the violation.
+	Message string
+}
+
+// String returns the violation as a string.
+func (sv SchemaViolation) String() string {
+	if sv.Path == "" {
+		return sv.Message
+	}
+	return sv.Path + ": " + sv.Message
+}
+
+// ValidateFile reads a config file with the deserializer registered for its extension
+// and validates its contents against the schema.
+//
+// Json files are read by `json` tags, so they must be validated against a schema generated
+// with `OptSchemaTag("json")`, and other files against a schema generated with the `yaml` tag;
+// see `SchemaTagForPath`.
+func (s *Schema) ValidateFile(path string) ([]SchemaViolation, error) {
+	deserializer, ok := GetDeserializer(filepath.Ext(path))
+	if !ok {
+		return nil, ex.New(ErrInvalidConfigExtension, ex.OptMessagef("extension: %s", filepath.Ext(path)))
+	}
+	if tag := SchemaTagForPath(path); tag != s.tagOrDefault() {
+		return nil, ex.New(ErrSchemaTagMismatch, ex.OptMessagef("%s is read by `%s` tags; the schema is generated with `%s` tags", path, tag, s.tagOrDefault()))
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, ex.New(err)
+	}
+	defer f.Close()
+
+	contents := make(map[string]interface{})
+	if err = deserializer(f, &contents); err != nil && !ex.Is(err, io.EOF) {
+		return nil, err
+	}
+	return s.Validate(contents), nil
+}
+
+// Validate validates config contents, as decoded into a `map[string]interface{}`, against the schema.
+//
+// Keys that are not in the schema are reported as unknown, as are values of the wrong type.
+// Null values are always valid, as they leave a config value unset.
+//
+// If the schema is generated with the `json` tag, the contents are validated as the json decoder
+// reads them, i.e. strings must be strings; otherwise other scalars are valid strings, as with yaml.
+func (s *Schema) Validate(contents interface{}) (violations []SchemaViolation) {
+	strict := s.tagOrDefault() == "json"
+	s.validate("", contents, strict, func(path, format string, args ...interface{}) {
+		violations = append(violations, SchemaViolation{Path: path, Message: fmt.Sprintf(format, args...)})
+	})
+	return
+}
+
+func (s *Schema) tagOrDefault() string {
+	if s.Tag != "" {
+		return s.Tag
+	}
+	return "yaml"
+}
+
+func (s *Schema) validate(path string, value interface{}, strict bool, report func(string, string, ...interface{})) {
+	if value == nil || s.Type == "" {
+		return
+	}
+	switch s.Type {
+	case SchemaTypeObject:
+		typed, ok := value.(map[string]interface{})
+		if !ok {
+			report(path, "expected an object; got %s", schemaValueKind(value))
+			return
+		}
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			childPath := joinPath(path, key)
+			if property, ok := s.Properties[key]; ok {
+				property.validate(childPath, typed[key], strict, report)
+				continue
+			}
+			if s.AdditionalProperties != nil {
+				s.AdditionalProperties.validate(childPath, typed[key], strict, report)
+				continue
+			}
+			if s.Closed {
+				report(childPath, "unknown key")
+			}
+		}
+	case SchemaTypeArray:
+		typed, ok := value.([]interface{})
+		if !ok {
+			report(path, "expected an array; got %s", schemaValueKind(value))
+			return
+		}
+		if s.Items != nil {
+			for index, item := range typed {
+				s.Items.validate(path+"["+strconv.Itoa(index)+"]", item, strict, report)
+			}
+		}
+	case SchemaTypeString:
+		s.validateString(path, value, strict, report)
+	case SchemaTypeInteger:
+		if number, ok := schemaNumber(value); !ok || number != math.Trunc(number) {
+			report(path, "expected an integer; got %s", schemaValueKind(value))
+		}
+	case SchemaTypeNumber:
+		if _, ok := schemaNumber(value); !ok {
+			report(path, "expected a number; got %s", schemaValueKind(value))
+		}
+	case SchemaTypeBoolean:
+		if _, ok := value.(bool); !ok {
+			report(path, "expected a boolean; got %s", schemaValueKind(value))
+		}
+	}
+}
+
+// validateString validates a string value; unless strict, other scalars are valid as yaml decodes them into strings.
+func (s *Schema) validateString(path string, value interface{}, strict bool, report func(string, string, ...interface{})) {
+	switch typed := value.(type) {
+	case map[string]interface{}, []interface{}:
+		report(path, "expected a string; got %s", schemaValueKind(value))
+	case string:
+		switch s.Format {
+		case SchemaFormatDuration:
+			if _, err := time.ParseDuration(typed); err != nil {
+				report(path, "invalid duration %q; expected a duration like `5s`", typed)
+			}
+		case SchemaFormatDateTime:
+			if _, err := time.Parse(time.RFC3339Nano, typed); err != nil {
+				report(path, "invalid date-time %q; expected an RFC3339 date-time", typed)
+			}
+		}
+	case time.Time:
+		if s.Format == SchemaFormatDuration {
+			report(path, "expected a duration; got a date-time")
+		}
+	default:
+		if strict {
+			report(path, "expected a string; got %s", schemaValueKind(value))
+		} else if s.Format == SchemaFormatDuration {
+			report(path, "expected a duration like `5s`; got %s", schemaValueKind(value))
+		}
+	}
+}
+
+// schemaNumber returns a decoded number as a float64.
+func schemaNumber(value interface{}) (float64, bool) {
+	switch typed := value.(type) {
+	case int:
+		return float64(typed), true
+	case int64:
+		return float64(typed), true
+	case uint64:
+		return float64(typed), true
+	case float64:
+		return typed, true
+	default:
+		return 0, false
+	}
+}
+
+func schemaValueKind(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case int, int64, uint64, float64:
+		return "a number"
+	case time.Time:
+		return "a date-time"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
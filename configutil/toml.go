@@ -0,0 +1,85 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package configutil
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+// ErrInvalidTOML is returned when toml contents cannot be parsed.
+const ErrInvalidTOML ex.Class = "configutil; invalid toml"
+
+// DeserializeTOML decodes toml contents into a reference.
+//
+// The contents are parsed into a map and then decoded as yaml, so fields are matched by their `yaml` tags,
+// as with `ReadLayered`. Offset date-times, local date-times and local dates are decoded as `time.Time`
+// (local values in UTC), and local times as strings, e.g. `07:32:00`.
+func DeserializeTOML(r io.Reader, ref Any) error {
+	contents, err := io.ReadAll(r)
+	if err != nil {
+		return ex.New(err)
+	}
+	parsed, err := ParseTOML(string(contents))
+	if err != nil {
+		return err
+	}
+	var node yaml.Node
+	if err = node.Encode(parsed); err != nil {
+		return ex.New(err)
+	}
+	return ex.New(node.Decode(ref))
+}
+
+// ParseTOML parses a toml v1.0 document into a map.
+//
+// Tables are returned as `map[string]interface{}`, arrays as `[]interface{}`, integers as `int64`,
+// floats as `float64`, offset date-times, local date-times and local dates as `time.Time`
+// (local values in UTC), and local times as strings.
+func ParseTOML(contents string) (map[string]interface{}, error) {
+	parsed := make(map[string]interface{})
+	if err := toml.Unmarshal([]byte(contents), &parsed); err != nil {
+		var decodeErr *toml.DecodeError
+		if errors.As(err, &decodeErr) {
+			line, _ := decodeErr.Position()
+			return nil, ex.New(ErrInvalidTOML, ex.OptMessagef("line %d: %s", line, decodeErr.Error()), ex.OptInner(err))
+		}
+		return nil, ex.New(ErrInvalidTOML, ex.OptInner(err))
+	}
+	return tomlNormalize(parsed).(map[string]interface{}), nil
+}
+
+// tomlNormalize converts local date-times and local dates into times in UTC, and local times into strings.
+func tomlNormalize(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, child := range typed {
+			typed[key] = tomlNormalize(child)
+		}
+		return typed
+	case []interface{}:
+		for index, child := range typed {
+			typed[index] = tomlNormalize(child)
+		}
+		return typed
+	case toml.LocalDateTime:
+		return typed.AsTime(time.UTC)
+	case toml.LocalDate:
+		return typed.AsTime(time.UTC)
+	case toml.LocalTime:
+		return typed.String()
+	default:
+		return value
+	}
+}
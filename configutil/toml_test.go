@@ -0,0 +1,253 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package configutil
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/ex"
+)
+
+const tomlDocument = `# This is a TOML document
+title = "TOML \"Example\"" # trailing comment
+literal = 'C:\Users\nodejs'
+unicode = "caf\u00E9"
+multiline = """
+Roses are red
+Violets are \
+    blue"""
+multiline_literal = '''
+The first newline is
+trimmed in raw strings.'''
+quotes = """Here are two quotation marks: "". Simple enough."""
+"quoted key" = 1
+site."google.com" = true
+
+[owner]
+name = "Tom Preston-Werner"
+dob = 1979-05-27T07:32:00-08:00
+local = 1979-05-27 07:32:00
+date = 1979-05-27
+time = 07:32:00
+
+[database]
+enabled = true
+ports = [ 8000, 8001, 8002 ]
+data = [ ["delta", "phi"], [3.14] ]
+temp_targets = { cpu = 79.5, case = 72.0 }
+mixed = [
+  1, # comments are allowed in arrays
+  "two",
+]
+
+[numbers]
+int = +99
+negative = -17
+underscores = 1_000_000
+hex = 0xDEAD_BEEF
+oct = 0o755
+bin = 0b1101
+float = 6.626e-34
+fraction = -0.01
+infinity = -inf
+not = nan
+
+[servers.alpha]
+ip = "10.0.0.1"
+
+[servers.beta]
+ip = "10.0.0.2"
+tags.role = "backend"
+
+[[products]]
+name = "Hammer"
+sku = 738594937
+
+[[products]]
+
+[[products]]
+name = "Nail"
+color = "gray"
+
+[[fruits]]
+name = "apple"
+
+[fruits.physical]
+color = "red"
+
+[[fruits.varieties]]
+name = "red delicious"
+
+[[fruits]]
+name = "banana"
+
+[fruits.physical]
+color = "yellow"
+`
+
+func TestParseTOML(t *testing.T) {
+	its := assert.New(t)
+
+	parsed, err := ParseTOML(tomlDocument)
+	its.Nil(err)
+
+	its.Equal(`TOML "Example"`, parsed["title"])
+	its.Equal(`C:\Users\nodejs`, parsed["literal"])
+	its.Equal("café", parsed["unicode"])
+	its.Equal("Roses are red\nViolets are blue", parsed["multiline"])
+	its.Equal("The first newline is\ntrimmed in raw strings.", parsed["multiline_literal"])
+	its.Equal(`Here are two quotation marks: "". Simple enough.`, parsed["quotes"])
+	its.Equal(int64(1), parsed["quoted key"])
+	its.Equal(map[string]interface{}{"google.com": true}, parsed["site"])
+
+	owner := parsed["owner"].(map[string]interface{})
+	its.Equal("Tom Preston-Werner", owner["name"])
+	its.True(time.Date(1979, 5, 27, 15, 32, 0, 0, time.UTC).Equal(owner["dob"].(time.Time)))
+	its.Equal(time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC), owner["local"])
+	its.Equal(time.Date(1979, 5, 27, 0, 0, 0, 0, time.UTC), owner["date"])
+	its.Equal("07:32:00", owner["time"])
+
+	database := parsed["database"].(map[string]interface{})
+	its.Equal(true, database["enabled"])
+	its.Equal([]interface{}{int64(8000), int64(8001), int64(8002)}, database["ports"])
+	its.Equal([]interface{}{[]interface{}{"delta", "phi"}, []interface{}{3.14}}, database["data"])
+	its.Equal(map[string]interface{}{"cpu": 79.5, "case": 72.0}, database["temp_targets"])
+	its.Equal([]interface{}{int64(1), "two"}, database["mixed"])
+
+	numbers := parsed["numbers"].(map[string]interface{})
+	its.Equal(int64(99), numbers["int"])
+	its.Equal(int64(-17), numbers["negative"])
+	its.Equal(int64(1000000), numbers["underscores"])
+	its.Equal(int64(0xDEADBEEF), numbers["hex"])
+	its.Equal(int64(0755), numbers["oct"])
+	its.Equal(int64(13), numbers["bin"])
+	its.Equal(6.626e-34, numbers["float"])
+	its.Equal(-0.01, numbers["fraction"])
+	its.Equal(math.Inf(-1), numbers["infinity"])
+	its.True(math.IsNaN(numbers["not"].(float64)))
+
+	servers := parsed["servers"].(map[string]interface{})
+	its.Equal("10.0.0.1", servers["alpha"].(map[string]interface{})["ip"])
+	its.Equal(map[string]interface{}{"role": "backend"}, servers["beta"].(map[string]interface{})["tags"])
+
+	products := parsed["products"].([]interface{})
+	its.Len(products, 3)
+	its.Equal(map[string]interface{}{"name": "Hammer", "sku": int64(738594937)}, products[0])
+	its.Empty(products[1])
+	its.Equal("Nail", products[2].(map[string]interface{})["name"])
+
+	fruits := parsed["fruits"].([]interface{})
+	its.Len(fruits, 2)
+	apple := fruits[0].(map[string]interface{})
+	its.Equal("red", apple["physical"].(map[string]interface{})["color"])
+	its.Equal([]interface{}{map[string]interface{}{"name": "red delicious"}}, apple["varieties"])
+	its.Equal("yellow", fruits[1].(map[string]interface{})["physical"].(map[string]interface{})["color"])
+}
+
+func TestParseTOMLEmpty(t *testing.T) {
+	its := assert.New(t)
+
+	parsed, err := ParseTOML("\n# nothing here\r\n")
+	its.Nil(err)
+	its.Empty(parsed)
+}
+
+func TestParseTOMLInvalid(t *testing.T) {
+	its := assert.New(t)
+
+	invalid := map[string]string{
+		"duplicate key":          "a = 1\na = 2",
+		"duplicate table":        "[a]\n[a]",
+		"missing value":          "a =",
+		"missing equals":         "a 1",
+		"unterminated string":    `a = "foo`,
+		"newline in string":      "a = \"foo\nbar\"",
+		"invalid escape":         `a = "\q"`,
+		"leading zero":           "a = 01",
+		"bad underscore":         "a = 1__0",
+		"trailing dot":           "a = 1.",
+		"two values":             "a = 1 2",
+		"extend value":           "a = 1\na.b = 2",
+		"extend inline table":    "a = {b = 1}\n[a.c]",
+		"extend inline dotted":   "a = {b = 1}\na.c = 2",
+		"dotted key then table":  "a.b = 1\n[a]\nc = 2",
+		"redefine implicit":      "[a.b]\n[a]\nb.c = 1",
+		"array of tables clash":  "a = [1]\n[[a]]",
+		"unterminated array":     "a = [1, 2",
+		"unterminated multiline": `a = """foo`,
+		"invalid date":           "a = 1979-13-45",
+	}
+	for name, contents := range invalid {
+		_, err := ParseTOML(contents)
+		its.True(ex.Is(err, ErrInvalidTOML), name)
+	}
+
+	_, err := ParseTOML("a = 1\n\nb = [1,\n2,\n}")
+	its.NotNil(err)
+	its.True(strings.Contains(ex.ErrMessage(err), "line 5"), ex.ErrMessage(err))
+}
+
+func FuzzParseTOML(f *testing.F) {
+	f.Add(tomlDocument)
+	f.Add("a.b = 1\n[a]\nc = 2")
+	f.Add("a = {b = 1}\n[a.c]")
+	f.Fuzz(func(t *testing.T, contents string) {
+		parsed, err := ParseTOML(contents)
+		if err != nil {
+			if !ex.Is(err, ErrInvalidTOML) {
+				t.Fatalf("unexpected error class: %v", err)
+			}
+			return
+		}
+		var cfg map[string]interface{}
+		_ = deserialize(ExtensionTOML, strings.NewReader(contents), &cfg)
+		if parsed == nil {
+			t.Fatal("expected a map for a valid document")
+		}
+	})
+}
+
+func TestDeserializeTOML(t *testing.T) {
+	its := assert.New(t)
+
+	type tomlConfig struct {
+		Name    string            `yaml:"name"`
+		Port    int               `yaml:"port"`
+		Timeout time.Duration     `yaml:"timeout"`
+		Created time.Time         `yaml:"created"`
+		Hosts   []string          `yaml:"hosts"`
+		Labels  map[string]string `yaml:"labels"`
+		DB      struct {
+			Host string `yaml:"host"`
+		} `yaml:"db"`
+	}
+
+	var cfg tomlConfig
+	its.Nil(deserialize(ExtensionTOML, strings.NewReader(`
+name = "service"
+port = 8080
+timeout = "5s"
+created = 2022-01-02T03:04:05Z
+hosts = ["a", "b"]
+labels = { team = "platform" }
+
+[db]
+host = "localhost"
+`), &cfg))
+	its.Equal("service", cfg.Name)
+	its.Equal(8080, cfg.Port)
+	its.Equal(5*time.Second, cfg.Timeout)
+	its.Equal(time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC), cfg.Created)
+	its.Equal([]string{"a", "b"}, cfg.Hosts)
+	its.Equal(map[string]string{"team": "platform"}, cfg.Labels)
+	its.Equal("localhost", cfg.DB.Host)
+}
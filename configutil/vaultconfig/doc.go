@@ -0,0 +1,31 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+/*
+Package vaultconfig contains configutil value sources that read secrets from vault.
+
+The sources can be used with any of the `configutil.Set___` helpers in a config's `Resolve` method:
+
+	func (c *Config) Resolve(ctx context.Context) error {
+		return configutil.Resolve(ctx,
+			configutil.SetString(&c.DB.Password, configutil.Env("DB_PASSWORD"), vaultconfig.Vault(client, "secret/db", "password")),
+			configutil.SetDuration(&c.DB.Timeout, vaultconfig.Vault(nil, "secret/db", "timeout"), configutil.Duration(c.DB.Timeout)),
+			configutil.SetString(&c.Credentials, vaultconfig.VaultJSON("secret/credentials")),
+		)
+	}
+
+Sources without a client use the client on the context, which can be set when reading the config with `OptClient`:
+
+	paths, err := configutil.Read(&cfg, vaultconfig.OptClient(client))
+
+The vault client detects whether each secret's mount is a KV1 or KV2 engine. Each secret is fetched at most once
+per `configutil.Read`, no matter how many of its fields are used. Reads are logged to the configutil logger at the
+debug level by path and field only; secret values are never logged.
+
+The sources are in their own package because the `vault` package itself depends on `configutil`.
+*/
+package vaultconfig // import "github.com/blend/go-sdk/configutil/vaultconfig"
@@ -0,0 +1,29 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package vaultconfig
+
+import (
+	"context"
+
+	"github.com/blend/go-sdk/configutil"
+	"github.com/blend/go-sdk/vault"
+)
+
+// OptClient sets the vault client used by sources without a client when reading a config.
+//
+// It should be given after any `configutil.OptContext` option, which it adds the client to.
+func OptClient(client vault.Client) configutil.Option {
+	return func(co *configutil.ConfigOptions) error {
+		ctx := co.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		co.Context = vault.WithClient(ctx, client)
+		return nil
+	}
+}
@@ -0,0 +1,146 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package vaultconfig
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/blend/go-sdk/configutil"
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/vault"
+)
+
+// ErrClientUnset is returned when a source has no client and there is no client on the context.
+const ErrClientUnset ex.Class = "vaultconfig; vault client unset"
+
+var (
+	_ configutil.StringSource   = (*Source)(nil)
+	_ configutil.BoolSource     = (*Source)(nil)
+	_ configutil.IntSource      = (*Source)(nil)
+	_ configutil.Float64Source  = (*Source)(nil)
+	_ configutil.DurationSource = (*Source)(nil)
+)
+
+// Vault returns a source for a field of the secret at a path.
+//
+// If the client is nil, the client on the context is used (see `OptClient`).
+// Fields that are not strings are returned as json, e.g. `5432` or `true`.
+func Vault(client vault.Client, path, field string) Source {
+	return Source{
+		Client: client,
+		Path:   path,
+		Field:  field,
+	}
+}
+
+// VaultJSON returns a source for the secret at a path as a json object,
+// using the client on the context (see `OptClient`).
+func VaultJSON(path string) Source {
+	return Source{
+		Path: path,
+	}
+}
+
+// Source is a configutil value source for a vault secret.
+//
+// Missing secrets and fields are treated as unset, so other sources are tried.
+type Source struct {
+	// Client is the vault client; if unset the client on the context is used.
+	Client vault.Client
+	// Path is the path of the secret.
+	Path string
+	// Field is the field of the secret; if unset the whole secret is returned as json.
+	Field string
+}
+
+// String returns the field, or the whole secret as json if the field is unset.
+func (s Source) String(ctx context.Context) (*string, error) {
+	log := configutil.GetLogger(ctx)
+	data, err := s.secret(ctx, log)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, nil
+	}
+
+	var value interface{} = data
+	if s.Field != "" {
+		var ok bool
+		if value, ok = data[s.Field]; !ok || value == nil {
+			configutil.MaybeDebugf(log, "vault secret field not found: %s field: %s", s.Path, s.Field)
+			return nil, nil
+		}
+		configutil.MaybeDebugf(log, "using vault secret field: %s field: %s", s.Path, s.Field)
+	} else {
+		configutil.MaybeDebugf(log, "using vault secret as json: %s", s.Path)
+	}
+	if typed, ok := value.(string); ok {
+		return &typed, nil
+	}
+	contents, err := json.Marshal(value)
+	if err != nil {
+		return nil, ex.New(err, ex.OptMessagef("path: %s field: %s", s.Path, s.Field))
+	}
+	output := string(contents)
+	return &output, nil
+}
+
+// Bool returns the value parsed as a bool.
+func (s Source) Bool(ctx context.Context) (*bool, error) {
+	return configutil.Parse(s).Bool(ctx)
+}
+
+// Int returns the value parsed as an int.
+func (s Source) Int(ctx context.Context) (*int, error) {
+	return configutil.Parse(s).Int(ctx)
+}
+
+// Float64 returns the value parsed as a float64.
+func (s Source) Float64(ctx context.Context) (*float64, error) {
+	return configutil.Parse(s).Float64(ctx)
+}
+
+// Duration returns the value parsed as a duration, e.g. `5s`.
+func (s Source) Duration(ctx context.Context) (*time.Duration, error) {
+	return configutil.Parse(s).Duration(ctx)
+}
+
+// secret returns the secret data, from the read cache if it has already been fetched.
+func (s Source) secret(ctx context.Context, log configutil.Logger) (vault.Values, error) {
+	client := s.Client
+	if client == nil {
+		client = vault.GetClient(ctx)
+	}
+	if client == nil {
+		return nil, ex.New(ErrClientUnset, ex.OptMessagef("path: %s", s.Path))
+	}
+
+	value, cached, err := configutil.GetReadCache(ctx).Get("vault;"+s.Path, func() (interface{}, error) {
+		configutil.MaybeDebugf(log, "reading vault secret: %s", s.Path)
+		data, err := client.Get(ctx, s.Path)
+		if ex.Is(err, vault.ErrNotFound) {
+			return vault.Values(nil), nil
+		}
+		return data, err
+	})
+	if err != nil {
+		configutil.MaybeErrorf(log, "reading vault secret: %s; %v", s.Path, ex.ErrClass(err))
+		return nil, err
+	}
+	if cached {
+		configutil.MaybeDebugf(log, "using cached vault secret: %s", s.Path)
+	}
+	data, _ := value.(vault.Values)
+	if data == nil {
+		configutil.MaybeDebugf(log, "vault secret not found: %s", s.Path)
+	}
+	return data, nil
+}
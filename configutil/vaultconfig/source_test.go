@@ -0,0 +1,152 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package vaultconfig
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/configutil"
+	"github.com/blend/go-sdk/env"
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/vault"
+)
+
+type mockClient struct {
+	vault.Client
+	Secrets map[string]vault.Values
+	Gets    map[string]int
+}
+
+func (mc *mockClient) Get(_ context.Context, path string, _ ...vault.CallOption) (vault.Values, error) {
+	if mc.Gets == nil {
+		mc.Gets = make(map[string]int)
+	}
+	mc.Gets[path]++
+	if path == "secret/error" {
+		return nil, ex.New(vault.ErrUnauthorized)
+	}
+	data, ok := mc.Secrets[path]
+	if !ok {
+		return nil, ex.New(vault.ErrNotFound)
+	}
+	return data, nil
+}
+
+type capturedLog struct {
+	strings.Builder
+}
+
+func (cl *capturedLog) Infof(format string, args ...interface{}) {
+	fmt.Fprintf(cl, format+"\n", args...)
+}
+func (cl *capturedLog) Debugf(format string, args ...interface{}) {
+	fmt.Fprintf(cl, format+"\n", args...)
+}
+func (cl *capturedLog) Warningf(format string, args ...interface{}) {
+	fmt.Fprintf(cl, format+"\n", args...)
+}
+func (cl *capturedLog) Errorf(format string, args ...interface{}) {
+	fmt.Fprintf(cl, format+"\n", args...)
+}
+
+type vaultedConfig struct {
+	Host        string        `yaml:"host"`
+	Password    string        `yaml:"password"`
+	Port        int           `yaml:"port"`
+	Timeout     time.Duration `yaml:"timeout"`
+	Debug       bool          `yaml:"debug"`
+	Missing     string        `yaml:"missing"`
+	Credentials string        `yaml:"credentials"`
+
+	client vault.Client
+}
+
+func (vc *vaultedConfig) Resolve(ctx context.Context) error {
+	return configutil.Resolve(ctx,
+		configutil.SetString(&vc.Host, Vault(vc.client, "secret/db", "host"), configutil.String(vc.Host)),
+		configutil.SetString(&vc.Password, Vault(nil, "secret/db", "password")),
+		configutil.SetInt(&vc.Port, Vault(nil, "secret/db", "port")),
+		configutil.SetDuration(&vc.Timeout, Vault(nil, "secret/db", "timeout")),
+		configutil.SetBool(&vc.Debug, Vault(nil, "secret/db", "debug")),
+		configutil.SetString(&vc.Missing, Vault(nil, "secret/missing", "value"), Vault(nil, "secret/db", "missing"), configutil.String(vc.Missing)),
+		configutil.SetString(&vc.Credentials, VaultJSON("secret/credentials")),
+	)
+}
+
+func TestSourceRead(t *testing.T) {
+	its := assert.New(t)
+
+	client := &mockClient{
+		Secrets: map[string]vault.Values{
+			"secret/db": {
+				"host":     "db.example.com",
+				"password": "hunter2",
+				"port":     float64(5432),
+				"timeout":  "5s",
+				"debug":    true,
+			},
+			"secret/credentials": {
+				"key": "sekrit",
+			},
+		},
+	}
+	log := new(capturedLog)
+
+	cfg := vaultedConfig{Missing: "default", client: client}
+	_, err := configutil.Read(&cfg,
+		configutil.OptEnv(env.Vars{}),
+		configutil.OptUnsetPaths(),
+		configutil.OptLog(log),
+		OptClient(client),
+	)
+	its.Nil(err)
+	its.Equal("db.example.com", cfg.Host)
+	its.Equal("hunter2", cfg.Password)
+	its.Equal(5432, cfg.Port)
+	its.Equal(5*time.Second, cfg.Timeout)
+	its.True(cfg.Debug)
+	its.Equal("default", cfg.Missing)
+	its.Equal(`{"key":"sekrit"}`, cfg.Credentials)
+
+	// each secret is fetched once per read
+	its.Equal(1, client.Gets["secret/db"])
+	its.Equal(1, client.Gets["secret/missing"])
+	its.Equal(1, client.Gets["secret/credentials"])
+
+	// values are never logged
+	its.Contains(log.String(), "reading vault secret: secret/db")
+	its.Contains(log.String(), "using vault secret field: secret/db field: password")
+	its.NotContains(log.String(), "hunter2")
+	its.NotContains(log.String(), "sekrit")
+	its.NotContains(log.String(), "db.example.com")
+
+	// a new read fetches the secrets again
+	_, err = configutil.Read(&cfg, configutil.OptEnv(env.Vars{}), configutil.OptUnsetPaths(), OptClient(client))
+	its.Nil(err)
+	its.Equal(2, client.Gets["secret/db"])
+}
+
+func TestSourceErrors(t *testing.T) {
+	its := assert.New(t)
+
+	_, err := Vault(nil, "secret/db", "password").String(context.Background())
+	its.True(ex.Is(err, ErrClientUnset))
+
+	client := new(mockClient)
+	_, err = Vault(client, "secret/error", "password").String(context.Background())
+	its.True(ex.Is(err, vault.ErrUnauthorized))
+
+	value, err := Vault(client, "secret/missing", "password").String(context.Background())
+	its.Nil(err)
+	its.Nil(value)
+}
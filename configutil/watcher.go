@@ -0,0 +1,361 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package configutil
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/blend/go-sdk/async"
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/fileutil"
+)
+
+// Watcher errors.
+const (
+	// ErrConfigInvalid is returned when a reloaded config fails validation.
+	ErrConfigInvalid ex.Class = "configutil; config is invalid"
+	// ErrConfigPathsMissing is returned when a reload reads no config paths, but the current config was read from paths.
+	ErrConfigPathsMissing ex.Class = "configutil; config reload read no paths"
+)
+
+// Validator is a config that can be validated.
+type Validator interface {
+	Validate() error
+}
+
+// ReadFunc reads a config, e.g. `Read` or `ReadLayered`.
+type ReadFunc func(ref Any, options ...Option) ([]string, error)
+
+// Subscriber is called with the previous and new config when a config is reloaded.
+type Subscriber func(previous, current Any)
+
+// NewWatcher returns a new config watcher.
+//
+// The given function must return a new reference to read a config into each time
+// the config is loaded, with any defaults set, e.g.
+//
+//	w := configutil.NewWatcher(func() configutil.Any { return new(Config) },
+//		configutil.OptWatcherOptions(configutil.OptPaths("config.yml")),
+//	)
+func NewWatcher(newConfig func() Any, opts ...WatcherOption) *Watcher {
+	w := Watcher{
+		Latch:     async.NewLatch(),
+		NewConfig: newConfig,
+		Reader:    Read,
+		Signals:   []os.Signal{syscall.SIGHUP},
+	}
+	for _, opt := range opts {
+		opt(&w)
+	}
+	return &w
+}
+
+// WatcherOption mutates a watcher.
+type WatcherOption func(*Watcher)
+
+// OptWatcherOptions sets the options the config is read with, e.g. the paths.
+func OptWatcherOptions(options ...Option) WatcherOption {
+	return func(w *Watcher) { w.Options = options }
+}
+
+// OptWatcherReader sets the function the config is read with; it defaults to `Read`.
+func OptWatcherReader(reader ReadFunc) WatcherOption {
+	return func(w *Watcher) { w.Reader = reader }
+}
+
+// OptWatcherValidate sets a function that validates a config, in addition to the config's own `Validate` method.
+func OptWatcherValidate(validate func(Any) error) WatcherOption {
+	return func(w *Watcher) { w.Validate = validate }
+}
+
+// OptWatcherSignals sets the signals that cause the config to be reloaded; it defaults to `SIGHUP`.
+func OptWatcherSignals(signals ...os.Signal) WatcherOption {
+	return func(w *Watcher) { w.Signals = signals }
+}
+
+// OptWatcherPollInterval sets the interval config files are polled for changes.
+func OptWatcherPollInterval(pollInterval time.Duration) WatcherOption {
+	return func(w *Watcher) { w.PollInterval = pollInterval }
+}
+
+// OptWatcherSubscribe adds a subscriber.
+func OptWatcherSubscribe(subscriber Subscriber) WatcherOption {
+	return func(w *Watcher) { w.subscribers = append(w.subscribers, subscriber) }
+}
+
+// Watcher reloads a config when the config files read change, or when the process receives one of the `Signals`.
+//
+// Each reload reads a new config (which calls its `Resolve` method if it is a `Resolver`) and validates it
+// (with its `Validate` method if it is a `Validator`, and with `Validate` if set). Only if the reload succeeds
+// is the new config swapped in and the subscribers called; otherwise the error is logged and the last good
+// config is kept. A reload that reads no config paths is rejected if the current config was read from paths,
+// e.g. if the files are briefly missing while they are replaced.
+//
+// The files watched are the config paths of the `Options`, and their environment specific paths
+// (see `EnvPath`), whether or not they exist; the config is reloaded when a file is created, changes,
+// or reappears after it is removed, e.g. after an atomic rename. The paths are re-derived after each reload.
+type Watcher struct {
+	*async.Latch
+
+	// NewConfig returns a new reference to read a config into.
+	NewConfig func() Any
+	// Options are the options the config is read with.
+	Options []Option
+	// Reader reads the config.
+	Reader ReadFunc
+	// Validate optionally validates a config.
+	Validate func(Any) error
+	// Signals are the signals that cause the config to be reloaded.
+	Signals []os.Signal
+	// PollInterval is the interval config files are polled for changes.
+	PollInterval time.Duration
+
+	reloadMu    sync.Mutex
+	mu          sync.RWMutex
+	config      Any
+	paths       []string
+	provenance  Provenance
+	subscribers []Subscriber
+	reloaded    chan struct{}
+}
+
+// Config returns the current config, or nil if it has not been loaded.
+func (w *Watcher) Config() Any {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.config
+}
+
+// Paths returns the paths the current config was read from.
+func (w *Watcher) Paths() []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.paths
+}
+
+// Provenance returns the source of each value of the current config,
+// if it was read with `ReadLayered`.
+func (w *Watcher) Provenance() Provenance {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.provenance
+}
+
+// PollIntervalOrDefault returns the poll interval or a default.
+func (w *Watcher) PollIntervalOrDefault() time.Duration {
+	if w.PollInterval > 0 {
+		return w.PollInterval
+	}
+	return fileutil.DefaultWatchPollInterval
+}
+
+// Subscribe adds a subscriber that is called with the previous and new config each time the config is reloaded.
+func (w *Watcher) Subscribe(subscriber Subscriber) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, subscriber)
+}
+
+// Reload reads, resolves and validates a new config, and if that succeeds
+// swaps it in and calls the subscribers; otherwise the current config is kept.
+func (w *Watcher) Reload() error {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	log := w.log()
+	next := w.NewConfig()
+	var provenance Provenance
+	options := append(append([]Option{}, w.Options...), OptProvenance(&provenance))
+	paths, err := w.Reader(next, options...)
+	if !IsIgnored(err) {
+		MaybeErrorf(log, "config reload rejected: %+v", err)
+		return err
+	}
+	if previousPaths := w.Paths(); len(paths) == 0 && len(previousPaths) > 0 {
+		err = ex.New(ErrConfigPathsMissing, ex.OptMessagef("previous paths: %v", previousPaths))
+		MaybeErrorf(log, "config reload rejected: %+v", err)
+		return err
+	}
+	if err = w.validate(next); err != nil {
+		MaybeErrorf(log, "config reload rejected: %+v", err)
+		return err
+	}
+
+	w.mu.Lock()
+	previous := w.config
+	w.config = next
+	w.paths = paths
+	w.provenance = provenance
+	subscribers := make([]Subscriber, len(w.subscribers))
+	copy(subscribers, w.subscribers)
+	reloaded := w.reloaded
+	w.mu.Unlock()
+
+	// notify the watch loop to re-derive the watched paths
+	select {
+	case reloaded <- struct{}{}:
+	default:
+	}
+
+	MaybeInfof(log, "config reloaded from paths: %v", paths)
+	for _, subscriber := range subscribers {
+		subscriber(previous, next)
+	}
+	return nil
+}
+
+// Start loads the config if it has not been loaded, and then watches for changes until stopped.
+//
+// It returns an error if the initial load fails.
+func (w *Watcher) Start() error {
+	if !w.CanStart() {
+		return ex.New(async.ErrCannotStart)
+	}
+	if w.Config() == nil {
+		if err := w.Reload(); err != nil {
+			return err
+		}
+	}
+	w.Starting()
+
+	reloaded := make(chan struct{}, 1)
+	w.mu.Lock()
+	w.reloaded = reloaded
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		w.reloaded = nil
+		w.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var wg sync.WaitGroup
+	watching := make(map[string]context.CancelFunc)
+	w.syncWatches(ctx, &wg, watching)
+
+	signals := make(chan os.Signal, 1)
+	if len(w.Signals) > 0 {
+		signal.Notify(signals, w.Signals...)
+		defer signal.Stop(signals)
+	}
+
+	w.Started()
+	for {
+		select {
+		case <-signals:
+			MaybeInfof(w.log(), "config reload signaled")
+			_ = w.Reload()
+		case <-reloaded:
+			w.syncWatches(ctx, &wg, watching)
+		case <-w.NotifyStopping():
+			cancel()
+			wg.Wait()
+			w.Stopped()
+			return nil
+		}
+	}
+}
+
+// Stop stops watching for changes.
+func (w *Watcher) Stop() error {
+	if !w.CanStop() {
+		return ex.New(async.ErrCannotStop)
+	}
+	w.Stopping()
+	<-w.NotifyStopped()
+	w.Latch.Reset()
+	return nil
+}
+
+// WatchPaths returns the paths watched for changes, i.e. the config paths of the `Options`
+// and their environment specific paths.
+func (w *Watcher) WatchPaths() (output []string) {
+	configOptions, err := createConfigOptions(w.Options...)
+	if err != nil {
+		return
+	}
+	serviceEnv := configOptions.Env.ServiceEnv()
+	for _, path := range configOptions.FilePaths {
+		if path == "" {
+			continue
+		}
+		output = append(output, path)
+		if serviceEnv != "" {
+			output = append(output, EnvPath(path, serviceEnv))
+		}
+	}
+	return
+}
+
+// syncWatches starts a file watcher for each watched path that is not yet watched,
+// and stops the file watchers of paths that are no longer watched.
+func (w *Watcher) syncWatches(ctx context.Context, wg *sync.WaitGroup, watching map[string]context.CancelFunc) {
+	paths := make(map[string]bool)
+	for _, path := range w.WatchPaths() {
+		paths[path] = true
+		if _, ok := watching[path]; ok {
+			continue
+		}
+		fileCtx, cancel := context.WithCancel(ctx)
+		watching[path] = cancel
+		fw := fileutil.NewWatcher(path, func(_ *os.File) error {
+			_ = w.Reload()
+			return nil
+		},
+			fileutil.OptWatcherPollInterval(w.PollIntervalOrDefault()),
+			fileutil.OptWatcherAllowMissing(true),
+		)
+		fw.Starting()
+		wg.Add(1)
+		done := make(chan struct{})
+		go func() {
+			defer wg.Done()
+			defer close(done)
+			fw.Watch(fileCtx)
+		}()
+		// wait for the file watcher to stat the file so changes after this are seen
+		select {
+		case <-fw.NotifyStarted():
+		case <-done:
+		}
+	}
+	for path, cancel := range watching {
+		if !paths[path] {
+			cancel()
+			delete(watching, path)
+		}
+	}
+}
+
+func (w *Watcher) validate(ref Any) error {
+	if typed, ok := ref.(Validator); ok {
+		if err := typed.Validate(); err != nil {
+			return ex.New(ErrConfigInvalid, ex.OptInner(err))
+		}
+	}
+	if w.Validate != nil {
+		if err := w.Validate(ref); err != nil {
+			return ex.New(ErrConfigInvalid, ex.OptInner(err))
+		}
+	}
+	return nil
+}
+
+func (w *Watcher) log() Logger {
+	configOptions, err := createConfigOptions(w.Options...)
+	if err != nil {
+		return nil
+	}
+	return configOptions.Log
+}
@@ -0,0 +1,255 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package configutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/env"
+	"github.com/blend/go-sdk/ex"
+)
+
+type watchedConfig struct {
+	Name string `yaml:"name"`
+	Port int    `yaml:"port"`
+}
+
+func (wc watchedConfig) Validate() error {
+	if wc.Port < 0 {
+		return fmt.Errorf("invalid port: %d", wc.Port)
+	}
+	return nil
+}
+
+// writeWatchedConfig writes a config file with a given modification time,
+// renaming it into place so the watcher never sees a partial file.
+func writeWatchedConfig(t *testing.T, path, contents string, modTime time.Time) {
+	t.Helper()
+	tempPath := path + ".tmp"
+	if err := os.WriteFile(tempPath, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(tempPath, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestWatcher(path string, opts ...WatcherOption) *Watcher {
+	return NewWatcher(
+		func() Any { return new(watchedConfig) },
+		append([]WatcherOption{
+			OptWatcherOptions(OptPaths(path), OptEnv(env.Vars{})),
+		}, opts...)...,
+	)
+}
+
+func TestWatcherReload(t *testing.T) {
+	its := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	writeWatchedConfig(t, path, "name: first\nport: 80\n", time.Now())
+
+	var previous, current []Any
+	w := newTestWatcher(path, OptWatcherSubscribe(func(p, c Any) {
+		previous = append(previous, p)
+		current = append(current, c)
+	}))
+	its.Nil(w.Config())
+
+	its.Nil(w.Reload())
+	its.Equal([]string{path}, w.Paths())
+	its.Equal("first", w.Config().(*watchedConfig).Name)
+	its.Len(current, 1)
+	its.Nil(previous[0])
+
+	writeWatchedConfig(t, path, "name: second\nport: 81\n", time.Now())
+	its.Nil(w.Reload())
+	its.Equal("second", w.Config().(*watchedConfig).Name)
+	its.Len(current, 2)
+	its.Equal("first", previous[1].(*watchedConfig).Name)
+	its.Equal("second", current[1].(*watchedConfig).Name)
+}
+
+func TestWatcherReloadInvalid(t *testing.T) {
+	its := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	writeWatchedConfig(t, path, "name: first\nport: 80\n", time.Now())
+
+	var calls int
+	w := newTestWatcher(path,
+		OptWatcherValidate(func(ref Any) error {
+			if ref.(*watchedConfig).Name == "" {
+				return fmt.Errorf("name is required")
+			}
+			return nil
+		}),
+		OptWatcherSubscribe(func(_, _ Any) { calls++ }),
+	)
+	its.Nil(w.Reload())
+	its.Equal(1, calls)
+
+	// fails the config's own validation
+	writeWatchedConfig(t, path, "name: second\nport: -1\n", time.Now())
+	err := w.Reload()
+	its.True(ex.Is(err, ErrConfigInvalid))
+	its.Equal("first", w.Config().(*watchedConfig).Name)
+
+	// fails the custom validation
+	writeWatchedConfig(t, path, "port: 82\n", time.Now())
+	its.NotNil(w.Reload())
+	its.Equal(80, w.Config().(*watchedConfig).Port)
+
+	// fails to parse
+	writeWatchedConfig(t, path, "port: [", time.Now())
+	its.NotNil(w.Reload())
+	its.Equal(80, w.Config().(*watchedConfig).Port)
+	its.Equal(1, calls)
+}
+
+func TestWatcherStart(t *testing.T) {
+	its := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	writeWatchedConfig(t, path, "name: first\n", time.Now().Add(-time.Minute))
+
+	reloaded := make(chan string, 4)
+	w := newTestWatcher(path,
+		OptWatcherPollInterval(5*time.Millisecond),
+		OptWatcherSignals(syscall.SIGUSR2),
+		OptWatcherSubscribe(func(_, c Any) { reloaded <- c.(*watchedConfig).Name }),
+	)
+
+	errors := make(chan error, 1)
+	go func() { errors <- w.Start() }()
+	<-w.NotifyStarted()
+	its.Equal("first", <-reloaded)
+
+	writeWatchedConfig(t, path, "name: second\n", time.Now())
+	its.Equal("second", <-reloaded)
+
+	writeWatchedConfig(t, path, "name: third\n", time.Now())
+	its.Nil(syscall.Kill(os.Getpid(), syscall.SIGUSR2))
+	its.Equal("third", <-reloaded)
+
+	its.Nil(w.Stop())
+	its.Nil(<-errors)
+}
+
+func TestWatcherReloadPathsMissing(t *testing.T) {
+	its := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	writeWatchedConfig(t, path, "name: first\n", time.Now())
+
+	w := newTestWatcher(path)
+	its.Equal([]os.Signal{syscall.SIGHUP}, w.Signals)
+	its.Nil(w.Reload())
+
+	its.Nil(os.Remove(path))
+	err := w.Reload()
+	its.True(ex.Is(err, ErrConfigPathsMissing))
+	its.Equal("first", w.Config().(*watchedConfig).Name)
+	its.Equal([]string{path}, w.Paths())
+}
+
+func TestWatcherStartReplaced(t *testing.T) {
+	its := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	writeWatchedConfig(t, path, "name: first\n", time.Now().Add(-time.Minute))
+
+	reloaded := make(chan string, 4)
+	w := newTestWatcher(path,
+		OptWatcherPollInterval(5*time.Millisecond),
+		OptWatcherSubscribe(func(_, c Any) { reloaded <- c.(*watchedConfig).Name }),
+	)
+
+	errors := make(chan error, 1)
+	go func() { errors <- w.Start() }()
+	<-w.NotifyStarted()
+	its.Equal("first", <-reloaded)
+
+	// the file is missing for a few polls while it is replaced
+	its.Nil(os.Remove(path))
+	time.Sleep(25 * time.Millisecond)
+	writeWatchedConfig(t, path, "name: second\n", time.Now().Add(-time.Hour))
+	its.Equal("second", <-reloaded)
+
+	its.Nil(w.Stop())
+	its.Nil(<-errors)
+}
+
+func TestWatcherStartCreated(t *testing.T) {
+	its := assert.New(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yml")
+	writeWatchedConfig(t, path, "name: first\nport: 80\n", time.Now().Add(-time.Minute))
+	missingPath := filepath.Join(dir, "missing.yml")
+
+	reloaded := make(chan *watchedConfig, 4)
+	w := NewWatcher(
+		func() Any { return new(watchedConfig) },
+		OptWatcherOptions(OptPaths(path, missingPath), OptEnv(env.Vars{env.VarServiceEnv: "prod"})),
+		OptWatcherReader(ReadLayered),
+		OptWatcherPollInterval(5*time.Millisecond),
+		OptWatcherSubscribe(func(_, c Any) { reloaded <- c.(*watchedConfig) }),
+	)
+	its.Equal([]string{
+		path,
+		filepath.Join(dir, "config.prod.yml"),
+		missingPath,
+		filepath.Join(dir, "missing.prod.yml"),
+	}, w.WatchPaths())
+
+	errors := make(chan error, 1)
+	go func() { errors <- w.Start() }()
+	<-w.NotifyStarted()
+	its.Equal("first", (<-reloaded).Name)
+
+	// an environment specific file created after the initial load
+	writeWatchedConfig(t, filepath.Join(dir, "config.prod.yml"), "name: prod\n", time.Now().Add(-time.Hour))
+	current := <-reloaded
+	its.Equal("prod", current.Name)
+	its.Equal(80, current.Port)
+
+	// a path missing at the initial load
+	writeWatchedConfig(t, missingPath, "port: 81\n", time.Now().Add(-time.Hour))
+	current = <-reloaded
+	its.Equal("prod", current.Name)
+	its.Equal(81, current.Port)
+
+	its.Nil(w.Stop())
+	its.Nil(<-errors)
+}
+
+func TestWatcherProvenance(t *testing.T) {
+	its := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	writeWatchedConfig(t, path, "name: first\n", time.Now())
+
+	w := newTestWatcher(path, OptWatcherReader(ReadLayered))
+	its.Nil(w.Reload())
+	source, ok := w.Provenance().Source("name")
+	its.True(ok)
+	its.Equal(Source{Kind: SourceKindFile, Name: path}, source)
+	source, ok = w.Provenance().Source("port")
+	its.True(ok)
+	its.Equal(Source{Kind: SourceKindDefaults}, source)
+}
@@ -7,5 +7,11 @@ Use of this source code is governed by a MIT license that can be found in the LI
 
 /*
 Package env contains environment variable helpers, enabling better tests and easier use of environment variables.
+
+Variables can also be loaded from dotenv files, e.g.
+
+	dotenv, err := env.ReadDotenvFile(".env")
+	...
+	vars := env.New(env.OptEnviron(os.Environ()...), env.OptDotenv(dotenv))
 */
 package env // import "github.com/blend/go-sdk/env"
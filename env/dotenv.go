@@ -0,0 +1,351 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package env
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+// ErrInvalidDotenv is returned when dotenv contents cannot be parsed.
+const ErrInvalidDotenv ex.Class = "env; invalid dotenv"
+
+// DotenvEntry is a variable from a dotenv file.
+type DotenvEntry struct {
+	Key string
+	// Value is the value with quotes removed; if it is expanded, `\\` and `\$` escapes are kept until it is expanded.
+	Value string
+	// Expand is if variable references in the value are expanded, i.e. if the value is not single quoted.
+	Expand bool
+}
+
+// Dotenv is the variables from a dotenv file, in the order they're defined.
+type Dotenv []DotenvEntry
+
+// ReadDotenvFile reads and parses a dotenv file.
+func ReadDotenvFile(path string) (Dotenv, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, ex.New(err)
+	}
+	defer f.Close()
+	return ParseDotenv(f)
+}
+
+// ParseDotenv parses dotenv contents.
+/*
+An example of this format:
+
+	# comments and blank lines are ignored
+	export SERVICE_NAME=example  # the export prefix and trailing comments are optional
+	DB_HOST="db.${SERVICE_ENV:-dev}.example.com"
+	DB_PASSWORD='literal $value'
+	CERT="-----BEGIN CERTIFICATE-----
+	...
+	-----END CERTIFICATE-----"
+
+Unquoted values are trimmed, and may use `\$` and `\\` for a literal dollar sign and backslash. Double quoted values may span lines, and support the escapes `\n`, `\r`, `\t`, `\"`, `\\` and `\$`.
+Single quoted values may span lines, and are used as is.
+
+Variable references in unquoted and double quoted values, i.e. `$VAR`, `${VAR}`, `${VAR:-default}` (if unset or empty)
+and `${VAR-default}` (if unset), are expanded when the variables are resolved with `Vars` or `OptDotenv`.
+*/
+func ParseDotenv(r io.Reader) (Dotenv, error) {
+	contents, err := io.ReadAll(r)
+	if err != nil {
+		return nil, ex.New(err)
+	}
+	p := dotenvParser{input: strings.ReplaceAll(string(contents), "\r\n", "\n")}
+	return p.parse()
+}
+
+// Vars resolves the dotenv variables, expanding variable references.
+//
+// References are resolved from the existing variables first, and then from the variables
+// defined earlier in the dotenv file; unresolved references are empty.
+// The existing variables are only used for references, and are not included in the output.
+func (d Dotenv) Vars(existing Vars) Vars {
+	output := make(Vars)
+	lookup := func(key string) (string, bool) {
+		if existing != nil {
+			if value, ok := existing[key]; ok {
+				return value, true
+			}
+		}
+		value, ok := output[key]
+		return value, ok
+	}
+	for _, entry := range d {
+		if entry.Expand {
+			output[entry.Key] = expandDotenv(entry.Value, lookup)
+			continue
+		}
+		output[entry.Key] = entry.Value
+	}
+	return output
+}
+
+type dotenvParser struct {
+	input string
+	pos   int
+}
+
+func (p *dotenvParser) parse() (output Dotenv, err error) {
+	for {
+		p.skipWhitespace()
+		if p.eof() {
+			return
+		}
+		switch p.peek() {
+		case '\n':
+			p.pos++
+			continue
+		case '#':
+			p.skipLine()
+			continue
+		}
+
+		if strings.HasPrefix(p.input[p.pos:], "export") && p.pos+6 < len(p.input) && (p.input[p.pos+6] == ' ' || p.input[p.pos+6] == '\t') {
+			p.pos += 6
+			p.skipWhitespace()
+		}
+		var entry DotenvEntry
+		if entry.Key, err = p.parseKey(); err != nil {
+			return
+		}
+		p.skipWhitespace()
+		if p.eof() || p.peek() != '=' {
+			err = p.errorf("expected `=` after key `%s`", entry.Key)
+			return
+		}
+		p.pos++
+		p.skipWhitespace()
+		if entry.Value, entry.Expand, err = p.parseValue(); err != nil {
+			return
+		}
+		output = append(output, entry)
+	}
+}
+
+func (p *dotenvParser) parseKey() (string, error) {
+	start := p.pos
+	for !p.eof() && isDotenvKeyChar(p.peek(), p.pos == start) {
+		p.pos++
+	}
+	if p.pos == start {
+		if p.eof() {
+			return "", p.errorf("expected a key")
+		}
+		return "", p.errorf("invalid key character %q", p.peek())
+	}
+	return p.input[start:p.pos], nil
+}
+
+// parseValue parses a value and the rest of its line.
+func (p *dotenvParser) parseValue() (value string, expand bool, err error) {
+	if p.eof() {
+		return
+	}
+	switch p.peek() {
+	case '\'':
+		p.pos++
+		end := strings.IndexByte(p.input[p.pos:], '\'')
+		if end < 0 {
+			err = p.errorf("unterminated single quoted value")
+			return
+		}
+		value = p.input[p.pos : p.pos+end]
+		p.pos += end + 1
+	case '"':
+		p.pos++
+		if value, err = p.parseDoubleQuoted(); err != nil {
+			return
+		}
+		expand = true
+	default:
+		// include the whitespace before the value, so a comment directly after the `=` is found
+		start := p.pos
+		for start > 0 && (p.input[start-1] == ' ' || p.input[start-1] == '\t') {
+			start--
+		}
+		end := strings.IndexByte(p.input[p.pos:], '\n')
+		if end < 0 {
+			end = len(p.input) - p.pos
+		}
+		value = p.input[start : p.pos+end]
+		p.pos += end
+		// a comment must be preceded by whitespace, so `#` can be used within values
+		for index := 1; index < len(value); index++ {
+			if value[index] == '#' && (value[index-1] == ' ' || value[index-1] == '\t') {
+				value = value[:index]
+				break
+			}
+		}
+		value = strings.TrimSpace(value)
+		expand = true
+		return
+	}
+
+	// only whitespace and a comment may follow a quoted value
+	p.skipWhitespace()
+	if p.eof() {
+		return
+	}
+	switch p.peek() {
+	case '\n':
+		p.pos++
+	case '#':
+		p.skipLine()
+	default:
+		err = p.errorf("unexpected %q after quoted value", p.peek())
+	}
+	return
+}
+
+// parseDoubleQuoted parses the rest of a double quoted value after the opening quote.
+//
+// Escaped dollar signs and backslashes are kept escaped so they are resolved when the value is expanded,
+// e.g. `\\$HOME` is a backslash followed by the value of `HOME`.
+func (p *dotenvParser) parseDoubleQuoted() (string, error) {
+	var output strings.Builder
+	for !p.eof() {
+		c := p.peek()
+		p.pos++
+		switch c {
+		case '"':
+			return output.String(), nil
+		case '\\':
+			if p.eof() {
+				return "", p.errorf("unterminated double quoted value")
+			}
+			escaped := p.peek()
+			p.pos++
+			switch escaped {
+			case 'n':
+				output.WriteByte('\n')
+			case 'r':
+				output.WriteByte('\r')
+			case 't':
+				output.WriteByte('\t')
+			case '"':
+				output.WriteByte(escaped)
+			default:
+				output.WriteByte('\\')
+				output.WriteByte(escaped)
+			}
+		default:
+			output.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated double quoted value")
+}
+
+func (p *dotenvParser) skipWhitespace() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+func (p *dotenvParser) skipLine() {
+	for !p.eof() && p.peek() != '\n' {
+		p.pos++
+	}
+}
+
+func (p *dotenvParser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *dotenvParser) peek() byte {
+	return p.input[p.pos]
+}
+
+func (p *dotenvParser) errorf(format string, args ...interface{}) error {
+	line := strings.Count(p.input[:p.pos], "\n") + 1
+	return ex.New(ErrInvalidDotenv, ex.OptMessagef("line %d: "+format, append([]interface{}{line}, args...)...))
+}
+
+func isDotenvKeyChar(c byte, first bool) bool {
+	if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_' {
+		return true
+	}
+	return !first && ((c >= '0' && c <= '9') || c == '.')
+}
+
+// expandDotenv expands `$VAR`, `${VAR}`, `${VAR:-default}` and `${VAR-default}` references in a value;
+// `\$` is a literal dollar sign and `\\` a literal backslash.
+func expandDotenv(value string, lookup func(string) (string, bool)) string {
+	var output strings.Builder
+	for index := 0; index < len(value); index++ {
+		c := value[index]
+		if c == '\\' && index+1 < len(value) && (value[index+1] == '$' || value[index+1] == '\\') {
+			output.WriteByte(value[index+1])
+			index++
+			continue
+		}
+		if c != '$' || index+1 == len(value) {
+			output.WriteByte(c)
+			continue
+		}
+
+		if value[index+1] == '{' {
+			end := matchingBrace(value[index:])
+			if end < 0 {
+				output.WriteByte(c)
+				continue
+			}
+			reference := value[index+2 : index+end]
+			index += end
+			key, defaultValue, operator := reference, "", ""
+			if split := strings.Index(reference, ":-"); split >= 0 {
+				key, defaultValue, operator = reference[:split], reference[split+2:], ":-"
+			} else if split := strings.IndexByte(reference, '-'); split >= 0 {
+				key, defaultValue, operator = reference[:split], reference[split+1:], "-"
+			}
+			resolved, ok := lookup(key)
+			if (operator == ":-" && resolved == "") || (operator == "-" && !ok) {
+				resolved = expandDotenv(defaultValue, lookup)
+			}
+			output.WriteString(resolved)
+			continue
+		}
+
+		end := index + 1
+		for end < len(value) && isDotenvKeyChar(value[end], end == index+1) && value[end] != '.' {
+			end++
+		}
+		if end == index+1 {
+			output.WriteByte(c)
+			continue
+		}
+		resolved, _ := lookup(value[index+1 : end])
+		output.WriteString(resolved)
+		index = end - 1
+	}
+	return output.String()
+}
+
+// matchingBrace returns the index of the brace that closes the `${` a value starts with, or -1.
+func matchingBrace(value string) int {
+	var depth int
+	for index := 1; index < len(value); index++ {
+		switch value[index] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return index
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,140 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/ex"
+)
+
+const dotenvContents = `# a dotenv file
+SERVICE_NAME=example
+export SERVICE_ENV = dev   # trailing comment
+EMPTY=
+EMPTY_COMMENT= # only a comment
+HASH=foo#bar
+DB_HOST="db.${SERVICE_ENV}.example.com"
+DB_URL=postgres://$DB_USER@${DB_HOST}:${DB_PORT:-5432}/${DB_NAME-$SERVICE_NAME}
+LITERAL='single $SERVICE_NAME \n "quoted"'
+ESCAPED="tab\tnewline\nquote\" dollar\$SERVICE_NAME backslash\\"
+BACKSLASH_DOLLAR="\\$SERVICE_NAME"
+MULTILINE="first
+second"
+MULTILINE_LITERAL='first
+second'
+WINDOWS=crlf` + "\r\n" + `
+dotted.key=value
+`
+
+func TestParseDotenv(t *testing.T) {
+	its := assert.New(t)
+
+	dotenv, err := ParseDotenv(strings.NewReader(dotenvContents))
+	its.Nil(err)
+	its.Len(dotenv, 14)
+	its.Equal(DotenvEntry{Key: "SERVICE_NAME", Value: "example", Expand: true}, dotenv[0])
+	its.Equal(DotenvEntry{Key: "LITERAL", Value: `single $SERVICE_NAME \n "quoted"`}, dotenv[7])
+
+	vars := dotenv.Vars(Vars{"DB_USER": "admin"})
+	its.Equal(Vars{
+		"SERVICE_NAME":      "example",
+		"SERVICE_ENV":       "dev",
+		"EMPTY":             "",
+		"EMPTY_COMMENT":     "",
+		"HASH":              "foo#bar",
+		"DB_HOST":           "db.dev.example.com",
+		"DB_URL":            "postgres://admin@db.dev.example.com:5432/example",
+		"LITERAL":           `single $SERVICE_NAME \n "quoted"`,
+		"ESCAPED":           "tab\tnewline\nquote\" dollar$SERVICE_NAME backslash\\",
+		"BACKSLASH_DOLLAR":  "\\example",
+		"MULTILINE":         "first\nsecond",
+		"MULTILINE_LITERAL": "first\nsecond",
+		"WINDOWS":           "crlf",
+		"dotted.key":        "value",
+	}, vars)
+}
+
+func TestParseDotenvInvalid(t *testing.T) {
+	its := assert.New(t)
+
+	invalid := []string{
+		"1KEY=value",
+		"KEY value",
+		"KEY",
+		`KEY="unterminated`,
+		"KEY='unterminated",
+		`KEY="value" trailing`,
+		"-KEY=value",
+	}
+	for _, contents := range invalid {
+		_, err := ParseDotenv(strings.NewReader(contents))
+		its.True(ex.Is(err, ErrInvalidDotenv), contents)
+	}
+
+	_, err := ParseDotenv(strings.NewReader("A=1\n# comment\nB 2"))
+	its.Contains(ex.ErrMessage(err), "line 3")
+}
+
+func TestExpandDotenv(t *testing.T) {
+	its := assert.New(t)
+
+	lookup := func(key string) (string, bool) {
+		value, ok := Vars{"SET": "set", "EMPTY": ""}[key]
+		return value, ok
+	}
+	testCases := map[string]string{
+		"$SET":                 "set",
+		"${SET}s":              "sets",
+		"$SET.suffix":          "set.suffix",
+		"${UNSET}":             "",
+		"${EMPTY:-default}":    "default",
+		"${EMPTY-default}":     "",
+		"${UNSET-default}":     "default",
+		"${UNSET:-${SET}}":     "set",
+		"\\$SET":               "$SET",
+		"\\\\$SET":             "\\set",
+		"\\\\\\$SET":           "\\$SET",
+		"cost: $5":             "cost: $5",
+		"trailing $":           "trailing $",
+		"${unterminated":       "${unterminated",
+		"$SET$SET":             "setset",
+		"prefix-${SET}-suffix": "prefix-set-suffix",
+	}
+	for input, expected := range testCases {
+		its.Equal(expected, expandDotenv(input, lookup), input)
+	}
+}
+
+func TestOptDotenv(t *testing.T) {
+	its := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), ".env")
+	its.Nil(os.WriteFile(path, []byte("HOME_DIR=${HOME}/app\nPORT=8080\nSERVICE_ENV=dev\n"), 0644))
+	dotenv, err := ReadDotenvFile(path)
+	its.Nil(err)
+
+	vars := New(OptEnviron("HOME=/home/example", "SERVICE_ENV=prod"), OptDotenv(dotenv))
+	its.Equal("/home/example/app", vars.String("HOME_DIR"))
+	its.Equal("8080", vars.String("PORT"))
+	its.Equal("prod", vars.String("SERVICE_ENV"))
+
+	type config struct {
+		Port int `env:"PORT"`
+	}
+	var cfg config
+	its.Nil(vars.ReadInto(&cfg))
+	its.Equal(8080, cfg.Port)
+
+	_, err = ReadDotenvFile(filepath.Join(t.TempDir(), "missing.env"))
+	its.True(os.IsNotExist(ex.ErrClass(err)))
+}
@@ -40,3 +40,18 @@ func OptRemove(keys ...string) Option {
 		}
 	}
 }
+
+// OptDotenv sets the variables from a dotenv file (see `ParseDotenv`) that are not already set,
+// so variables set by earlier options (e.g. `OptEnviron`) take precedence.
+//
+// Variable references in the dotenv values are resolved from the variables set by earlier options,
+// and then from the variables defined earlier in the dotenv file.
+func OptDotenv(dotenv Dotenv) Option {
+	return func(vars Vars) {
+		for key, value := range dotenv.Vars(vars) {
+			if _, ok := vars[key]; !ok {
+				vars[key] = value
+			}
+		}
+	}
+}
@@ -8,6 +8,7 @@ Use of this source code is governed by a MIT license that can be found in the LI
 package fileutil
 
 import (
+	"io"
 	"os"
 	"sync"
 
@@ -59,6 +60,9 @@ func (tf *Temp) Read(buffer []byte) (int, error) {
 	defer tf.Unlock()
 
 	read, err := tf.file.Read(buffer)
+	if err == io.EOF {
+		return read, err
+	}
 	return read, ex.New(err)
 }
 
@@ -71,9 +75,23 @@ func (tf *Temp) ReadAt(buffer []byte, off int64) (int, error) {
 	defer tf.Unlock()
 
 	read, err := tf.file.ReadAt(buffer, off)
+	if err == io.EOF {
+		return read, err
+	}
 	return read, ex.New(err)
 }
 
+// Seek sets the offset for the next Read or Write on file to offset, interpreted
+// according to whence: 0 means relative to the origin of the file, 1 means
+// relative to the current offset, and 2 means relative to the end.
+func (tf *Temp) Seek(offset int64, whence int) (int64, error) {
+	tf.Lock()
+	defer tf.Unlock()
+
+	position, err := tf.file.Seek(offset, whence)
+	return position, ex.New(err)
+}
+
 // Write writes len(b) bytes to the File.
 // It returns the number of bytes written and an error, if any.
 // Write returns a non-nil error when n != len(b).
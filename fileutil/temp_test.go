@@ -0,0 +1,48 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package fileutil_test
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/fileutil"
+)
+
+func TestTemp(t *testing.T) {
+	t.Parallel()
+	it := assert.New(t)
+
+	temp, err := fileutil.NewTemp([]byte("hello"))
+	it.Nil(err)
+	name := temp.Name()
+
+	_, err = temp.Seek(0, io.SeekStart)
+	it.Nil(err)
+
+	// io.ReadAll relies on a bare io.EOF to stop reading.
+	contents, err := io.ReadAll(temp)
+	it.Nil(err)
+	it.Equal("hello", string(contents))
+
+	read, err := temp.Read(make([]byte, 8))
+	it.Zero(read)
+	it.Equal(io.EOF, err)
+
+	buffer := make([]byte, 8)
+	read, err = temp.ReadAt(buffer, 2)
+	it.Equal(3, read)
+	it.Equal(io.EOF, err)
+	it.Equal("llo", string(buffer[:read]))
+
+	it.Nil(temp.Close())
+	_, err = os.Stat(name)
+	it.True(os.IsNotExist(err))
+}
@@ -59,12 +59,24 @@ type WatchAction func(*os.File) error
 // WatcherOption is an option for a watcher.
 type WatcherOption func(*Watcher)
 
+// OptWatcherPollInterval sets the interval the file is polled for changes.
+func OptWatcherPollInterval(pollInterval time.Duration) WatcherOption {
+	return func(w *Watcher) { w.PollInterval = pollInterval }
+}
+
+// OptWatcherAllowMissing sets if the watcher should keep polling a file that does not exist,
+// and call the action when it is created, rather than stopping.
+func OptWatcherAllowMissing(allowMissing bool) WatcherOption {
+	return func(w *Watcher) { w.AllowMissing = allowMissing }
+}
+
 // Watcher watches a file for changes and calls the action.
 type Watcher struct {
 	*async.Latch
 
 	Path         string
 	PollInterval time.Duration
+	AllowMissing bool
 	Action       func(*os.File) error
 	Errors       chan error
 }
@@ -78,15 +90,21 @@ func (w Watcher) PollIntervalOrDefault() time.Duration {
 }
 
 // Watch watches a given file.
+//
+// If `AllowMissing` is set, a file that does not exist (or is removed) is polled until
+// it is created, and the action is then called.
 func (w Watcher) Watch(ctx context.Context) {
+	var lastMod time.Time
 	stat, err := os.Stat(w.Path)
-	if err != nil {
+	if err != nil && !w.isAllowedMissing(err) {
 		w.handleError(ex.New(err))
 		return
 	}
+	if err == nil {
+		lastMod = stat.ModTime()
+	}
 
 	w.Started()
-	lastMod := stat.ModTime()
 	ticker := time.NewTicker(w.PollIntervalOrDefault())
 	defer ticker.Stop()
 
@@ -104,12 +122,21 @@ func (w Watcher) Watch(ctx context.Context) {
 		case <-ticker.C:
 			stat, err = os.Stat(w.Path)
 			if err != nil {
+				if w.isAllowedMissing(err) {
+					// the file is reloaded when it is created again, whatever its modification time
+					lastMod = time.Time{}
+					continue
+				}
 				w.handleError(ex.New(err))
 				return
 			}
 			if stat.ModTime().After(lastMod) {
 				file, err := os.Open(w.Path)
 				if err != nil {
+					if w.isAllowedMissing(err) {
+						lastMod = time.Time{}
+						continue
+					}
 					w.handleError(ex.New(err))
 					return
 				}
@@ -140,6 +167,10 @@ func (w Watcher) Watch(ctx context.Context) {
 	}
 }
 
+func (w Watcher) isAllowedMissing(err error) bool {
+	return w.AllowMissing && os.IsNotExist(err)
+}
+
 func (w Watcher) handleError(err error) {
 	if err == nil {
 		return
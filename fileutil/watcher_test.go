@@ -0,0 +1,85 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package fileutil
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func writeWatchedFile(t *testing.T, path, contents string, modTime time.Time) {
+	t.Helper()
+	tempPath := path + ".tmp"
+	if err := os.WriteFile(tempPath, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(tempPath, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatcherMissing(t *testing.T) {
+	its := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "file.txt")
+	errors := make(chan error, 1)
+	w := NewWatcher(path, func(_ *os.File) error { return nil })
+	w.Errors = errors
+	w.Starting()
+	w.Watch(context.Background())
+	its.Len(errors, 1)
+}
+
+func TestWatcherAllowMissing(t *testing.T) {
+	its := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "file.txt")
+	contents := make(chan string, 4)
+	w := NewWatcher(path, func(f *os.File) error {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return err
+		}
+		contents <- string(data)
+		return nil
+	},
+		OptWatcherPollInterval(5*time.Millisecond),
+		OptWatcherAllowMissing(true),
+	)
+	errors := make(chan error, 1)
+	w.Errors = errors
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w.Starting()
+	go w.Watch(ctx)
+	<-w.NotifyStarted()
+
+	// created after the watch started
+	writeWatchedFile(t, path, "first", time.Now())
+	its.Equal("first", <-contents)
+
+	// removed, and then created again with an older modification time
+	its.Nil(os.Remove(path))
+	time.Sleep(25 * time.Millisecond)
+	writeWatchedFile(t, path, "second", time.Now().Add(-time.Hour))
+	its.Equal("second", <-contents)
+
+	cancel()
+	<-w.NotifyStopped()
+	its.Empty(errors)
+}
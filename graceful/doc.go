@@ -7,5 +7,15 @@ Use of this source code is governed by a MIT license that can be found in the LI
 
 /*
 Package graceful provides a mechanism to gracefully stop processes on os signals.
+
+It can also restart a process without dropping connections, if opted in: create a handoff with `graceful.NewHandoff`,
+create listeners with it (e.g. with `web.OptHandoff`, `webutil.OptGracefulHTTPServerHandoff` or `grpcutil.CreateHandoffListener`)
+and use `graceful.ShutdownWithHandoff`. On SIGUSR2 the listening sockets are passed to a new instance of the binary,
+and the current process drains and exits once the new one has started.
+
+To stop processes in a specific order, e.g. the http server, then queue workers, then cron jobs, then database
+connections, group them into phases of a `graceful.Plan` and use `graceful.ShutdownPlan`. Each phase can have its own
+timeout, and a `graceful.Readiness` flag (which can be registered with `status.OptReadiness`) is cleared before draining
+starts so load balancers stop sending traffic first.
 */
 package graceful // import "github.com/blend/go-sdk/graceful"
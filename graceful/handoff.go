@@ -0,0 +1,325 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package graceful
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+// Handoff environment variables.
+const (
+	// EnvVarHandoffListeners holds the `network:addr` keys of the listeners passed to a child process,
+	// separated by `;`, in file descriptor order starting at 3.
+	EnvVarHandoffListeners = "GRACEFUL_HANDOFF_LISTENERS"
+	// EnvVarHandoffReadyFD holds the file descriptor a child process writes to once it is ready.
+	EnvVarHandoffReadyFD = "GRACEFUL_HANDOFF_READY_FD"
+)
+
+// DefaultHandoffReadyTimeout is the default time to wait for a child process to signal it's ready.
+const DefaultHandoffReadyTimeout = 30 * time.Second
+
+// Handoff errors.
+const (
+	ErrHandoffChildNotReady ex.Class = "graceful; handoff child process exited before signaling ready"
+	ErrHandoffReadyTimeout  ex.Class = "graceful; handoff child process did not signal ready in time"
+)
+
+// handoff file descriptors start after stdin, stdout and stderr.
+const handoffFirstFD = 3
+
+// NewHandoff returns a new handoff, reading inherited listeners from the environment.
+//
+// Handoffs are opt in; a process should create one handoff and pass it to the servers that should
+// hand off their listeners (e.g. with `web.OptHandoff`) and to `ShutdownWithHandoff`.
+// The handoff environment variables are unset so they are not passed on to unrelated child processes.
+func NewHandoff(opts ...HandoffOption) *Handoff {
+	h := Handoff{
+		ReadyTimeout: DefaultHandoffReadyTimeout,
+		inherited:    map[string]*os.File{},
+	}
+	if keys := os.Getenv(EnvVarHandoffListeners); keys != "" {
+		for index, key := range strings.Split(keys, ";") {
+			fd := handoffFirstFD + index
+			h.inherited[key] = os.NewFile(uintptr(fd), key)
+		}
+	}
+	if readyFD, err := strconv.Atoi(os.Getenv(EnvVarHandoffReadyFD)); err == nil {
+		h.readyFile = os.NewFile(uintptr(readyFD), "handoff-ready")
+	}
+	_ = os.Unsetenv(EnvVarHandoffListeners)
+	_ = os.Unsetenv(EnvVarHandoffReadyFD)
+
+	for _, opt := range opts {
+		opt(&h)
+	}
+	return &h
+}
+
+// HandoffOption mutates a handoff.
+type HandoffOption func(*Handoff)
+
+// OptHandoffLog sets the handoff logger.
+func OptHandoffLog(log Logger) HandoffOption {
+	return func(h *Handoff) { h.Log = log }
+}
+
+// OptHandoffReadyTimeout sets the time to wait for a child process to signal it's ready.
+func OptHandoffReadyTimeout(d time.Duration) HandoffOption {
+	return func(h *Handoff) { h.ReadyTimeout = d }
+}
+
+// OptHandoffCommand sets the executable, arguments and extra environment used to start the child process.
+//
+// By default the child is started with the current executable, arguments and environment.
+func OptHandoffCommand(executable string, args []string, env ...string) HandoffOption {
+	return func(h *Handoff) {
+		h.Executable = executable
+		h.Args = args
+		h.Env = env
+	}
+}
+
+// Handoff passes listening sockets from a running process to a new instance of the process
+// so that the binary can be restarted without refusing connections.
+//
+// The parent starts the child with the listener file descriptors inherited, waits for the
+// child to call `Ready`, and then drains and exits as if it had received a shutdown signal.
+type Handoff struct {
+	sync.Mutex
+	upgradeLock sync.Mutex
+
+	Log          Logger
+	ReadyTimeout time.Duration
+	Executable   string
+	Args         []string
+	Env          []string
+
+	inherited map[string]*os.File
+	listeners []handoffListener
+	readyFile *os.File
+}
+
+// IsChild returns if the process was started by a handoff.
+func (h *Handoff) IsChild() bool {
+	h.Lock()
+	defer h.Unlock()
+	return h.readyFile != nil || len(h.inherited) > 0
+}
+
+// Listen returns an inherited listener for a given network and address, or creates a new one.
+//
+// Either way the listener is tracked so that it is passed to the child process on `Upgrade`,
+// until it is released with `Release`.
+func (h *Handoff) Listen(network, addr string) (net.Listener, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	key := handoffKey(network, addr)
+	if file, ok := h.inherited[key]; ok {
+		delete(h.inherited, key)
+		listener, err := net.FileListener(file)
+		_ = file.Close()
+		if err != nil {
+			return nil, ex.New(err, ex.OptMessagef("listener: %s", key))
+		}
+		MaybeInfof(h.Log, "graceful handoff; using inherited listener %s", key)
+		h.listeners = append(h.listeners, handoffListener{key: key, listener: listener})
+		return listener, nil
+	}
+
+	listener, err := net.Listen(network, addr)
+	if err != nil {
+		return nil, ex.New(err)
+	}
+	if typed, ok := listener.(*net.UnixListener); ok {
+		typed.SetUnlinkOnClose(true)
+	}
+	h.listeners = append(h.listeners, handoffListener{key: key, listener: listener})
+	return listener, nil
+}
+
+// Release stops tracking a listener, typically once it has been closed, so it is not passed to a child process.
+func (h *Handoff) Release(listener net.Listener) {
+	h.Lock()
+	defer h.Unlock()
+	for index, hl := range h.listeners {
+		if hl.listener == listener {
+			h.listeners = append(h.listeners[:index], h.listeners[index+1:]...)
+			return
+		}
+	}
+}
+
+// Ready signals the parent process, if any, that this process is ready to serve.
+// It is safe to call more than once.
+func (h *Handoff) Ready() error {
+	h.Lock()
+	defer h.Unlock()
+
+	// close any inherited listeners that were never claimed.
+	for key, file := range h.inherited {
+		_ = file.Close()
+		delete(h.inherited, key)
+	}
+	if h.readyFile == nil {
+		return nil
+	}
+	MaybeInfof(h.Log, "graceful handoff; signaling parent process ready")
+	_, err := h.readyFile.Write([]byte{1})
+	_ = h.readyFile.Close()
+	h.readyFile = nil
+	return ex.New(err)
+}
+
+// Upgrade starts a new instance of the process, passing it the tracked listeners,
+// and waits for it to signal that it is ready.
+//
+// If the child exits or does not signal ready before the ready timeout, it is killed and an error is returned;
+// the current process should keep serving in that case.
+func (h *Handoff) Upgrade() (*os.Process, error) {
+	// only one upgrade runs at a time, but listeners can be added and released while waiting for the child.
+	h.upgradeLock.Lock()
+	defer h.upgradeLock.Unlock()
+
+	keys, files, listeners := h.listenerFiles()
+	defer func() {
+		for _, file := range files {
+			_ = file.Close()
+		}
+	}()
+
+	readyReader, readyWriter, err := os.Pipe()
+	if err != nil {
+		return nil, ex.New(err)
+	}
+	defer readyReader.Close()
+
+	executable := h.Executable
+	args := h.Args
+	if executable == "" {
+		if executable, err = os.Executable(); err != nil {
+			_ = readyWriter.Close()
+			return nil, ex.New(err)
+		}
+		args = os.Args[1:]
+	}
+
+	cmd := exec.Command(executable, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = append(append([]*os.File{}, files...), readyWriter)
+	cmd.Env = append(handoffEnviron(), h.Env...)
+	cmd.Env = append(cmd.Env,
+		fmt.Sprintf("%s=%s", EnvVarHandoffListeners, strings.Join(keys, ";")),
+		fmt.Sprintf("%s=%d", EnvVarHandoffReadyFD, handoffFirstFD+len(files)),
+	)
+
+	MaybeInfof(h.Log, "graceful handoff; starting child process %s with %d listener(s)", executable, len(files))
+	err = cmd.Start()
+	_ = readyWriter.Close()
+	if err != nil {
+		return nil, ex.New(err)
+	}
+
+	ready := make(chan error, 1)
+	go func() {
+		buffer := make([]byte, 1)
+		if _, readErr := io.ReadFull(readyReader, buffer); readErr != nil {
+			ready <- ex.New(ErrHandoffChildNotReady, ex.OptInner(readErr))
+			return
+		}
+		ready <- nil
+	}()
+
+	readyTimeout := h.ReadyTimeout
+	if readyTimeout <= 0 {
+		readyTimeout = DefaultHandoffReadyTimeout
+	}
+	timer := time.NewTimer(readyTimeout)
+	defer timer.Stop()
+	select {
+	case err = <-ready:
+	case <-timer.C:
+		err = ex.New(ErrHandoffReadyTimeout, ex.OptMessagef("timeout: %v", readyTimeout))
+	}
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return nil, err
+	}
+
+	// the child now owns the sockets; make sure closing our copies
+	// while draining doesn't remove unix socket files out from under it.
+	for _, listener := range listeners {
+		if typed, ok := listener.(*net.UnixListener); ok {
+			typed.SetUnlinkOnClose(false)
+		}
+	}
+	MaybeInfof(h.Log, "graceful handoff; child process %d ready", cmd.Process.Pid)
+	return cmd.Process, nil
+}
+
+// listenerFiles returns the keys and duplicated files of the tracked listeners that can be passed
+// to a child process; listeners that have been closed are no longer tracked.
+func (h *Handoff) listenerFiles() (keys []string, files []*os.File, listeners []net.Listener) {
+	h.Lock()
+	defer h.Unlock()
+
+	open := h.listeners[:0]
+	for _, hl := range h.listeners {
+		filer, ok := hl.listener.(interface{ File() (*os.File, error) })
+		if !ok {
+			MaybeErrorf(h.Log, "graceful handoff; listener %s cannot be passed to a child process, skipping", hl.key)
+			open = append(open, hl)
+			continue
+		}
+		file, err := filer.File()
+		if err != nil {
+			// the listener has been closed without being released.
+			MaybeDebugf(h.Log, "graceful handoff; releasing listener %s: %v", hl.key, err)
+			continue
+		}
+		open = append(open, hl)
+		keys = append(keys, hl.key)
+		files = append(files, file)
+		listeners = append(listeners, hl.listener)
+	}
+	h.listeners = open
+	return
+}
+
+type handoffListener struct {
+	key      string
+	listener net.Listener
+}
+
+func handoffKey(network, addr string) string {
+	return network + ":" + addr
+}
+
+// handoffEnviron returns the current environment without any handoff variables.
+func handoffEnviron() (output []string) {
+	for _, value := range os.Environ() {
+		if strings.HasPrefix(value, EnvVarHandoffListeners+"=") || strings.HasPrefix(value, EnvVarHandoffReadyFD+"=") {
+			continue
+		}
+		output = append(output, value)
+	}
+	return
+}
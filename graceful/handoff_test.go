@@ -0,0 +1,255 @@
+//go:build linux
+// +build linux
+
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package graceful
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/ex"
+)
+
+const envVarHandoffTestHelper = "GRACEFUL_HANDOFF_TEST_HELPER"
+
+// TestHandoffHelperProcess is not a real test; it is the child process started by the handoff tests.
+func TestHandoffHelperProcess(t *testing.T) {
+	mode := os.Getenv(envVarHandoffTestHelper)
+	if mode == "" {
+		return
+	}
+	h := NewHandoff()
+	if !h.IsChild() {
+		fmt.Fprintln(os.Stderr, "helper process did not inherit a handoff")
+		os.Exit(1)
+	}
+	if mode == "fail" {
+		os.Exit(1)
+	}
+	listener, err := h.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	served := make(chan struct{})
+	go func() {
+		_ = http.Serve(listener, http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			rw.Header().Set("Connection", "close")
+			fmt.Fprint(rw, "child")
+			close(served)
+		}))
+	}()
+	if err := h.Ready(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	select {
+	case <-served:
+		time.Sleep(50 * time.Millisecond)
+	case <-time.After(10 * time.Second):
+	}
+	os.Exit(0)
+}
+
+func TestHandoffUpgrade(t *testing.T) {
+	its := assert.New(t)
+
+	h := NewHandoff(
+		OptHandoffCommand(os.Args[0], []string{"-test.run=^TestHandoffHelperProcess$"}, envVarHandoffTestHelper+"=serve"),
+		OptHandoffReadyTimeout(10*time.Second),
+	)
+	its.False(h.IsChild())
+	its.Nil(h.Ready(), "ready should be a no-op without a parent")
+
+	listener, err := h.Listen("tcp", "127.0.0.1:0")
+	its.Nil(err)
+	addr := listener.Addr().String()
+
+	server := &http.Server{Handler: http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(rw, "parent")
+	})}
+	go func() { _ = server.Serve(listener) }()
+	its.Equal("parent", handoffTestGet(its, addr))
+
+	child, err := h.Upgrade()
+	its.Nil(err)
+	its.NotNil(child)
+
+	// drain the parent; the socket stays open because the child holds a copy of it.
+	server.SetKeepAlivesEnabled(false)
+	its.Nil(server.Close())
+
+	its.Equal("child", handoffTestGet(its, addr))
+	state, err := child.Wait()
+	its.Nil(err)
+	its.True(state.Success())
+}
+
+func TestHandoffUpgradeChildFails(t *testing.T) {
+	its := assert.New(t)
+
+	h := NewHandoff(
+		OptHandoffCommand(os.Args[0], []string{"-test.run=^TestHandoffHelperProcess$"}, envVarHandoffTestHelper+"=fail"),
+		OptHandoffReadyTimeout(10*time.Second),
+	)
+	listener, err := h.Listen("tcp", "127.0.0.1:0")
+	its.Nil(err)
+	defer listener.Close()
+
+	child, err := h.Upgrade()
+	its.Nil(child)
+	its.NotNil(err)
+	its.Equal(ErrHandoffChildNotReady, ex.ErrClass(err))
+}
+
+func TestHandoffRelease(t *testing.T) {
+	its := assert.New(t)
+
+	h := NewHandoff()
+	first, err := h.Listen("tcp", "127.0.0.1:0")
+	its.Nil(err)
+	second, err := h.Listen("tcp", "127.0.0.1:0")
+	its.Nil(err)
+	third, err := h.Listen("tcp", "127.0.0.1:0")
+	its.Nil(err)
+	defer third.Close()
+	its.Len(h.listeners, 3)
+
+	its.Nil(first.Close())
+	h.Release(first)
+	its.Len(h.listeners, 2)
+
+	// listeners closed without being released are released when an upgrade collects them
+	its.Nil(second.Close())
+	keys, files, _ := h.listenerFiles()
+	for _, file := range files {
+		_ = file.Close()
+	}
+	its.Equal([]string{handoffKey("tcp", "127.0.0.1:0")}, keys)
+	its.Len(h.listeners, 1)
+	its.Equal(third, h.listeners[0].listener)
+}
+
+func TestShutdownBySignalRestartSignalWithoutHandoff(t *testing.T) {
+	its := assert.New(t)
+
+	hosted := newHosted()
+	restartSignal := make(chan os.Signal, 1)
+	shutdownSignal := make(chan os.Signal)
+	done := make(chan struct{})
+	var err error
+	go func() {
+		err = ShutdownBySignal([]Graceful{hosted},
+			OptShutdownSignal(shutdownSignal),
+			OptRestartSignal(restartSignal),
+		)
+		close(done)
+	}()
+	<-hosted.NotifyStarted()
+
+	// without a handoff the restart signal is ignored
+	restartSignal <- os.Interrupt
+	select {
+	case <-done:
+		its.FailNow("a restart signal without a handoff should not shut down the hosted processes")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(shutdownSignal)
+	<-done
+	its.Nil(err)
+}
+
+func handoffTestGet(its *assert.Assertions, addr string) string {
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}, Timeout: 5 * time.Second}
+	res, err := client.Get("http://" + addr)
+	its.Nil(err)
+	if err != nil {
+		return ""
+	}
+	defer res.Body.Close()
+	contents, err := io.ReadAll(res.Body)
+	its.Nil(err)
+	return string(contents)
+}
+
+func TestShutdownBySignalRestart(t *testing.T) {
+	its := assert.New(t)
+
+	failing := NewHandoff(
+		OptHandoffCommand(os.Args[0], []string{"-test.run=^TestHandoffHelperProcess$"}, envVarHandoffTestHelper+"=fail"),
+	)
+	_, err := failing.Listen("tcp", "127.0.0.1:0")
+	its.Nil(err)
+
+	hosted := newHosted()
+	restartSignal := make(chan os.Signal)
+	shutdownSignal := make(chan os.Signal)
+	done := make(chan struct{})
+	go func() {
+		err = ShutdownBySignal([]Graceful{hosted},
+			OptShutdownSignal(shutdownSignal),
+			OptRestartSignal(restartSignal),
+			OptHandoff(failing),
+		)
+		close(done)
+	}()
+	<-hosted.NotifyStarted()
+
+	restartSignal <- os.Interrupt
+	select {
+	case <-done:
+		its.FailNow("a failed restart should not shut down the hosted processes")
+	case <-time.After(100 * time.Millisecond):
+	}
+	its.Equal(1, hosted.state)
+
+	close(shutdownSignal)
+	<-done
+	its.Nil(err)
+	its.Equal(0, hosted.state)
+}
+
+func TestShutdownBySignalRestartHandoff(t *testing.T) {
+	its := assert.New(t)
+
+	h := NewHandoff(
+		OptHandoffCommand(os.Args[0], []string{"-test.run=^TestHandoffHelperProcess$"}, envVarHandoffTestHelper+"=serve"),
+		OptHandoffReadyTimeout(10*time.Second),
+	)
+	listener, err := h.Listen("tcp", "127.0.0.1:0")
+	its.Nil(err)
+	addr := listener.Addr().String()
+
+	hosted := newHosted()
+	restartSignal := make(chan os.Signal)
+	done := make(chan struct{})
+	go func() {
+		err = ShutdownBySignal([]Graceful{hosted},
+			OptRestartSignal(restartSignal),
+			OptHandoff(h),
+		)
+		close(done)
+	}()
+	<-hosted.NotifyStarted()
+
+	restartSignal <- os.Interrupt
+	<-done
+	its.Nil(err)
+	its.Equal(0, hosted.state)
+
+	its.Nil(listener.Close())
+	its.Equal("child", handoffTestGet(its, addr))
+}
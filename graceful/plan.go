@@ -0,0 +1,226 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package graceful
+
+import (
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+// ErrPhaseTimeout is returned when a shutdown phase does not complete within its timeout.
+const ErrPhaseTimeout ex.Class = "graceful; shutdown phase timed out"
+
+// NewPlan returns a new shutdown plan.
+func NewPlan(opts ...PlanOption) *Plan {
+	var plan Plan
+	for _, opt := range opts {
+		opt(&plan)
+	}
+	return &plan
+}
+
+// PlanOption mutates a plan.
+type PlanOption func(*Plan)
+
+// OptPlanPhase adds a phase to the plan; phases are stopped in the order they are added.
+//
+// A timeout of zero waits for the phase indefinitely.
+func OptPlanPhase(name string, timeout time.Duration, hosted ...Graceful) PlanOption {
+	return func(p *Plan) {
+		p.Phases = append(p.Phases, Phase{Name: name, Timeout: timeout, Hosted: hosted})
+	}
+}
+
+// OptPlanReadiness sets the readiness flag the plan sets on start and clears before draining.
+func OptPlanReadiness(readiness *Readiness) PlanOption {
+	return func(p *Plan) { p.Readiness = readiness }
+}
+
+// OptPlanReadinessDelay sets how long to wait after clearing readiness before the first phase is stopped.
+func OptPlanReadinessDelay(d time.Duration) PlanOption {
+	return func(p *Plan) { p.ReadinessDelay = d }
+}
+
+// OptPlanLog sets the plan logger.
+func OptPlanLog(log Logger) PlanOption {
+	return func(p *Plan) { p.Log = log }
+}
+
+// Phase is a named set of hosted processes that are stopped together.
+type Phase struct {
+	Name    string
+	Timeout time.Duration
+	Hosted  []Graceful
+}
+
+// Plan is an ordered shutdown of hosted processes.
+//
+// Phases are started in reverse order, waiting for each phase's processes to start
+// if they can notify when they've started, and are stopped in order, waiting for
+// each phase to stop (or time out) before the next one is stopped. For example:
+//
+//	plan := graceful.NewPlan(
+//		graceful.OptPlanReadiness(readiness),
+//		graceful.OptPlanPhase("http", 30*time.Second, app),
+//		graceful.OptPlanPhase("queues", 10*time.Second, queue),
+//		graceful.OptPlanPhase("cron", 10*time.Second, jobs),
+//		graceful.OptPlanPhase("db", 5*time.Second, dbCloser),
+//	)
+//	if err := graceful.ShutdownPlan(plan); err != nil {
+//		logger.FatalExit(err)
+//	}
+type Plan struct {
+	Phases         []Phase
+	Readiness      *Readiness
+	ReadinessDelay time.Duration
+	Log            Logger
+}
+
+// ShutdownPlan starts the processes in a plan and stops them in order based on SIGINT or SIGTERM received from the os.
+func ShutdownPlan(plan *Plan) error {
+	return ShutdownPlanBySignal(plan, OptDefaultShutdownSignal())
+}
+
+// ShutdownPlanBySignal starts the processes in a plan and stops them in order based on a set of variadic options.
+//
+// If any hosted process exits on its own, the remaining processes are stopped in plan order.
+// Errors from starting, stopping and phase timeouts are combined in the returned error.
+func ShutdownPlanBySignal(plan *Plan, opts ...ShutdownOption) error {
+	var options ShutdownOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	restartSignal := options.restartSignal()
+
+	serverExited := make(chan struct{})
+	errors := make(chan error, 2*plan.hostedCount()+len(plan.Phases))
+	exited := make([][]chan struct{}, len(plan.Phases))
+
+	// start the phases in reverse order, so dependencies start first.
+	for index := len(plan.Phases) - 1; index >= 0; index-- {
+		phase := plan.Phases[index]
+		MaybeDebugf(plan.Log, "graceful plan; starting phase %s", phase.Name)
+		for _, hostedInstance := range phase.Hosted {
+			instanceExited := make(chan struct{})
+			exited[index] = append(exited[index], instanceExited)
+			go func(instance Graceful) {
+				defer func() {
+					close(instanceExited)
+					_ = safely(func() { close(serverExited) })
+				}()
+				if err := instance.Start(); err != nil {
+					errors <- err
+				}
+			}(hostedInstance)
+		}
+		waitStarted(phase.Hosted, serverExited)
+	}
+
+	MaybeInfof(plan.Log, "graceful plan; all phases started")
+	if plan.Readiness != nil {
+		plan.Readiness.SetReady(true)
+	}
+	if options.Handoff != nil {
+		if err := options.Handoff.Ready(); err != nil {
+			MaybeErrorf(options.Handoff.Log, "graceful handoff; error signaling ready: %v", err)
+		}
+	}
+
+	for {
+		select {
+		case <-options.ShutdownSignal:
+			signal.Stop(options.ShutdownSignal)
+			MaybeInfof(plan.Log, "graceful plan; shutdown signal received")
+		case <-restartSignal:
+			if _, err := options.Handoff.Upgrade(); err != nil {
+				MaybeErrorf(options.Handoff.Log, "graceful handoff; restart failed, continuing to serve: %v", err)
+				continue
+			}
+			signal.Stop(restartSignal)
+			if options.ShutdownSignal != nil {
+				signal.Stop(options.ShutdownSignal)
+			}
+			MaybeInfof(plan.Log, "graceful plan; handed off to new process")
+		case <-serverExited:
+			MaybeInfof(plan.Log, "graceful plan; a hosted process exited")
+		}
+		break
+	}
+
+	plan.stop(exited, errors)
+
+	var err error
+	for errorCount := len(errors); errorCount > 0; errorCount-- {
+		err = ex.Append(err, <-errors)
+	}
+	return err
+}
+
+// stop clears readiness and stops each phase in order.
+func (p *Plan) stop(exited [][]chan struct{}, errors chan error) {
+	if p.Readiness != nil {
+		MaybeInfof(p.Log, "graceful plan; marking process not ready")
+		p.Readiness.SetReady(false)
+		if p.ReadinessDelay > 0 {
+			MaybeInfof(p.Log, "graceful plan; waiting %v for traffic to drain", p.ReadinessDelay)
+			time.Sleep(p.ReadinessDelay)
+		}
+	}
+
+	for index, phase := range p.Phases {
+		MaybeInfof(p.Log, "graceful plan; stopping phase %s", phase.Name)
+		started := time.Now()
+
+		phaseComplete := make(chan struct{})
+		wg := sync.WaitGroup{}
+		wg.Add(len(phase.Hosted))
+		for instanceIndex, hostedInstance := range phase.Hosted {
+			go func(instance Graceful, instanceExited chan struct{}) {
+				defer wg.Done()
+				select {
+				case <-instanceExited: // the instance already exited on its own
+					return
+				default:
+				}
+				if err := instance.Stop(); err != nil {
+					errors <- err
+				}
+				<-instanceExited
+			}(hostedInstance, exited[index][instanceIndex])
+		}
+		go func() {
+			wg.Wait()
+			close(phaseComplete)
+		}()
+
+		if phase.Timeout > 0 {
+			timer := time.NewTimer(phase.Timeout)
+			select {
+			case <-phaseComplete:
+				MaybeInfof(p.Log, "graceful plan; phase %s stopped (%v)", phase.Name, time.Since(started))
+			case <-timer.C:
+				MaybeErrorf(p.Log, "graceful plan; phase %s did not stop within %v, continuing", phase.Name, phase.Timeout)
+				errors <- ex.New(ErrPhaseTimeout, ex.OptMessagef("phase: %s, timeout: %v", phase.Name, phase.Timeout))
+			}
+			timer.Stop()
+		} else {
+			<-phaseComplete
+			MaybeInfof(p.Log, "graceful plan; phase %s stopped (%v)", phase.Name, time.Since(started))
+		}
+	}
+}
+
+func (p *Plan) hostedCount() (count int) {
+	for _, phase := range p.Phases {
+		count += len(phase.Hosted)
+	}
+	return
+}
@@ -0,0 +1,129 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package graceful
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/ex"
+)
+
+type orderedHosted struct {
+	*hosted
+	name      string
+	mu        *sync.Mutex
+	order     *[]string
+	stopDelay time.Duration
+}
+
+func (oh orderedHosted) Start() error {
+	oh.mu.Lock()
+	*oh.order = append(*oh.order, "start "+oh.name)
+	oh.mu.Unlock()
+	return oh.hosted.Start()
+}
+
+func (oh orderedHosted) Stop() error {
+	oh.mu.Lock()
+	*oh.order = append(*oh.order, "stop "+oh.name)
+	oh.mu.Unlock()
+	if oh.stopDelay > 0 {
+		time.Sleep(oh.stopDelay)
+	}
+	return oh.hosted.Stop()
+}
+
+func TestShutdownPlanBySignal(t *testing.T) {
+	its := assert.New(t)
+
+	var mu sync.Mutex
+	var order []string
+	newOrdered := func(name string) orderedHosted {
+		return orderedHosted{hosted: newHosted(), name: name, mu: &mu, order: &order}
+	}
+	web, cron, db := newOrdered("web"), newOrdered("cron"), newOrdered("db")
+	readiness := NewReadiness()
+
+	plan := NewPlan(
+		OptPlanReadiness(readiness),
+		OptPlanPhase("http", time.Second, web),
+		OptPlanPhase("cron", time.Second, cron),
+		OptPlanPhase("db", time.Second, db),
+	)
+
+	shutdownSignal := make(chan os.Signal)
+	var err error
+	done := make(chan struct{})
+	go func() {
+		err = ShutdownPlanBySignal(plan, OptShutdownSignal(shutdownSignal))
+		close(done)
+	}()
+	<-web.NotifyStarted()
+	// readiness is set after the last phase has started.
+	for !readiness.IsReady() {
+		time.Sleep(time.Millisecond)
+	}
+
+	close(shutdownSignal)
+	<-done
+	its.Nil(err)
+	its.False(readiness.IsReady())
+	its.Equal([]string{
+		"start db", "start cron", "start web",
+		"stop web", "stop cron", "stop db",
+	}, order)
+}
+
+func TestShutdownPlanBySignalPhaseTimeout(t *testing.T) {
+	its := assert.New(t)
+
+	var mu sync.Mutex
+	var order []string
+	slow := orderedHosted{hosted: newHosted(), name: "slow", mu: &mu, order: &order, stopDelay: 100 * time.Millisecond}
+	db := orderedHosted{hosted: newHosted(), name: "db", mu: &mu, order: &order}
+
+	plan := NewPlan(
+		OptPlanPhase("slow", 10*time.Millisecond, slow),
+		OptPlanPhase("db", time.Second, db),
+	)
+
+	shutdownSignal := make(chan os.Signal)
+	var err error
+	done := make(chan struct{})
+	go func() {
+		err = ShutdownPlanBySignal(plan, OptShutdownSignal(shutdownSignal))
+		close(done)
+	}()
+	<-slow.NotifyStarted()
+
+	close(shutdownSignal)
+	<-done
+	its.NotNil(err)
+	its.True(ex.Is(err, ErrPhaseTimeout))
+
+	mu.Lock()
+	its.Equal([]string{"start db", "start slow", "stop slow", "stop db"}, order)
+	mu.Unlock()
+}
+
+func TestReadiness(t *testing.T) {
+	its := assert.New(t)
+
+	readiness := NewReadiness()
+	its.False(readiness.IsReady())
+	its.True(ex.Is(readiness.Check(context.Background()), ErrNotReady))
+
+	readiness.SetReady(true)
+	its.True(readiness.IsReady())
+	its.Nil(readiness.Check(context.Background()))
+}
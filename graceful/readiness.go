@@ -0,0 +1,54 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package graceful
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+// ErrNotReady is returned by a readiness check when the process is not ready for traffic.
+const ErrNotReady ex.Class = "graceful; process is not ready"
+
+// NewReadiness returns a new readiness flag; it starts as not ready.
+func NewReadiness() *Readiness {
+	return new(Readiness)
+}
+
+// Readiness reports if the process should receive traffic.
+//
+// A shutdown plan sets it once the hosted processes have started, and clears it
+// before any draining starts so load balancers can stop routing to the process.
+// Its `Check` method reports `ErrNotReady` while not ready, so it can be used as a `status` check.
+type Readiness struct {
+	ready int32
+}
+
+// SetReady sets if the process is ready.
+func (r *Readiness) SetReady(ready bool) {
+	if ready {
+		atomic.StoreInt32(&r.ready, 1)
+	} else {
+		atomic.StoreInt32(&r.ready, 0)
+	}
+}
+
+// IsReady returns if the process is ready.
+func (r *Readiness) IsReady() bool {
+	return atomic.LoadInt32(&r.ready) == 1
+}
+
+// Check implements async.Checker, returning `ErrNotReady` if the process is not ready.
+func (r *Readiness) Check(_ context.Context) error {
+	if !r.IsReady() {
+		return ex.New(ErrNotReady)
+	}
+	return nil
+}
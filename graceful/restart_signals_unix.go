@@ -0,0 +1,23 @@
+//go:build !windows
+// +build !windows
+
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package graceful
+
+import (
+	"os"
+	"syscall"
+)
+
+// DefaultRestartSignals are the default os signals to capture to restart with a handoff.
+//
+// SIGHUP is left alone, as it is commonly used to reopen log files or reload config.
+var DefaultRestartSignals = []os.Signal{
+	syscall.SIGUSR2,
+}
@@ -0,0 +1,20 @@
+//go:build windows
+// +build windows
+
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package graceful
+
+import (
+	"os"
+)
+
+// DefaultRestartSignals are the default os signals to capture to restart with a handoff.
+//
+// It is empty, as handoffs are not supported on windows; child processes cannot inherit listeners.
+var DefaultRestartSignals = []os.Signal{}
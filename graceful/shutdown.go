@@ -15,3 +15,16 @@ func Shutdown(hosted ...Graceful) error {
 		OptDefaultShutdownSignal(),
 	)
 }
+
+// ShutdownWithHandoff gracefully stops a set of hosted processes based on SIGINT or SIGTERM received from the os,
+// and restarts the process without dropping connections on the `DefaultRestartSignals` (SIGUSR2).
+//
+// On restart, the listeners created with the handoff's `Listen` are passed to a new instance of the process;
+// once it calls `Ready` (which happens automatically when its hosted processes have started) the current
+// process drains and returns as if it had received a shutdown signal.
+func ShutdownWithHandoff(handoff *Handoff, hosted ...Graceful) error {
+	return ShutdownBySignal(hosted,
+		OptDefaultShutdownSignal(),
+		OptHandoff(handoff),
+	)
+}
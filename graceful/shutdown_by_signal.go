@@ -23,6 +23,7 @@ func ShutdownBySignal(hosted []Graceful, opts ...ShutdownOption) error {
 	for _, opt := range opts {
 		opt(&options)
 	}
+	restartSignal := options.restartSignal()
 
 	shouldShutdown := make(chan struct{})
 	serverExited := make(chan struct{})
@@ -57,23 +58,61 @@ func ShutdownBySignal(hosted []Graceful, opts ...ShutdownOption) error {
 		}(hostedInstance)
 	}
 
-	select {
-	case <-options.ShutdownSignal: // if we've issued a shutdown, wait for the server to exit
-		signal.Stop(options.ShutdownSignal) // unhook the process signal redirects, the next ^c will crash the process etc.
-		close(shouldShutdown)
-		waitShutdownComplete.Wait()
-		waitServerExited.Wait()
+	if options.Handoff != nil {
+		go func() {
+			waitStarted(hosted, serverExited)
+			if err := options.Handoff.Ready(); err != nil {
+				MaybeErrorf(options.Handoff.Log, "graceful handoff; error signaling ready: %v", err)
+			}
+		}()
+	}
 
-	case <-serverExited: // if any of the servers exited on their own
-		close(shouldShutdown) // quit the signal listener
-		waitShutdownComplete.Wait()
+	for {
+		select {
+		case <-options.ShutdownSignal: // if we've issued a shutdown, wait for the server to exit
+			signal.Stop(options.ShutdownSignal) // unhook the process signal redirects, the next ^c will crash the process etc.
+			close(shouldShutdown)
+			waitShutdownComplete.Wait()
+			waitServerExited.Wait()
+		case <-restartSignal: // if we've been asked to restart, hand off to a new process and then drain
+			if _, err := options.Handoff.Upgrade(); err != nil {
+				MaybeErrorf(options.Handoff.Log, "graceful handoff; restart failed, continuing to serve: %v", err)
+				continue
+			}
+			signal.Stop(restartSignal)
+			if options.ShutdownSignal != nil {
+				signal.Stop(options.ShutdownSignal)
+			}
+			close(shouldShutdown)
+			waitShutdownComplete.Wait()
+			waitServerExited.Wait()
+		case <-serverExited: // if any of the servers exited on their own
+			close(shouldShutdown) // quit the signal listener
+			waitShutdownComplete.Wait()
+		}
+		break
 	}
+
 	if len(errors) > 0 {
 		return <-errors
 	}
 	return nil
 }
 
+// waitStarted waits for any hosted processes that can notify when they've started,
+// or for any of them to exit.
+func waitStarted(hosted []Graceful, serverExited <-chan struct{}) {
+	for _, instance := range hosted {
+		if typed, ok := instance.(interface{ NotifyStarted() <-chan struct{} }); ok {
+			select {
+			case <-typed.NotifyStarted():
+			case <-serverExited:
+				return
+			}
+		}
+	}
+}
+
 func safely(action func()) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -9,6 +9,7 @@ package graceful
 
 import (
 	"os"
+	"os/signal"
 )
 
 // OptDefaultShutdownSignal returns an option that sets the shutdown signal to the defaults.
@@ -21,10 +22,42 @@ func OptShutdownSignal(signal chan os.Signal) ShutdownOption {
 	return func(so *ShutdownOptions) { so.ShutdownSignal = signal }
 }
 
+// OptRestartSignal sets the restart signal.
+//
+// A restart signal has no effect unless a handoff is also set with `OptHandoff`.
+func OptRestartSignal(signal chan os.Signal) ShutdownOption {
+	return func(so *ShutdownOptions) { so.RestartSignal = signal }
+}
+
+// OptHandoff sets the handoff used to pass listeners to a new process on restart.
+//
+// The handoff is also told the process is ready once the hosted processes have started.
+// If no restart signal is set with `OptRestartSignal`, the `DefaultRestartSignals` are used.
+func OptHandoff(handoff *Handoff) ShutdownOption {
+	return func(so *ShutdownOptions) { so.Handoff = handoff }
+}
+
 // ShutdownOption is a mutator for shutdown options.
 type ShutdownOption func(*ShutdownOptions)
 
 // ShutdownOptions are the options for graceful shutdown.
 type ShutdownOptions struct {
 	ShutdownSignal chan os.Signal
+	RestartSignal  chan os.Signal
+	Handoff        *Handoff
+}
+
+// restartSignal returns the restart signal to select on; it is nil, and any
+// signals relayed to it are stopped, if there is no handoff to restart with.
+func (so ShutdownOptions) restartSignal() chan os.Signal {
+	if so.Handoff == nil {
+		if so.RestartSignal != nil {
+			signal.Stop(so.RestartSignal)
+		}
+		return nil
+	}
+	if so.RestartSignal == nil && len(DefaultRestartSignals) > 0 {
+		return Notify(DefaultRestartSignals...)
+	}
+	return so.RestartSignal
 }
@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/graceful"
 )
 
 // CreateListener creates a net listener for a given bind address.
@@ -29,3 +30,15 @@ func CreateListener(bindAddr string) (net.Listener, error) {
 	}
 	return socketListener, ex.New(err)
 }
+
+// CreateHandoffListener creates a net listener for a given bind address with a handoff, so
+// the listener is passed to a new process on a graceful restart, or inherited from the previous one.
+// It handles detecting if we should create a unix socket address.
+//
+// Call `handoff.Release` with the listener once the server using it has stopped.
+func CreateHandoffListener(handoff *graceful.Handoff, bindAddr string) (net.Listener, error) {
+	if strings.HasPrefix(bindAddr, "unix://") {
+		return handoff.Listen("unix", strings.TrimPrefix(bindAddr, "unix://"))
+	}
+	return handoff.Listen("tcp", bindAddr)
+}
@@ -14,6 +14,7 @@ import (
 	"testing"
 
 	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/graceful"
 	"github.com/blend/go-sdk/uuid"
 )
 
@@ -35,3 +36,20 @@ func TestListener(t *testing.T) {
 	assert.Equal("unix", unixln.Addr().Network())
 	assert.Equal(socketPath, unixln.Addr().String())
 }
+
+func TestCreateHandoffListener(t *testing.T) {
+	assert := assert.New(t)
+
+	handoff := graceful.NewHandoff()
+	tcpln, err := CreateHandoffListener(handoff, "127.0.0.1:")
+	assert.Nil(err)
+	defer func() { _ = tcpln.Close() }()
+	assert.Equal("tcp", tcpln.Addr().Network())
+
+	socketPath := filepath.Join(os.TempDir(), uuid.V4().String())
+	unixln, err := CreateHandoffListener(handoff, "unix://"+socketPath)
+	assert.Nil(err)
+	defer func() { _ = unixln.Close() }()
+	assert.Equal("unix", unixln.Addr().Network())
+	assert.Equal(socketPath, unixln.Addr().String())
+}
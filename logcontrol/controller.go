@@ -0,0 +1,272 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package logcontrol
+
+import (
+	"strings"
+	"time"
+
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/logger"
+	"github.com/blend/go-sdk/web"
+	"github.com/blend/go-sdk/webutil"
+)
+
+// Controller defaults.
+const (
+	DefaultPath      = "/logger"
+	DefaultPrincipal = "anonymous"
+	// AuditNoun is the noun of the audit events the controller triggers.
+	AuditNoun = "logger.override"
+	// AuditVerbApply is the verb of the audit event triggered when an override is applied.
+	AuditVerbApply = "apply"
+	// AuditVerbRevert is the verb of the audit event triggered when an override is reverted.
+	AuditVerbRevert = "revert"
+)
+
+// Errors
+const (
+	ErrInvalidDuration    ex.Class = "logcontrol; invalid override duration"
+	ErrDurationExceedsMax ex.Class = "logcontrol; override duration exceeds the max duration"
+	ErrUntilRequired      ex.Class = "logcontrol; override duration or until is required"
+	ErrOverrideEmpty      ex.Class = "logcontrol; override has no changes"
+)
+
+// NewController returns a new controller for a given logger.
+func NewController(log *logger.Logger, opts ...ControllerOption) *Controller {
+	controller := Controller{
+		Logger:   log,
+		AuditLog: log,
+		Path:     DefaultPath,
+	}
+	for _, opt := range opts {
+		opt(&controller)
+	}
+	return &controller
+}
+
+// ControllerOption mutates a controller.
+type ControllerOption func(*Controller)
+
+// OptPath sets the path prefix of the routes.
+func OptPath(path string) ControllerOption {
+	return func(c *Controller) { c.Path = path }
+}
+
+// OptAuditLog sets the logger audit events are triggered on; it defaults to the controlled logger.
+func OptAuditLog(log logger.Triggerable) ControllerOption {
+	return func(c *Controller) { c.AuditLog = log }
+}
+
+// OptMaxDuration sets the maximum duration of an override.
+//
+// If set, overrides must have a duration or deadline, and it cannot be further out than the max duration.
+func OptMaxDuration(maxDuration time.Duration) ControllerOption {
+	return func(c *Controller) { c.MaxDuration = maxDuration }
+}
+
+// OptAuth sets the middleware that authorizes requests to the routes, e.g. `web.SessionRequired`.
+//
+// Either `OptAuth` or `OptNoAuth` is required; otherwise the routes return `403 Forbidden`.
+func OptAuth(auth ...web.Middleware) ControllerOption {
+	return func(c *Controller) { c.Auth = append(c.Auth, auth...) }
+}
+
+// OptNoAuth explicitly allows unauthenticated requests to the routes,
+// e.g. if the app is only reachable from a trusted network.
+func OptNoAuth() ControllerOption {
+	return func(c *Controller) { c.NoAuth = true }
+}
+
+// OptMiddleware adds middleware for the routes; it is applied after the `OptAuth` middleware.
+//
+// Middleware must be set _before_ you register the controller.
+func OptMiddleware(middleware ...web.Middleware) ControllerOption {
+	return func(c *Controller) { c.Middleware = append(c.Middleware, middleware...) }
+}
+
+// OptNowProvider sets the now provider used to compute override deadlines.
+func OptNowProvider(nowProvider func() time.Time) ControllerOption {
+	return func(c *Controller) { c.NowProvider = nowProvider }
+}
+
+// Controller exposes routes to change the flags and scopes of a logger at runtime.
+type Controller struct {
+	// Logger is the logger that is changed.
+	Logger *logger.Logger
+	// AuditLog is the logger audit events are triggered on.
+	AuditLog logger.Triggerable
+	// Path is the path prefix of the routes.
+	Path string
+	// MaxDuration is the optional maximum duration of an override.
+	MaxDuration time.Duration
+	// Auth is the middleware that authorizes requests to the routes.
+	Auth []web.Middleware
+	// NoAuth explicitly allows unauthenticated requests if `Auth` is unset.
+	NoAuth bool
+	// Middleware is applied to the routes after `Auth`.
+	Middleware []web.Middleware
+	// NowProvider optionally returns the current time.
+	NowProvider func() time.Time
+}
+
+// OverrideRequest is the body of a request to apply an override.
+type OverrideRequest struct {
+	logger.Override
+	// Duration is an optional duration after which the override is reverted, e.g. `10m`.
+	//
+	// It takes precedence over `until`.
+	Duration string `json:"duration,omitempty"`
+}
+
+// State is the current flags and scopes of a logger.
+type State struct {
+	Flags          []string         `json:"flags"`
+	Writable       []string         `json:"writable"`
+	Scopes         []string         `json:"scopes"`
+	WritableScopes []string         `json:"writableScopes"`
+	Override       *logger.Override `json:"override,omitempty"`
+}
+
+// Register adds the controller's routes to the app.
+//
+// If neither `Auth` nor `NoAuth` is set, the routes return `403 Forbidden`.
+func (c Controller) Register(app *web.App) {
+	path := strings.TrimSuffix(c.Path, "/")
+	middleware := c.middleware()
+	app.GET(path, c.getState, middleware...)
+	app.PUT(path+"/override", c.putOverride, middleware...)
+	app.DELETE(path+"/override", c.deleteOverride, middleware...)
+}
+
+// middleware returns the auth middleware followed by the other middleware,
+// or middleware that forbids all requests if auth is not configured.
+func (c Controller) middleware() []web.Middleware {
+	if len(c.Auth) == 0 && !c.NoAuth {
+		return []web.Middleware{forbidden}
+	}
+	return append(append([]web.Middleware{}, c.Auth...), c.Middleware...)
+}
+
+func forbidden(_ web.Action) web.Action {
+	return func(r *web.Ctx) web.Result {
+		return web.JSON.Forbidden()
+	}
+}
+
+// GET /logger
+func (c Controller) getState(r *web.Ctx) web.Result {
+	return web.JSON.Result(c.state())
+}
+
+// PUT /logger/override
+func (c Controller) putOverride(r *web.Ctx) web.Result {
+	var req OverrideRequest
+	if err := r.PostBodyAsJSON(&req); err != nil {
+		return web.JSON.BadRequest(err)
+	}
+	override, err := c.override(req)
+	if err != nil {
+		return web.JSON.BadRequest(err)
+	}
+	// audit before applying the override, so an override that disables
+	// the `audit` flag cannot suppress its own audit event.
+	c.audit(r, AuditVerbApply, override)
+	c.Logger.ApplyOverride(override)
+	return web.JSON.Result(c.state())
+}
+
+// DELETE /logger/override
+func (c Controller) deleteOverride(r *web.Ctx) web.Result {
+	override, ok := c.Logger.ActiveOverride()
+	if !ok {
+		return web.JSON.NotFound()
+	}
+	// audit after reverting the override, for the same reason.
+	c.Logger.RevertOverride()
+	c.audit(r, AuditVerbRevert, override)
+	return web.JSON.Result(c.state())
+}
+
+// override validates a request and returns the override it describes.
+func (c Controller) override(req OverrideRequest) (logger.Override, error) {
+	override := req.Override
+	if len(override.Flags) == 0 && len(override.Writable) == 0 && len(override.Scopes) == 0 && len(override.WritableScopes) == 0 {
+		return override, ex.New(ErrOverrideEmpty)
+	}
+	now := c.now()
+	if req.Duration != "" {
+		duration, err := time.ParseDuration(req.Duration)
+		if err != nil || duration <= 0 {
+			return override, ex.New(ErrInvalidDuration, ex.OptMessagef("duration: %s", req.Duration))
+		}
+		override.Until = now.Add(duration)
+	}
+	if c.MaxDuration > 0 {
+		if override.Until.IsZero() {
+			return override, ex.New(ErrUntilRequired)
+		}
+		if override.Until.Sub(now) > c.MaxDuration {
+			return override, ex.New(ErrDurationExceedsMax, ex.OptMessagef("max duration: %v", c.MaxDuration))
+		}
+	}
+	return override, nil
+}
+
+func (c Controller) state() State {
+	state := State{
+		Flags:          c.Logger.Flags.Flags(),
+		Writable:       c.Logger.Writable.Flags(),
+		Scopes:         c.Logger.Scopes.Scopes(),
+		WritableScopes: c.Logger.WritableScopes.Scopes(),
+	}
+	if override, ok := c.Logger.ActiveOverride(); ok {
+		state.Override = &override
+	}
+	return state
+}
+
+// audit triggers an audit event for a change to the logger.
+func (c Controller) audit(r *web.Ctx, verb string, override logger.Override) {
+	principal := DefaultPrincipal
+	if r.Session != nil && r.Session.UserID != "" {
+		principal = r.Session.UserID
+	}
+	extra := map[string]string{}
+	if len(override.Flags) > 0 {
+		extra["flags"] = strings.Join(override.Flags, ",")
+	}
+	if len(override.Writable) > 0 {
+		extra["writable"] = strings.Join(override.Writable, ",")
+	}
+	if len(override.Scopes) > 0 {
+		extra["scopes"] = strings.Join(override.Scopes, ",")
+	}
+	if len(override.WritableScopes) > 0 {
+		extra["writableScopes"] = strings.Join(override.WritableScopes, ",")
+	}
+	if override.Scope != "" {
+		extra["scope"] = override.Scope
+	}
+	if !override.Until.IsZero() {
+		extra["until"] = override.Until.UTC().Format(time.RFC3339)
+	}
+	logger.MaybeTriggerContext(r.Context(), c.AuditLog, logger.NewAuditEvent(principal, verb,
+		logger.OptAuditNoun(AuditNoun),
+		logger.OptAuditRemoteAddress(webutil.GetRemoteAddr(r.Request)),
+		logger.OptAuditUserAgent(webutil.GetUserAgent(r.Request)),
+		logger.OptAuditExtra(extra),
+	))
+}
+
+func (c Controller) now() time.Time {
+	if c.NowProvider != nil {
+		return c.NowProvider()
+	}
+	return time.Now()
+}
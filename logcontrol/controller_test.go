@@ -0,0 +1,134 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package logcontrol
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/logger"
+	"github.com/blend/go-sdk/r2"
+	"github.com/blend/go-sdk/web"
+)
+
+func Test_Controller_override(t *testing.T) {
+	its := assert.New(t)
+
+	buffer := new(bytes.Buffer)
+	log := logger.Memory(buffer, logger.OptFlags(logger.NewFlags(logger.Info, logger.Audit)))
+	now := time.Now().UTC().Truncate(time.Second)
+	app := web.MustNew()
+	app.Register(NewController(log, OptNoAuth(), OptNowProvider(func() time.Time { return now })))
+
+	var state State
+	meta, err := web.MockMethod(app, http.MethodPut, "/logger/override",
+		r2.OptJSONBody(OverrideRequest{
+			Override: logger.Override{Flags: []string{logger.Debug}, Scope: "worker/*"},
+			Duration: "10m",
+		}),
+		r2.OptHeaderValue("User-Agent", "test-agent"),
+	).JSON(&state)
+	its.Nil(err)
+	its.Equal(http.StatusOK, meta.StatusCode)
+	its.NotNil(state.Override)
+	its.Equal("worker/*", state.Override.Scope)
+	its.Equal(now.Add(10*time.Minute), state.Override.Until.UTC())
+
+	override, ok := log.ActiveOverride()
+	its.True(ok)
+	its.Equal([]string{logger.Debug}, override.Flags)
+	its.Contains(buffer.String(), "Principal:anonymous Verb:apply Noun:logger.override")
+	its.Contains(buffer.String(), "UA:test-agent")
+	its.Contains(buffer.String(), "scope:worker/*")
+
+	meta, err = web.MockGet(app, "/logger").JSON(&state)
+	its.Nil(err)
+	its.Equal(http.StatusOK, meta.StatusCode)
+	its.NotNil(state.Override)
+
+	var reverted State
+	meta, err = web.MockMethod(app, http.MethodDelete, "/logger/override").JSON(&reverted)
+	its.Nil(err)
+	its.Equal(http.StatusOK, meta.StatusCode)
+	its.Nil(reverted.Override)
+	its.Contains(buffer.String(), "Verb:revert")
+	_, ok = log.ActiveOverride()
+	its.False(ok)
+
+	meta, err = web.MockMethod(app, http.MethodDelete, "/logger/override").Discard()
+	its.Nil(err)
+	its.Equal(http.StatusNotFound, meta.StatusCode)
+}
+
+func Test_Controller_override_invalid(t *testing.T) {
+	its := assert.New(t)
+
+	log := logger.Memory(new(bytes.Buffer))
+	app := web.MustNew()
+	app.Register(NewController(log, OptNoAuth(), OptMaxDuration(time.Hour)))
+
+	testCases := [...]OverrideRequest{
+		{},
+		{Override: logger.Override{Flags: []string{logger.Debug}}},
+		{Override: logger.Override{Flags: []string{logger.Debug}}, Duration: "bogus"},
+		{Override: logger.Override{Flags: []string{logger.Debug}}, Duration: "2h"},
+	}
+	for _, tc := range testCases {
+		meta, err := web.MockMethod(app, http.MethodPut, "/logger/override", r2.OptJSONBody(tc)).Discard()
+		its.Nil(err)
+		its.Equal(http.StatusBadRequest, meta.StatusCode, tc.Duration)
+	}
+	_, ok := log.ActiveOverride()
+	its.False(ok)
+}
+
+func Test_Controller_overrideDisablesAudit(t *testing.T) {
+	its := assert.New(t)
+
+	buffer := new(bytes.Buffer)
+	log := logger.Memory(buffer, logger.OptFlags(logger.NewFlags(logger.Info, logger.Audit)))
+	app := web.MustNew()
+	app.Register(NewController(log, OptNoAuth()))
+
+	meta, err := web.MockMethod(app, http.MethodPut, "/logger/override",
+		r2.OptJSONBody(OverrideRequest{Override: logger.Override{Flags: []string{"-" + logger.Audit}}}),
+	).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusOK, meta.StatusCode)
+	its.Contains(buffer.String(), "Verb:apply")
+
+	meta, err = web.MockMethod(app, http.MethodDelete, "/logger/override").Discard()
+	its.Nil(err)
+	its.Equal(http.StatusOK, meta.StatusCode)
+	its.Contains(buffer.String(), "Verb:revert")
+}
+
+func Test_Controller_auth(t *testing.T) {
+	its := assert.New(t)
+
+	log := logger.Memory(new(bytes.Buffer))
+	app := web.MustNew()
+	app.Register(NewController(log))
+
+	meta, err := web.MockMethod(app, http.MethodPut, "/logger/override",
+		r2.OptJSONBody(OverrideRequest{Override: logger.Override{Flags: []string{logger.Debug}}}),
+	).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusForbidden, meta.StatusCode)
+	_, ok := log.ActiveOverride()
+	its.False(ok)
+
+	app = web.MustNew()
+	app.Register(NewController(log, OptAuth(web.SessionRequired)))
+	meta, err = web.MockGet(app, "/logger").Discard()
+	its.Nil(err)
+	its.Equal(http.StatusUnauthorized, meta.StatusCode)
+}
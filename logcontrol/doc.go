@@ -0,0 +1,33 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+/*
+Package logcontrol provides a controller to change the flags and scopes of a logger at runtime.
+
+It exposes `(*logger.Logger).ApplyOverride(...)` over http, e.g. to enable `debug` for
+a single scope path for ten minutes while investigating an issue without a restart:
+
+	app.Register(logcontrol.NewController(log,
+		logcontrol.OptAuth(web.SessionRequired),
+		logcontrol.OptMaxDuration(time.Hour),
+	))
+
+	PUT /logger/override {"flags":["debug"],"scope":"worker/*","duration":"10m"}
+
+The app will have the following routes registered:
+
+	GET /logger            returns the current flags, scopes and override.
+	PUT /logger/override   applies an override, replacing any active override.
+	DELETE /logger/override reverts the active override.
+
+Changes are logged as `logger.AuditEvent`s with the principal (the session user id) that made them;
+note that the `audit` flag must be enabled on the audit logger for them to be written.
+
+The routes change how a service logs, so they require either `OptAuth(...)` with middleware that
+authorizes requests, or an explicit `OptNoAuth()`; without either, they return `403 Forbidden`.
+*/
+package logcontrol // import "github.com/blend/go-sdk/logcontrol"
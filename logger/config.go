@@ -10,6 +10,8 @@ package logger
 import (
 	"context"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/blend/go-sdk/env"
 )
@@ -33,12 +35,19 @@ type Config struct {
 	// If a scope is not writable, it is hidden from output but listeners _are_ triggered.
 	// It defaults to all scopes being writable, or `*`.
 	WritableScopes []string `json:"writableScopes,omitempty" yaml:"writableScopes,omitempty" env:"LOG_WRITABLE_SCOPES,csv"`
-	// Format is the output format, either `text` or `json`.
+	// Format is the output format, one of `text`, `json`, `logfmt`, `ecs` or `otel`.
 	Format string `json:"format,omitempty" yaml:"format,omitempty" env:"LOG_FORMAT"`
 	// Text holds text output specific options.
 	Text TextConfig `json:"text,omitempty" yaml:"text,omitempty"`
 	// JSON holds json specific options.
 	JSON JSONConfig `json:"json,omitempty" yaml:"json,omitempty"`
+	// Sample holds per flag sample rates of the form `flag=N`, where 1 in every N
+	// events for the flag are logged, e.g. `debug=10`.
+	Sample []string `json:"sample,omitempty" yaml:"sample,omitempty" env:"LOG_SAMPLE,csv"`
+	// RateLimit holds rate limiting options.
+	RateLimit RateLimitConfig `json:"rateLimit,omitempty" yaml:"rateLimit,omitempty"`
+	// File holds rotating file output options.
+	File FileConfig `json:"file,omitempty" yaml:"file,omitempty"`
 }
 
 // Resolve resolves the config.
@@ -88,14 +97,113 @@ func (c Config) FormatOrDefault() string {
 
 // Formatter returns the configured writers
 func (c Config) Formatter() WriteFormatter {
-	switch strings.ToLower(string(c.FormatOrDefault())) {
+	return c.formatter(c.FormatOrDefault(), c.Text)
+}
+
+func (c Config) formatter(format string, text TextConfig) WriteFormatter {
+	switch strings.ToLower(format) {
 	case FormatJSON:
 		return NewJSONOutputFormatter(OptJSONConfig(c.JSON))
+	case FormatECS:
+		return NewJSONOutputFormatter(OptJSONConfig(c.JSON), OptJSONSchema(JSONSchemaECS))
+	case FormatOTel:
+		return NewJSONOutputFormatter(OptJSONConfig(c.JSON), OptJSONSchema(JSONSchemaOTel))
+	case FormatLogfmt:
+		return NewLogfmtOutputFormatter(OptLogfmtConfig(text))
 	case FormatText:
-		return NewTextOutputFormatter(OptTextConfig(c.Text))
+		return NewTextOutputFormatter(OptTextConfig(text))
 	default:
-		return NewTextOutputFormatter(OptTextConfig(c.Text))
+		return NewTextOutputFormatter(OptTextConfig(text))
+	}
+}
+
+// FileConfig is the config for writing to a rotating file, in addition to the logger output.
+type FileConfig struct {
+	// Path is the file path; file output is disabled if it is unset.
+	Path string `json:"path,omitempty" yaml:"path,omitempty" env:"LOG_FILE"`
+	// Format is the file output format, one of the logger formats; it defaults to the logger format.
+	Format string `json:"format,omitempty" yaml:"format,omitempty" env:"LOG_FILE_FORMAT"`
+	// MaxSizeBytes is the size a file can grow to before it is rotated.
+	MaxSizeBytes int64 `json:"maxSizeBytes,omitempty" yaml:"maxSizeBytes,omitempty" env:"LOG_FILE_MAX_SIZE_BYTES"`
+	// MaxAge is how long after a file was started that it is rotated.
+	MaxAge time.Duration `json:"maxAge,omitempty" yaml:"maxAge,omitempty" env:"LOG_FILE_MAX_AGE"`
+	// MaxBackups is the maximum number of rotated files that are kept.
+	MaxBackups int `json:"maxBackups,omitempty" yaml:"maxBackups,omitempty" env:"LOG_FILE_MAX_BACKUPS"`
+	// Compress sets if rotated files are compressed with gzip.
+	Compress bool `json:"compress,omitempty" yaml:"compress,omitempty" env:"LOG_FILE_COMPRESS"`
+	// ReopenOnHangup sets if the file is reopened on SIGHUP, e.g. for logrotate.
+	//
+	// It is off by default, as SIGHUP may also be used to restart or reload the process.
+	ReopenOnHangup bool `json:"reopenOnHangup,omitempty" yaml:"reopenOnHangup,omitempty" env:"LOG_FILE_REOPEN_ON_HANGUP"`
+}
+
+// Writer returns a rotating file writer for the config, which reopens the file on SIGHUP if `ReopenOnHangup` is set.
+func (fc FileConfig) Writer() (*RotatingFileWriter, error) {
+	opts := []RotatingFileWriterOption{
+		OptRotatingFileMaxSize(fc.MaxSizeBytes),
+		OptRotatingFileMaxAge(fc.MaxAge),
+		OptRotatingFileMaxBackups(fc.MaxBackups),
+		OptRotatingFileCompress(fc.Compress),
+	}
+	if fc.ReopenOnHangup {
+		opts = append(opts, OptRotatingFileReopenSignals(syscall.SIGHUP))
+	}
+	return NewRotatingFileWriter(fc.Path, opts...)
+}
+
+// Formatter returns the file formatter, which defaults to the logger format.
+//
+// Text output to a file is never colorized.
+func (fc FileConfig) Formatter(c Config) WriteFormatter {
+	format := fc.Format
+	if format == "" {
+		format = c.FormatOrDefault()
+	}
+	text := c.Text
+	text.NoColor = true
+	return c.formatter(format, text)
+}
+
+// RateLimitConfig is the config for rate limiting similar events.
+type RateLimitConfig struct {
+	// Rate is how many events per second are allowed for each set of similar events.
+	// Rate limiting is disabled if it is unset.
+	Rate float64 `json:"rate,omitempty" yaml:"rate,omitempty" env:"LOG_RATE_LIMIT"`
+	// Burst is how many events can be logged at once for each set of similar events.
+	Burst int `json:"burst,omitempty" yaml:"burst,omitempty" env:"LOG_RATE_LIMIT_BURST"`
+	// Flags are the flags that are rate limited.
+	Flags []string `json:"flags,omitempty" yaml:"flags,omitempty" env:"LOG_RATE_LIMIT_FLAGS,csv"`
+	// SummaryInterval is how often summaries of suppressed events are logged.
+	SummaryInterval time.Duration `json:"summaryInterval,omitempty" yaml:"summaryInterval,omitempty" env:"LOG_RATE_LIMIT_SUMMARY_INTERVAL"`
+}
+
+// IsEnabled returns if rate limiting is enabled.
+func (rlc RateLimitConfig) IsEnabled() bool {
+	return rlc.Rate > 0
+}
+
+// BurstOrDefault returns the burst or a default.
+func (rlc RateLimitConfig) BurstOrDefault() int {
+	if rlc.Burst > 0 {
+		return rlc.Burst
+	}
+	return DefaultRateLimitBurst
+}
+
+// FlagsOrDefault returns the rate limited flags or a default.
+func (rlc RateLimitConfig) FlagsOrDefault() []string {
+	if len(rlc.Flags) > 0 {
+		return rlc.Flags
+	}
+	return DefaultRateLimitFlags
+}
+
+// SummaryIntervalOrDefault returns the summary interval or a default.
+func (rlc RateLimitConfig) SummaryIntervalOrDefault() time.Duration {
+	if rlc.SummaryInterval > 0 {
+		return rlc.SummaryInterval
 	}
+	return DefaultRateLimitSummaryInterval
 }
 
 // TextConfig is the config for a text formatter.
@@ -119,6 +227,8 @@ type JSONConfig struct {
 	Pretty       bool   `json:"pretty,omitempty" yaml:"pretty,omitempty" env:"LOG_JSON_PRETTY"`
 	PrettyPrefix string `json:"prettyPrefix,omitempty" yaml:"prettyPrefix,omitempty" env:"LOG_JSON_PRETTY_PREFIX"`
 	PrettyIndent string `json:"prettyIndent,omitempty" yaml:"prettyIndent,omitempty" env:"LOG_JSON_PRETTY_INDENT"`
+	// Schema is the optional schema json fields are mapped onto, either `ecs` or `otel`.
+	Schema string `json:"schema,omitempty" yaml:"schema,omitempty" env:"LOG_JSON_SCHEMA"`
 }
 
 // PrettyPrefixOrDefault returns the pretty prefix or a default.
@@ -8,12 +8,18 @@ Use of this source code is governed by a MIT license that can be found in the LI
 package logger
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"syscall"
 	"testing"
 	"time"
 
 	"github.com/blend/go-sdk/assert"
 	"github.com/blend/go-sdk/env"
+	"github.com/blend/go-sdk/ex"
 )
 
 func TestConfig(t *testing.T) {
@@ -55,3 +61,100 @@ func TestConfigResolve(t *testing.T) {
 	assert.True(cfg.Text.NoColor)
 	assert.Equal(time.Kitchen, cfg.Text.TimeFormat)
 }
+
+func TestConfigResolveSampleRateLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	defer env.Restore()
+	env.Env().Set("LOG_SAMPLE", "debug=10,info=2")
+	env.Env().Set("LOG_RATE_LIMIT", "0.5")
+	env.Env().Set("LOG_RATE_LIMIT_BURST", "5")
+	env.Env().Set("LOG_RATE_LIMIT_FLAGS", "error")
+	env.Env().Set("LOG_RATE_LIMIT_SUMMARY_INTERVAL", "30s")
+
+	cfg := &Config{}
+	ctx := env.WithVars(context.Background(), env.Env())
+	assert.Nil(cfg.Resolve(ctx))
+
+	assert.Equal([]string{"debug=10", "info=2"}, cfg.Sample)
+	assert.True(cfg.RateLimit.IsEnabled())
+	assert.Equal(0.5, cfg.RateLimit.Rate)
+	assert.Equal(5, cfg.RateLimit.BurstOrDefault())
+	assert.Equal([]string{Error}, cfg.RateLimit.FlagsOrDefault())
+	assert.Equal(30*time.Second, cfg.RateLimit.SummaryIntervalOrDefault())
+
+	log, err := New(OptConfig(*cfg))
+	assert.Nil(err)
+	assert.True(log.HasFilter(Debug, FilterNameSample))
+	assert.True(log.HasFilter(Info, FilterNameSample))
+	assert.True(log.HasFilter(Error, FilterNameRateLimit))
+	assert.False(log.HasFilter(Info, FilterNameRateLimit))
+
+	_, err = New(OptConfig(Config{Sample: []string{"debug"}}))
+	assert.True(ex.Is(err, ErrInvalidSampleRate))
+}
+
+func TestConfigFile(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	buffer := new(bytes.Buffer)
+	log, err := New(
+		OptConfig(Config{
+			Flags:  []string{FlagAll},
+			Format: FormatText,
+			Text:   TextConfig{NoColor: true, HideTimestamp: true},
+			File:   FileConfig{Path: path, Format: FormatJSON},
+		}),
+		OptOutput(buffer),
+	)
+	assert.Nil(err)
+	assert.Len(log.Sinks, 1)
+
+	log.Info("hello")
+	log.Close()
+
+	assert.Equal("[info] hello\n", buffer.String())
+	contents, err := os.ReadFile(path)
+	assert.Nil(err)
+	var decoded map[string]interface{}
+	assert.Nil(json.Unmarshal(contents, &decoded))
+	assert.Equal("hello", decoded["text"])
+}
+
+func TestConfigFormatter(t *testing.T) {
+	its := assert.New(t)
+
+	_, ok := Config{}.Formatter().(*TextOutputFormatter)
+	its.True(ok)
+	_, ok = Config{Format: FormatLogfmt}.Formatter().(*LogfmtOutputFormatter)
+	its.True(ok)
+
+	jf, ok := Config{Format: FormatJSON}.Formatter().(*JSONOutputFormatter)
+	its.True(ok)
+	its.Empty(jf.Schema)
+	jf, ok = Config{Format: FormatJSON, JSON: JSONConfig{Schema: JSONSchemaOTel}}.Formatter().(*JSONOutputFormatter)
+	its.True(ok)
+	its.Equal(JSONSchemaOTel, jf.Schema)
+	jf, ok = Config{Format: FormatECS}.Formatter().(*JSONOutputFormatter)
+	its.True(ok)
+	its.Equal(JSONSchemaECS, jf.Schema)
+	jf, ok = Config{Format: FormatOTel}.Formatter().(*JSONOutputFormatter)
+	its.True(ok)
+	its.Equal(JSONSchemaOTel, jf.Schema)
+}
+
+func TestFileConfigWriterReopenOnHangup(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	rfw, err := FileConfig{Path: path}.Writer()
+	assert.Nil(err)
+	assert.Empty(rfw.ReopenSignals, "files should not be reopened on SIGHUP unless it is set")
+	assert.Nil(rfw.Close())
+
+	rfw, err = FileConfig{Path: path, ReopenOnHangup: true}.Writer()
+	assert.Nil(err)
+	assert.Equal([]os.Signal{syscall.SIGHUP}, rfw.ReopenSignals)
+	assert.Nil(rfw.Close())
+}
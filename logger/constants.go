@@ -35,8 +35,27 @@ const (
 
 // Output Formats
 const (
-	FormatJSON = "json"
-	FormatText = "text"
+	FormatJSON   = "json"
+	FormatText   = "text"
+	FormatLogfmt = "logfmt"
+	// FormatECS is json with fields mapped onto the Elastic Common Schema.
+	FormatECS = "ecs"
+	// FormatOTel is json with fields mapped onto the OpenTelemetry log data model.
+	FormatOTel = "otel"
+)
+
+// JSON Schemas
+const (
+	// JSONSchemaECS maps json fields onto the Elastic Common Schema.
+	JSONSchemaECS = "ecs"
+	// JSONSchemaOTel maps json fields onto the OpenTelemetry log data model.
+	JSONSchemaOTel = "otel"
+)
+
+// Tracing annotations, as set on a context by `tracing.WithTraceAnnotations`.
+const (
+	AnnotationTracingSpanID  = "tracing.span-id"
+	AnnotationTracingTraceID = "tracing.trace-id"
 )
 
 // Default flags
@@ -57,6 +76,9 @@ const (
 	EnvVarHideTime   = "LOG_HIDE_TIME"
 	EnvVarTimeFormat = "LOG_TIME_FORMAT"
 	EnvVarJSONPretty = "LOG_JSON_PRETTY"
+	EnvVarJSONSchema = "LOG_JSON_SCHEMA"
+	EnvVarSample     = "LOG_SAMPLE"
+	EnvVarRateLimit  = "LOG_RATE_LIMIT"
 )
 
 const (
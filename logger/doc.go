@@ -13,5 +13,13 @@ It has two main modes of output; text and json, and allows multiple listeners to
 The output is governed by the `LOG_FORMAT` environment variable. Text output is the default, which
 is great for reading locally, but is less than optimal for search and automated ingestion. In
 production systems, `LOG_FORMAT=json` is recommended.
+
+`LOG_FORMAT=logfmt` writes `key=value` pairs, and `LOG_FORMAT=ecs` or `LOG_FORMAT=otel` write json with
+fields mapped onto the Elastic Common Schema or the OpenTelemetry log data model respectively, including
+the trace and span ids set by the `tracing` package.
+
+Noisy flags can be sampled with `LOG_SAMPLE`, e.g. `LOG_SAMPLE=debug=10` logs 1 in every 10 debug events,
+and similar events (by flag, scope path and message) can be rate limited with `LOG_RATE_LIMIT`, the
+number of similar events allowed per second, alongside `LOG_RATE_LIMIT_BURST` and `LOG_RATE_LIMIT_FLAGS`.
 */
 package logger // import "github.com/blend/go-sdk/logger"
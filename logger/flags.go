@@ -156,3 +156,16 @@ func (efs Flags) MergeWith(other *Flags) {
 		efs.flags[key] = value
 	}
 }
+
+// Copy returns a copy of the flag set.
+func (efs Flags) Copy() *Flags {
+	output := &Flags{
+		flags: make(map[string]bool, len(efs.flags)),
+		all:   efs.all,
+		none:  efs.none,
+	}
+	for key, value := range efs.flags {
+		output.flags[key] = value
+	}
+	return output
+}
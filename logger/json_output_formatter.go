@@ -40,9 +40,17 @@ func OptJSONConfig(cfg JSONConfig) JSONOutputFormatterOption {
 		jf.Pretty = cfg.Pretty
 		jf.PrettyIndent = cfg.PrettyIndentOrDefault()
 		jf.PrettyPrefix = cfg.PrettyPrefixOrDefault()
+		jf.Schema = cfg.Schema
 	}
 }
 
+// OptJSONSchema sets the schema json fields are mapped onto, either `ecs` or `otel`.
+//
+// If unset, events are written with the sdk fields, e.g. `flag` and `_timestamp`.
+func OptJSONSchema(schema string) JSONOutputFormatterOption {
+	return func(jso *JSONOutputFormatter) { jso.Schema = schema }
+}
+
 // OptJSONPretty sets the json output formatter to indent output.
 func OptJSONPretty() JSONOutputFormatterOption {
 	return func(jso *JSONOutputFormatter) { jso.Pretty = true }
@@ -64,6 +72,8 @@ type JSONOutputFormatter struct {
 	Pretty       bool
 	PrettyPrefix string
 	PrettyIndent string
+	// Schema is the optional schema fields are mapped onto, either `ecs` or `otel`.
+	Schema string
 }
 
 // PrettyPrefixOrDefault returns the pretty prefix or a default.
@@ -91,7 +101,11 @@ func (jw JSONOutputFormatter) WriteFormat(ctx context.Context, output io.Writer,
 	if jw.Pretty {
 		encoder.SetIndent(jw.PrettyPrefixOrDefault(), jw.PrettyIndentOrDefault())
 	}
-	if decomposer, ok := e.(JSONWritable); ok {
+	if schemaFields := jw.GetSchemaFields(ctx, e); schemaFields != nil {
+		if err := encoder.Encode(schemaFields); err != nil {
+			return err
+		}
+	} else if decomposer, ok := e.(JSONWritable); ok {
 		fields := jw.CombineFields(jw.GetScopeFields(ctx, e), decomposer.Decompose())
 		if err := encoder.Encode(fields); err != nil {
 			return err
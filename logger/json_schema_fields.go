@@ -0,0 +1,179 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package logger
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Elastic Common Schema fields.
+//
+// See https://www.elastic.co/guide/en/ecs/current/ecs-field-reference.html
+const (
+	ECSVersion = "8.11.0"
+
+	ECSFieldTimestamp   = "@timestamp"
+	ECSFieldVersion     = "ecs.version"
+	ECSFieldLogLevel    = "log.level"
+	ECSFieldLogLogger   = "log.logger"
+	ECSFieldMessage     = "message"
+	ECSFieldLabels      = "labels"
+	ECSFieldTraceID     = "trace.id"
+	ECSFieldSpanID      = "span.id"
+	ECSFieldAnnotations = "annotations"
+	// ECSFieldData is the custom field the event fields are nested under.
+	ECSFieldData = "data"
+)
+
+// OpenTelemetry log data model fields.
+//
+// See https://opentelemetry.io/docs/specs/otel/logs/data-model/
+const (
+	OTelFieldTimestamp      = "timeUnixNano"
+	OTelFieldSeverityText   = "severityText"
+	OTelFieldSeverityNumber = "severityNumber"
+	OTelFieldBody           = "body"
+	OTelFieldAttributes     = "attributes"
+	OTelFieldTraceID        = "traceId"
+	OTelFieldSpanID         = "spanId"
+)
+
+// OpenTelemetry severity numbers.
+const (
+	OTelSeverityDebug = 5
+	OTelSeverityInfo  = 9
+	OTelSeverityWarn  = 13
+	OTelSeverityError = 17
+	OTelSeverityFatal = 21
+)
+
+// GetSchemaFields returns the fields for an event mapped onto the formatter schema,
+// or nil if the schema is unset or unknown.
+func (jw JSONOutputFormatter) GetSchemaFields(ctx context.Context, e Event) map[string]interface{} {
+	switch strings.ToLower(jw.Schema) {
+	case JSONSchemaECS:
+		return jw.GetECSFields(ctx, e)
+	case JSONSchemaOTel:
+		return jw.GetOTelFields(ctx, e)
+	default:
+		return nil
+	}
+}
+
+// GetECSFields returns the fields for an event mapped onto the Elastic Common Schema.
+//
+// The flag is the log level, the scope path is the logger name, the event text is the message,
+// and the trace and span ids are taken from the annotations set by `tracing`.
+// Labels are written as ECS labels, and the event fields are nested under `data`.
+func (jw JSONOutputFormatter) GetECSFields(ctx context.Context, e Event) map[string]interface{} {
+	output := map[string]interface{}{
+		ECSFieldTimestamp: GetEventTimestamp(ctx, e).UTC().Format(time.RFC3339Nano),
+		ECSFieldVersion:   ECSVersion,
+		ECSFieldLogLevel:  e.GetFlag(),
+		ECSFieldMessage:   FormatEventText(e),
+	}
+	if path := GetPath(ctx); len(path) > 0 {
+		output[ECSFieldLogLogger] = strings.Join(path, "/")
+	}
+	if labels := GetLabels(ctx); len(labels) > 0 {
+		output[ECSFieldLabels] = labels
+	}
+	traceID, spanID, annotations := getTracingIDs(ctx)
+	if traceID != "" {
+		output[ECSFieldTraceID] = traceID
+	}
+	if spanID != "" {
+		output[ECSFieldSpanID] = spanID
+	}
+	if len(annotations) > 0 {
+		output[ECSFieldAnnotations] = annotations
+	}
+	if decomposer, ok := e.(JSONWritable); ok {
+		if data := decomposer.Decompose(); len(data) > 0 {
+			output[ECSFieldData] = data
+		}
+	}
+	return output
+}
+
+// GetOTelFields returns the fields for an event mapped onto the OpenTelemetry log data model.
+//
+// The flag is mapped to a severity, the event text is the body, and the trace and span ids
+// are taken from the annotations set by `tracing` and written as hex. The event fields,
+// the scope path, annotations and labels are written as attributes, in that order of precedence.
+func (jw JSONOutputFormatter) GetOTelFields(ctx context.Context, e Event) map[string]interface{} {
+	severityText, severityNumber := OTelSeverity(e.GetFlag())
+	output := map[string]interface{}{
+		OTelFieldTimestamp:      strconv.FormatInt(GetEventTimestamp(ctx, e).UnixNano(), 10),
+		OTelFieldSeverityText:   severityText,
+		OTelFieldSeverityNumber: severityNumber,
+		OTelFieldBody:           FormatEventText(e),
+	}
+	traceID, spanID, annotations := getTracingIDs(ctx)
+	if traceID != "" {
+		output[OTelFieldTraceID] = otelID(traceID, 32)
+	}
+	if spanID != "" {
+		output[OTelFieldSpanID] = otelID(spanID, 16)
+	}
+
+	// attributes are written lowest precedence first, so event fields win on collisions
+	attributes := make(map[string]interface{})
+	for key, value := range GetLabels(ctx) {
+		attributes[key] = value
+	}
+	for key, value := range annotations {
+		attributes[key] = value
+	}
+	if path := GetPath(ctx); len(path) > 0 {
+		attributes[FieldScopePath] = strings.Join(path, "/")
+	}
+	if decomposer, ok := e.(JSONWritable); ok {
+		for key, value := range decomposer.Decompose() {
+			attributes[key] = value
+		}
+	}
+	if len(attributes) > 0 {
+		output[OTelFieldAttributes] = attributes
+	}
+	return output
+}
+
+// OTelSeverity returns the OpenTelemetry severity text and number for a flag.
+//
+// Flags that are not log levels (e.g. `http.request`) keep the flag as the severity text with an info severity number.
+func OTelSeverity(flag string) (string, int) {
+	switch flag {
+	case Debug:
+		return "DEBUG", OTelSeverityDebug
+	case Info:
+		return "INFO", OTelSeverityInfo
+	case Warning:
+		return "WARN", OTelSeverityWarn
+	case Error:
+		return "ERROR", OTelSeverityError
+	case Fatal:
+		return "FATAL", OTelSeverityFatal
+	default:
+		return flag, OTelSeverityInfo
+	}
+}
+
+// otelID formats a decimal id (as set by `tracing`) as zero padded hex of a given width;
+// ids that are not decimal are returned unchanged.
+func otelID(id string, width int) string {
+	parsed, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return id
+	}
+	return fmt.Sprintf("%0*x", width, parsed)
+}
@@ -0,0 +1,106 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func schemaFieldsContext() context.Context {
+	ctx := WithTimestamp(context.Background(), time.Date(2022, 01, 02, 03, 04, 05, 0, time.UTC))
+	ctx = WithPath(ctx, "worker", "email")
+	ctx = WithLabels(ctx, Labels{"user": "example"})
+	return WithAnnotations(ctx, Annotations{
+		AnnotationTracingTraceID: "123",
+		AnnotationTracingSpanID:  "456",
+		"attempt":                2,
+	})
+}
+
+func TestJSONOutputFormatterECS(t *testing.T) {
+	its := assert.New(t)
+
+	buf := new(bytes.Buffer)
+	jf := NewJSONOutputFormatter(OptJSONSchema(JSONSchemaECS))
+	its.Nil(jf.WriteFormat(schemaFieldsContext(), buf, NewMessageEvent(Warning, "sent email")))
+
+	var fields map[string]interface{}
+	its.Nil(json.Unmarshal(buf.Bytes(), &fields))
+	its.Equal("2022-01-02T03:04:05Z", fields[ECSFieldTimestamp])
+	its.Equal(ECSVersion, fields[ECSFieldVersion])
+	its.Equal(Warning, fields[ECSFieldLogLevel])
+	its.Equal("worker/email", fields[ECSFieldLogLogger])
+	its.Equal("sent email", fields[ECSFieldMessage])
+	its.Equal("123", fields[ECSFieldTraceID])
+	its.Equal("456", fields[ECSFieldSpanID])
+	its.Equal(map[string]interface{}{"user": "example"}, fields[ECSFieldLabels])
+	its.Equal(map[string]interface{}{"attempt": 2.0}, fields[ECSFieldAnnotations])
+	its.Equal("sent email", fields[ECSFieldData].(map[string]interface{})[FieldText])
+	its.Nil(fields[FieldFlag])
+}
+
+func TestJSONOutputFormatterOTel(t *testing.T) {
+	its := assert.New(t)
+
+	buf := new(bytes.Buffer)
+	jf := NewJSONOutputFormatter(OptJSONSchema(JSONSchemaOTel))
+	its.Nil(jf.WriteFormat(schemaFieldsContext(), buf, NewMessageEvent(Error, "failed to send email")))
+
+	var fields map[string]interface{}
+	its.Nil(json.Unmarshal(buf.Bytes(), &fields))
+	its.Equal("1641092645000000000", fields[OTelFieldTimestamp])
+	its.Equal("ERROR", fields[OTelFieldSeverityText])
+	its.Equal(float64(OTelSeverityError), fields[OTelFieldSeverityNumber])
+	its.Equal("failed to send email", fields[OTelFieldBody])
+	its.Equal("0000000000000000000000000000007b", fields[OTelFieldTraceID])
+	its.Equal("00000000000001c8", fields[OTelFieldSpanID])
+
+	attributes := fields[OTelFieldAttributes].(map[string]interface{})
+	its.Equal("example", attributes["user"])
+	its.Equal(2.0, attributes["attempt"])
+	its.Equal("worker/email", attributes[FieldScopePath])
+	its.Equal("failed to send email", attributes[FieldText])
+	its.Nil(attributes[AnnotationTracingTraceID])
+}
+
+func TestJSONOutputFormatterOTelAttributePrecedence(t *testing.T) {
+	its := assert.New(t)
+
+	ctx := WithPath(context.Background(), "worker")
+	ctx = WithLabels(ctx, Labels{FieldText: "label", FieldScopePath: "label", "user": "label", "team": "label"})
+	ctx = WithAnnotations(ctx, Annotations{FieldText: "annotation", "user": "annotation"})
+
+	buf := new(bytes.Buffer)
+	jf := NewJSONOutputFormatter(OptJSONSchema(JSONSchemaOTel))
+	its.Nil(jf.WriteFormat(ctx, buf, NewMessageEvent(Info, "event")))
+
+	var fields map[string]interface{}
+	its.Nil(json.Unmarshal(buf.Bytes(), &fields))
+	attributes := fields[OTelFieldAttributes].(map[string]interface{})
+	its.Equal("event", attributes[FieldText])
+	its.Equal("worker", attributes[FieldScopePath])
+	its.Equal("annotation", attributes["user"])
+	its.Equal("label", attributes["team"])
+}
+
+func TestOTelSeverity(t *testing.T) {
+	its := assert.New(t)
+
+	text, number := OTelSeverity(Debug)
+	its.Equal("DEBUG", text)
+	its.Equal(OTelSeverityDebug, number)
+	text, number = OTelSeverity("http.request")
+	its.Equal("http.request", text)
+	its.Equal(OTelSeverityInfo, number)
+}
@@ -0,0 +1,194 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/blend/go-sdk/bufferutil"
+)
+
+var (
+	_ WriteFormatter = (*LogfmtOutputFormatter)(nil)
+)
+
+// Logfmt keys.
+const (
+	LogfmtKeyTime    = "time"
+	LogfmtKeyLevel   = "level"
+	LogfmtKeyScope   = "scope"
+	LogfmtKeyMessage = "msg"
+	LogfmtKeyTraceID = "trace_id"
+	LogfmtKeySpanID  = "span_id"
+
+	// LogfmtLabelPrefix is prepended to labels with the same key as another pair.
+	LogfmtLabelPrefix = "label."
+	// LogfmtAnnotationPrefix is prepended to annotations with the same key as another pair.
+	LogfmtAnnotationPrefix = "annotation."
+)
+
+// NewLogfmtOutputFormatter returns a new logfmt formatter.
+func NewLogfmtOutputFormatter(options ...LogfmtOutputFormatterOption) *LogfmtOutputFormatter {
+	lf := &LogfmtOutputFormatter{
+		BufferPool: bufferutil.NewPool(DefaultBufferPoolSize),
+		TimeFormat: DefaultTextTimeFormat,
+	}
+	for _, option := range options {
+		option(lf)
+	}
+	return lf
+}
+
+// LogfmtOutputFormatterOption is an option for logfmt formatters.
+type LogfmtOutputFormatterOption func(*LogfmtOutputFormatter)
+
+// OptLogfmtConfig sets the logfmt formatter timestamp options from a text config.
+func OptLogfmtConfig(cfg TextConfig) LogfmtOutputFormatterOption {
+	return func(lf *LogfmtOutputFormatter) {
+		lf.HideTimestamp = cfg.HideTimestamp
+		lf.TimeFormat = cfg.TimeFormatOrDefault()
+	}
+}
+
+// OptLogfmtTimeFormat sets the timestamp format.
+func OptLogfmtTimeFormat(format string) LogfmtOutputFormatterOption {
+	return func(lf *LogfmtOutputFormatter) { lf.TimeFormat = format }
+}
+
+// OptLogfmtHideTimestamp hides the timestamp in output.
+func OptLogfmtHideTimestamp() LogfmtOutputFormatterOption {
+	return func(lf *LogfmtOutputFormatter) { lf.HideTimestamp = true }
+}
+
+// LogfmtOutputFormatter writes events as logfmt, i.e. space separated `key=value` pairs.
+//
+// Each line has the timestamp, the flag as the level, the scope path, the event text
+// as the message, the trace and span ids (if set by `tracing`), and then the labels
+// and annotations in alphabetic order, e.g.
+//
+//	time=2022-01-01T00:00:00Z level=info scope=worker/email msg="sent email" trace_id=123 span_id=456 user=example
+//
+// Labels with the same key as a preceding pair (e.g. `level`) are prefixed with `label.`, and
+// annotations with `annotation.`; pairs whose prefixed key is still taken are skipped.
+type LogfmtOutputFormatter struct {
+	BufferPool    *bufferutil.Pool
+	HideTimestamp bool
+	TimeFormat    string
+}
+
+// TimeFormatOrDefault returns the time format or a default.
+func (lf LogfmtOutputFormatter) TimeFormatOrDefault() string {
+	if lf.TimeFormat != "" {
+		return lf.TimeFormat
+	}
+	return DefaultTextTimeFormat
+}
+
+// WriteFormat implements write formatter.
+func (lf LogfmtOutputFormatter) WriteFormat(ctx context.Context, output io.Writer, e Event) error {
+	buffer := lf.BufferPool.Get()
+	defer lf.BufferPool.Put(buffer)
+
+	var pairs []string
+	keys := map[string]bool{}
+	add := func(prefix, key, value string) {
+		key = formatLogfmtKey(key)
+		if keys[key] {
+			key = prefix + key
+		}
+		if keys[key] {
+			return
+		}
+		keys[key] = true
+		pairs = append(pairs, FormatLogfmtPair(key, value))
+	}
+
+	if !lf.HideTimestamp {
+		pairs = append(pairs, FormatLogfmtPair(LogfmtKeyTime, GetEventTimestamp(ctx, e).Format(lf.TimeFormatOrDefault())))
+	}
+	pairs = append(pairs, FormatLogfmtPair(LogfmtKeyLevel, e.GetFlag()))
+	if path := GetPath(ctx); len(path) > 0 {
+		pairs = append(pairs, FormatLogfmtPair(LogfmtKeyScope, strings.Join(path, "/")))
+	}
+	pairs = append(pairs, FormatLogfmtPair(LogfmtKeyMessage, FormatEventText(e)))
+
+	traceID, spanID, annotations := getTracingIDs(ctx)
+	if traceID != "" {
+		pairs = append(pairs, FormatLogfmtPair(LogfmtKeyTraceID, traceID))
+	}
+	if spanID != "" {
+		pairs = append(pairs, FormatLogfmtPair(LogfmtKeySpanID, spanID))
+	}
+	for _, key := range []string{LogfmtKeyTime, LogfmtKeyLevel, LogfmtKeyScope, LogfmtKeyMessage, LogfmtKeyTraceID, LogfmtKeySpanID} {
+		keys[key] = true
+	}
+	labels := GetLabels(ctx)
+	for _, key := range sortedKeys(labels) {
+		add(LogfmtLabelPrefix, key, labels[key])
+	}
+	annotationKeys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		annotationKeys = append(annotationKeys, key)
+	}
+	sort.Strings(annotationKeys)
+	for _, key := range annotationKeys {
+		add(LogfmtAnnotationPrefix, key, fmt.Sprint(annotations[key]))
+	}
+
+	buffer.WriteString(strings.Join(pairs, Space))
+	buffer.WriteString(Newline)
+	_, err := io.Copy(output, buffer)
+	return err
+}
+
+// FormatLogfmtPair formats a logfmt `key=value` pair, quoting the value if required.
+//
+// Characters that are not valid in a key (spaces, `=` and `"`) are replaced with `_`.
+func FormatLogfmtPair(key, value string) string {
+	key = formatLogfmtKey(key)
+	if logfmtNeedsQuote(value) {
+		return key + "=" + strconv.Quote(value)
+	}
+	return key + "=" + value
+}
+
+func formatLogfmtKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '=' || r == '"' || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+func logfmtNeedsQuote(value string) bool {
+	if value == "" {
+		return true
+	}
+	for _, r := range value {
+		if r == '=' || r == '"' || r == '\\' || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(labels Labels) []string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,58 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func TestLogfmtOutputFormatter(t *testing.T) {
+	its := assert.New(t)
+
+	ctx := WithTimestamp(context.Background(), time.Date(2022, 01, 02, 03, 04, 05, 0, time.UTC))
+	ctx = WithPath(ctx, "worker", "email")
+	ctx = WithLabels(ctx, Labels{"user": "example", "empty": ""})
+	ctx = WithAnnotations(ctx, Annotations{
+		AnnotationTracingTraceID: "123",
+		AnnotationTracingSpanID:  "456",
+		"attempt":                2,
+	})
+
+	buf := new(bytes.Buffer)
+	its.Nil(NewLogfmtOutputFormatter().WriteFormat(ctx, buf, NewMessageEvent(Info, `sent "email" to a=b`)))
+	its.Equal(`time=2022-01-02T03:04:05Z level=info scope=worker/email msg="sent \"email\" to a=b" trace_id=123 span_id=456 empty="" user=example attempt=2`+"\n", buf.String())
+
+	buf.Reset()
+	its.Nil(NewLogfmtOutputFormatter(OptLogfmtHideTimestamp()).WriteFormat(context.Background(), buf, NewMessageEvent(Debug, "ok")))
+	its.Equal("level=debug msg=ok\n", buf.String())
+}
+
+func TestLogfmtOutputFormatterReservedKeys(t *testing.T) {
+	its := assert.New(t)
+
+	ctx := WithLabels(context.Background(), Labels{"level": "high", "msg": "label", "user": "example", "annotation.time": "label"})
+	ctx = WithAnnotations(ctx, Annotations{"time": "now", "user": "annotation", "label.msg": "taken"})
+
+	buf := new(bytes.Buffer)
+	its.Nil(NewLogfmtOutputFormatter(OptLogfmtHideTimestamp()).WriteFormat(ctx, buf, NewMessageEvent(Info, "ok")))
+	its.Equal("level=info msg=ok annotation.time=label label.level=high label.msg=label user=example annotation.label.msg=taken annotation.user=annotation\n", buf.String())
+}
+
+func TestFormatLogfmtPair(t *testing.T) {
+	its := assert.New(t)
+
+	its.Equal("foo=bar", FormatLogfmtPair("foo", "bar"))
+	its.Equal(`foo_bar="a b"`, FormatLogfmtPair("foo bar", "a b"))
+	its.Equal(`foo_="a\nb"`, FormatLogfmtPair("foo=", "a\nb"))
+	its.Equal(`foo=""`, FormatLogfmtPair("foo", ""))
+}
@@ -12,6 +12,7 @@ import (
 	"io"
 	"os"
 	"sync"
+	"sync/atomic"
 )
 
 // New returns a new logger with a given set of enabled flags.
@@ -105,12 +106,20 @@ type Logger struct {
 	Output    io.Writer
 	Formatter WriteFormatter
 	Errors    chan error
+	// Sinks are additional formatters and outputs events are written to,
+	// e.g. to write json to a file while writing text to stdout.
+	Sinks []Sink
 
 	// Filters hold filters organized by flag, and then by filter name.
 	// The intent is to modify event data before it is written or given to listeners.
 	Filters map[string]map[string]Filter
 	// Listeners hold event listeners organized by flag, and then by listener name.
 	Listeners map[string]map[string]*Worker
+
+	overrideMu sync.Mutex
+	override   atomic.Value
+
+	rateLimiter *RateLimiter
 }
 
 // GetFlags returns the flags.
@@ -299,10 +308,12 @@ func (l *Logger) Dispatch(ctx context.Context, e Event) {
 		return
 	}
 	flag := e.GetFlag()
-	if !l.IsEnabled(flag) {
+	path := GetPath(ctx)
+	flags, scopes := l.enabledFor(path)
+	if !flags.IsEnabled(flag) {
 		return
 	}
-	if !l.Scopes.IsEnabled(GetPath(ctx)...) {
+	if !scopes.IsEnabled(path...) {
 		return
 	}
 
@@ -339,22 +350,35 @@ func (l *Logger) Dispatch(ctx context.Context, e Event) {
 
 // Write writes an event synchronously to the writer either as a normal even or as an error.
 func (l *Logger) Write(ctx context.Context, e Event) {
-	// if a formater or the output are unset, bail.
-	if l.Formatter == nil || l.Output == nil {
+	// if a formater or the output are unset, and there are no sinks, bail.
+	if (l.Formatter == nil || l.Output == nil) && len(l.Sinks) == 0 {
 		return
 	}
 
 	if IsSkipWrite(ctx) {
 		return
 	}
-	if !l.Writable.IsEnabled(e.GetFlag()) {
+	path := GetPath(ctx)
+	writable, writableScopes := l.writableFor(path)
+	if !writable.IsEnabled(e.GetFlag()) {
 		return
 	}
-	if !l.WritableScopes.IsEnabled(GetPath(ctx)...) {
+	if !writableScopes.IsEnabled(path...) {
 		return
 	}
 
-	err := l.Formatter.WriteFormat(ctx, l.Output, e)
+	if l.Formatter != nil && l.Output != nil {
+		l.writeFormat(ctx, l.Formatter, l.Output, e)
+	}
+	for _, sink := range l.Sinks {
+		if sink.Formatter != nil && sink.Output != nil {
+			l.writeFormat(ctx, sink.Formatter, sink.Output, e)
+		}
+	}
+}
+
+func (l *Logger) writeFormat(ctx context.Context, formatter WriteFormatter, output io.Writer, e Event) {
+	err := formatter.WriteFormat(ctx, output, e)
 	if err != nil && l.Errors != nil {
 		l.Errors <- err
 	}
@@ -365,9 +389,15 @@ func (l *Logger) Write(ctx context.Context, e Event) {
 // --------------------------------------------------------------------------------
 
 // Close releases shared resources for the agent.
-// It will stop listeners and wait for them to complete work
+// It will flush rate limit summaries, stop listeners and wait for them to complete work
 // and then zero out any other resources.
 func (l *Logger) Close() {
+	if l.rateLimiter != nil {
+		l.rateLimiter.Flush()
+	}
+
+	l.RevertOverride()
+
 	l.Lock()
 	defer l.Unlock()
 
@@ -383,6 +413,11 @@ func (l *Logger) Close() {
 	if closer, ok := l.Output.(io.Closer); ok {
 		_ = closer.Close()
 	}
+	for _, sink := range l.Sinks {
+		if closer, ok := sink.Output.(io.Closer); ok {
+			_ = closer.Close()
+		}
+	}
 	l.Listeners = nil
 	l.Filters = nil
 }
@@ -394,7 +429,12 @@ func (l *Logger) Drain() {
 }
 
 // DrainContext waits for the logger to finish its queue of events with a given context.
+//
+// Rate limit summaries are flushed first so they are included in the drained events.
 func (l *Logger) DrainContext(ctx context.Context) {
+	if l.rateLimiter != nil {
+		l.rateLimiter.Flush()
+	}
 	for _, workers := range l.Listeners {
 		for _, worker := range workers {
 			_ = worker.StopContext(ctx)
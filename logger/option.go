@@ -24,6 +24,28 @@ func OptConfig(cfg Config) Option {
 		l.Writable = NewFlags(cfg.WritableOrDefault()...)
 		l.Scopes = NewScopes(cfg.ScopesOrDefault()...)
 		l.WritableScopes = NewScopes(cfg.WritableScopesOrDefault()...)
+
+		sampleRates, err := ParseSampleRates(cfg.Sample...)
+		if err != nil {
+			return err
+		}
+		for flag, rate := range sampleRates {
+			l.Filter(flag, FilterNameSample, NewSampleFilter(rate))
+		}
+		if cfg.File.Path != "" {
+			file, err := cfg.File.Writer()
+			if err != nil {
+				return err
+			}
+			l.Sinks = append(l.Sinks, Sink{Formatter: cfg.File.Formatter(cfg), Output: NewInterlockedWriter(file)})
+		}
+		if cfg.RateLimit.IsEnabled() {
+			rateLimiter := NewRateLimiter(l, cfg.RateLimit.Rate, cfg.RateLimit.BurstOrDefault(), cfg.RateLimit.SummaryIntervalOrDefault())
+			l.rateLimiter = rateLimiter
+			for _, flag := range cfg.RateLimit.FlagsOrDefault() {
+				l.Filter(flag, FilterNameRateLimit, rateLimiter.Filter)
+			}
+		}
 		return nil
 	}
 }
@@ -36,12 +58,7 @@ func OptConfigFromEnv() Option {
 		if err := env.Env().ReadInto(&cfg); err != nil {
 			return err
 		}
-		l.Formatter = cfg.Formatter()
-		l.Flags = NewFlags(cfg.FlagsOrDefault()...)
-		l.Writable = NewFlags(cfg.WritableOrDefault()...)
-		l.Scopes = NewScopes(cfg.ScopesOrDefault()...)
-		l.WritableScopes = NewScopes(cfg.WritableScopesOrDefault()...)
-		return nil
+		return OptConfig(cfg)(l)
 	}
 }
 
@@ -68,6 +85,22 @@ func OptOutput(output io.Writer) Option {
 	}
 }
 
+// OptSink adds an additional formatter and output that events are written to.
+//
+// It will wrap the output with a synchronizer if it's not already wrapped. To write text
+// to stdout and json to a rotating file, use the following:
+//
+//	file, _ := logger.NewRotatingFileWriter("app.log", logger.OptRotatingFileMaxSize(100<<20))
+//	log := logger.New(logger.OptText(), logger.OptSink(logger.NewJSONOutputFormatter(), file))
+//
+// Sink outputs are closed when the logger is closed.
+func OptSink(formatter WriteFormatter, output io.Writer) Option {
+	return func(l *Logger) error {
+		l.Sinks = append(l.Sinks, Sink{Formatter: formatter, Output: NewInterlockedWriter(output)})
+		return nil
+	}
+}
+
 // OptPath sets an initial logger context path.
 //
 // This is useful if you want to label a logger to differentiate areas of an application
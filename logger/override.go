@@ -0,0 +1,208 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package logger
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/blend/go-sdk/stringutil"
+)
+
+// Override is a temporary change to the flags and scopes of a logger,
+// e.g. to enable `debug` for a scope path while investigating an issue.
+//
+// Flags are given in the same form as `LOG_FLAGS`, e.g. `debug` to enable a flag,
+// `-info` to disable a flag, or `all` and `none`. Scopes are given in the same form
+// as `LOG_SCOPES`, e.g. `db/*` to enable a scope, `-db/*` to disable a scope, or `*`.
+type Override struct {
+	// Flags are changes to the enabled flags.
+	Flags []string `json:"flags,omitempty"`
+	// Writable are changes to the writable flags.
+	Writable []string `json:"writable,omitempty"`
+	// Scopes are changes to the enabled scopes.
+	Scopes []string `json:"scopes,omitempty"`
+	// WritableScopes are changes to the writable scopes.
+	WritableScopes []string `json:"writableScopes,omitempty"`
+	// Scope optionally limits the `Flags` and `Writable` changes to events with a scope path
+	// that matches a glob, e.g. `worker/*`; events with other scope paths use the logger's flags.
+	//
+	// Note that the scope path must also be enabled by the logger's scopes, or by `Scopes`.
+	Scope string `json:"scope,omitempty"`
+	// Until is an optional deadline after which the override is reverted.
+	Until time.Time `json:"until,omitempty"`
+}
+
+// ApplyOverride applies an override to the logger's flags and scopes,
+// replacing any active override.
+//
+// The logger's own flags and scopes are left unchanged; events are checked against
+// a copy of them with the override applied until the override is reverted.
+// If the override has an `Until` deadline, it is reverted automatically at the deadline.
+func (l *Logger) ApplyOverride(o Override) {
+	l.overrideMu.Lock()
+	defer l.overrideMu.Unlock()
+
+	l.revertOverrideUnsafe()
+	active := &activeOverride{
+		Override: o,
+		scope:    strings.ToLower(strings.TrimSpace(o.Scope)),
+		enabled:  newOverrideSet(l.Flags, l.Scopes, o.Flags, o.Scopes, o.Scope != ""),
+		writable: newOverrideSet(l.Writable, l.WritableScopes, o.Writable, o.WritableScopes, o.Scope != ""),
+	}
+	if !o.Until.IsZero() {
+		active.timer = time.AfterFunc(time.Until(o.Until), func() {
+			l.overrideMu.Lock()
+			defer l.overrideMu.Unlock()
+			if l.loadOverride() == active {
+				l.revertOverrideUnsafe()
+			}
+		})
+	}
+	l.override.Store(active)
+}
+
+// RevertOverride reverts the active override, if any.
+func (l *Logger) RevertOverride() {
+	l.overrideMu.Lock()
+	defer l.overrideMu.Unlock()
+	l.revertOverrideUnsafe()
+}
+
+// ActiveOverride returns the active override, if any.
+func (l *Logger) ActiveOverride() (Override, bool) {
+	active := l.loadOverride()
+	if active == nil {
+		return Override{}, false
+	}
+	return active.Override, true
+}
+
+// IsEnabled returns if a flag is enabled, taking an active override into account.
+//
+// Overrides limited to a scope path are not taken into account.
+func (l *Logger) IsEnabled(flag string) bool {
+	flags, _ := l.enabledFor(nil)
+	return flags.IsEnabled(flag)
+}
+
+// activeOverride is an immutable snapshot of an applied override.
+type activeOverride struct {
+	Override
+
+	scope    string
+	enabled  overrideSet
+	writable overrideSet
+
+	timer *time.Timer
+}
+
+// overrideSet holds copies of a logger's flags and scopes with an override applied;
+// fields are nil if the override does not change them.
+type overrideSet struct {
+	flags       *Flags
+	scopedFlags *Flags
+	scopes      *Scopes
+}
+
+// resolve returns the flags and scopes to check an event with a given scope path against.
+func (os overrideSet) resolve(scope string, flags *Flags, scopes *Scopes, path []string) (*Flags, *Scopes) {
+	if os.flags != nil {
+		flags = os.flags
+	}
+	if os.scopedFlags != nil && stringutil.Glob(filepath.Join(path...), scope) {
+		flags = os.scopedFlags
+	}
+	if os.scopes != nil {
+		scopes = os.scopes
+	}
+	return flags, scopes
+}
+
+func newOverrideSet(flags *Flags, scopes *Scopes, flagChanges, scopeChanges []string, scoped bool) (output overrideSet) {
+	if len(flagChanges) > 0 {
+		changed := flags.Copy()
+		applyFlags(changed, flagChanges)
+		if scoped {
+			output.scopedFlags = changed
+		} else {
+			output.flags = changed
+		}
+	}
+	if len(scopeChanges) > 0 {
+		output.scopes = scopes.Copy()
+		applyScopes(output.scopes, scopeChanges)
+	}
+	return
+}
+
+// loadOverride returns the active override snapshot, if any.
+func (l *Logger) loadOverride() *activeOverride {
+	active, _ := l.override.Load().(*activeOverride)
+	return active
+}
+
+func (l *Logger) revertOverrideUnsafe() {
+	active := l.loadOverride()
+	if active == nil {
+		return
+	}
+	if active.timer != nil {
+		active.timer.Stop()
+	}
+	l.override.Store((*activeOverride)(nil))
+}
+
+// enabledFor returns the flags and scopes to dispatch an event with a given scope path against.
+func (l *Logger) enabledFor(path []string) (*Flags, *Scopes) {
+	if active := l.loadOverride(); active != nil {
+		return active.enabled.resolve(active.scope, l.Flags, l.Scopes, path)
+	}
+	return l.Flags, l.Scopes
+}
+
+// writableFor returns the flags and scopes to write an event with a given scope path against.
+func (l *Logger) writableFor(path []string) (*Flags, *Scopes) {
+	if active := l.loadOverride(); active != nil {
+		return active.writable.resolve(active.scope, l.Writable, l.WritableScopes, path)
+	}
+	return l.Writable, l.WritableScopes
+}
+
+// applyFlags applies changes in `LOG_FLAGS` form to a flag set.
+func applyFlags(flags *Flags, changes []string) {
+	for _, change := range changes {
+		parsed := strings.ToLower(strings.TrimSpace(change))
+		switch {
+		case parsed == FlagAll:
+			flags.SetAll()
+		case parsed == FlagNone:
+			flags.SetNone()
+		case strings.HasPrefix(parsed, "-"):
+			flags.Disable(strings.TrimPrefix(parsed, "-"))
+		case parsed != "":
+			flags.Enable(parsed)
+		}
+	}
+}
+
+// applyScopes applies changes in `LOG_SCOPES` form to scopes.
+func applyScopes(scopes *Scopes, changes []string) {
+	for _, change := range changes {
+		parsed := strings.ToLower(strings.TrimSpace(change))
+		switch {
+		case parsed == ScopeAll:
+			scopes.SetAll()
+		case strings.HasPrefix(parsed, "-"):
+			scopes.Disable(strings.TrimPrefix(parsed, "-"))
+		case parsed != "":
+			scopes.Enable(parsed)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func TestLoggerApplyOverride(t *testing.T) {
+	its := assert.New(t)
+
+	buffer := new(bytes.Buffer)
+	log := Memory(buffer, OptFlags(NewFlags(Info, Error)))
+
+	log.ApplyOverride(Override{Flags: []string{Debug, "-" + Info}})
+	active, ok := log.ActiveOverride()
+	its.True(ok)
+	its.Equal([]string{Debug, "-" + Info}, active.Flags)
+	its.True(log.IsEnabled(Debug))
+	its.False(log.IsEnabled(Info))
+	its.True(log.IsEnabled(Error))
+
+	log.Debugf("debug message")
+	log.Infof("info message")
+	its.Contains(buffer.String(), "debug message")
+	its.NotContains(buffer.String(), "info message")
+
+	log.RevertOverride()
+	_, ok = log.ActiveOverride()
+	its.False(ok)
+	its.False(log.IsEnabled(Debug))
+	its.True(log.IsEnabled(Info))
+	its.True(log.IsEnabled(Error))
+}
+
+func TestLoggerApplyOverrideReplaces(t *testing.T) {
+	its := assert.New(t)
+
+	buffer := new(bytes.Buffer)
+	log := Memory(buffer, OptFlags(NewFlags(Info)), OptScopes(NewScopes("api/*")))
+	log.ApplyOverride(Override{Flags: []string{Debug}, Scopes: []string{"worker/*"}})
+	log.WithPath("worker", "foo").Infof("worker info")
+	its.Contains(buffer.String(), "worker info")
+
+	log.ApplyOverride(Override{Flags: []string{FlagNone}})
+	its.False(log.IsEnabled(Info), "the second override should apply to the original flags")
+	buffer.Reset()
+	log.WithPath("worker", "foo").Infof("worker info")
+	its.Empty(buffer.String(), "the first override should be reverted")
+
+	log.RevertOverride()
+	its.True(log.IsEnabled(Info))
+	its.False(log.IsEnabled(Debug))
+	log.WithPath("api", "foo").Infof("api info")
+	log.WithPath("worker", "foo").Infof("worker info")
+	its.Contains(buffer.String(), "api info")
+	its.NotContains(buffer.String(), "worker info")
+}
+
+func TestLoggerApplyOverrideLeavesFlags(t *testing.T) {
+	its := assert.New(t)
+
+	log := Memory(new(bytes.Buffer), OptFlags(NewFlags(Info)), OptScopes(NewScopes("api/*")))
+	log.ApplyOverride(Override{Flags: []string{Debug}, Scopes: []string{"worker/*"}})
+	its.True(log.IsEnabled(Debug))
+	its.False(log.Flags.IsEnabled(Debug))
+	its.False(log.Scopes.IsEnabled("worker", "foo"))
+
+	log.Flags.Enable(Error)
+	log.RevertOverride()
+	its.True(log.IsEnabled(Error), "changes made during the override should be kept")
+}
+
+func TestLoggerApplyOverrideScope(t *testing.T) {
+	its := assert.New(t)
+
+	buffer := new(bytes.Buffer)
+	log := Memory(buffer, OptFlags(NewFlags(Info)))
+	log.ApplyOverride(Override{Scope: "worker/*", Flags: []string{Debug}})
+	its.False(log.IsEnabled(Debug))
+
+	log.WithPath("worker", "email").Debugf("worker debug")
+	log.WithPath("api").Debugf("api debug")
+	log.WithPath("api").Infof("api info")
+	its.Contains(buffer.String(), "worker debug")
+	its.NotContains(buffer.String(), "api debug")
+	its.Contains(buffer.String(), "api info")
+
+	log.RevertOverride()
+	buffer.Reset()
+	log.WithPath("worker", "email").Debugf("worker debug")
+	its.Empty(buffer.String())
+}
+
+func TestLoggerApplyOverrideUntil(t *testing.T) {
+	its := assert.New(t)
+
+	log := Memory(new(bytes.Buffer), OptFlags(NewFlags(Info)))
+	log.ApplyOverride(Override{Flags: []string{Debug}, Until: time.Now().Add(10 * time.Millisecond)})
+	its.True(log.IsEnabled(Debug))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for log.IsEnabled(Debug) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	its.False(log.IsEnabled(Debug))
+	_, ok := log.ActiveOverride()
+	its.False(ok)
+}
+
+func TestLoggerApplyOverrideConcurrent(t *testing.T) {
+	its := assert.New(t)
+
+	buffer := new(bytes.Buffer)
+	log := Memory(buffer, OptFlags(NewFlags(Info)))
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for x := 0; x < 100; x++ {
+			log.ApplyOverride(Override{Flags: []string{Debug}, Scopes: []string{"worker"}})
+			log.RevertOverride()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for x := 0; x < 100; x++ {
+			log.WithPath("worker").Debugf("debug")
+			log.Infof("info")
+		}
+	}()
+	wg.Wait()
+	its.Equal(100, strings.Count(buffer.String(), "[info]"))
+}
@@ -0,0 +1,223 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FilterNameRateLimit is the filter name used for rate limit filters set from config.
+const FilterNameRateLimit = "rate_limit"
+
+// Rate limit defaults.
+const (
+	DefaultRateLimitBurst           = 10
+	DefaultRateLimitSummaryInterval = time.Minute
+)
+
+// DefaultRateLimitFlags are the flags that are rate limited if the rate limit flags are unset.
+var DefaultRateLimitFlags = []string{Debug, Info, Warning, Error}
+
+// NewRateLimiter returns a new rate limiter.
+func NewRateLimiter(log Triggerable, rate float64, burst int, summaryInterval time.Duration) *RateLimiter {
+	return &RateLimiter{
+		Log:             log,
+		Rate:            rate,
+		Burst:           burst,
+		SummaryInterval: summaryInterval,
+	}
+}
+
+// RateLimiter limits how often similar events are logged.
+//
+// Events are similar if they have the same flag, scope path and message; each
+// set of similar events has a token bucket that refills at `Rate` tokens per second
+// up to `Burst` tokens. Once per `SummaryInterval`, a message with the same flag and scope path
+// is triggered for each set of similar events that were dropped, e.g. "suppressed 1234 similar messages".
+// Summaries are triggered on a timer once events are dropped, so they are reported even if the events stop,
+// and by `Flush`, which loggers configured with `OptConfig` call when they are drained or closed.
+type RateLimiter struct {
+	// Log is where summaries are triggered.
+	Log Triggerable
+	// Rate is how many events per second are allowed for each set of similar events.
+	Rate float64
+	// Burst is how many events can be logged at once for each set of similar events.
+	Burst int
+	// SummaryInterval is how often summaries of dropped events are triggered.
+	SummaryInterval time.Duration
+	// NowProvider optionally returns the current time.
+	NowProvider func() time.Time
+
+	mu           sync.Mutex
+	buckets      map[uint64]*rateLimitBucket
+	lastSummary  time.Time
+	summaryTimer *time.Timer
+}
+
+type rateLimitBucket struct {
+	flag       string
+	path       []string
+	tokens     float64
+	updated    time.Time
+	suppressed int64
+}
+
+type rateLimitSkipKey struct{}
+
+// isRateLimitSkipped returns if the event is a summary that should not itself be sampled or rate limited.
+func isRateLimitSkipped(ctx context.Context) bool {
+	skip, _ := ctx.Value(rateLimitSkipKey{}).(bool)
+	return skip
+}
+
+// Filter implements `Filter`; it should be set for each flag that is rate limited.
+func (rl *RateLimiter) Filter(ctx context.Context, e Event) (Event, bool) {
+	if isRateLimitSkipped(ctx) {
+		return e, false
+	}
+	now := rl.now()
+	path := GetPath(ctx)
+	key := rateLimitKey(e, path)
+
+	rl.mu.Lock()
+	if rl.buckets == nil {
+		rl.buckets = make(map[uint64]*rateLimitBucket)
+		rl.lastSummary = now
+	}
+	bucket, ok := rl.buckets[key]
+	if !ok {
+		bucket = &rateLimitBucket{flag: e.GetFlag(), path: path, tokens: float64(rl.burst()), updated: now}
+		rl.buckets[key] = bucket
+	}
+	bucket.tokens += now.Sub(bucket.updated).Seconds() * rl.Rate
+	if burst := float64(rl.burst()); bucket.tokens > burst {
+		bucket.tokens = burst
+	}
+	bucket.updated = now
+
+	var drop bool
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+	} else {
+		bucket.suppressed++
+		drop = true
+		rl.scheduleSummaryUnsafe(now)
+	}
+	var summaries []func()
+	if rl.SummaryInterval > 0 && now.Sub(rl.lastSummary) >= rl.SummaryInterval {
+		summaries = rl.summariesUnsafe(now)
+	}
+	rl.mu.Unlock()
+
+	for _, summary := range summaries {
+		summary()
+	}
+	return e, drop
+}
+
+// Flush triggers summaries for any dropped events now, rather than waiting for the summary interval.
+func (rl *RateLimiter) Flush() {
+	rl.mu.Lock()
+	summaries := rl.summariesUnsafe(rl.now())
+	rl.mu.Unlock()
+
+	for _, summary := range summaries {
+		summary()
+	}
+}
+
+// flushDue triggers summaries from the timer if the summary interval has elapsed.
+func (rl *RateLimiter) flushDue() {
+	rl.mu.Lock()
+	rl.summaryTimer = nil
+	now := rl.now()
+	if now.Sub(rl.lastSummary) < rl.SummaryInterval {
+		rl.scheduleSummaryUnsafe(now)
+		rl.mu.Unlock()
+		return
+	}
+	summaries := rl.summariesUnsafe(now)
+	rl.mu.Unlock()
+
+	for _, summary := range summaries {
+		summary()
+	}
+}
+
+// scheduleSummaryUnsafe starts the summary timer if it isn't already running.
+func (rl *RateLimiter) scheduleSummaryUnsafe(now time.Time) {
+	if rl.SummaryInterval <= 0 || rl.summaryTimer != nil {
+		return
+	}
+	delay := rl.SummaryInterval - now.Sub(rl.lastSummary)
+	if delay < 0 {
+		delay = 0
+	}
+	rl.summaryTimer = time.AfterFunc(delay, rl.flushDue)
+}
+
+// summariesUnsafe returns the summaries for dropped events, resetting their counts.
+func (rl *RateLimiter) summariesUnsafe(now time.Time) (output []func()) {
+	rl.lastSummary = now
+	if rl.summaryTimer != nil {
+		rl.summaryTimer.Stop()
+		rl.summaryTimer = nil
+	}
+	for key, bucket := range rl.buckets {
+		if bucket.suppressed > 0 {
+			summaryCtx := context.WithValue(WithPath(context.Background(), bucket.path...), rateLimitSkipKey{}, true)
+			summary := NewMessageEvent(bucket.flag, fmt.Sprintf("suppressed %d similar messages", bucket.suppressed))
+			output = append(output, func() { MaybeTriggerContext(summaryCtx, rl.Log, summary) })
+			bucket.suppressed = 0
+		}
+		// buckets that have refilled are no different to new buckets, so they can be removed
+		if bucket.suppressed == 0 && bucket.tokens+now.Sub(bucket.updated).Seconds()*rl.Rate >= float64(rl.burst()) {
+			delete(rl.buckets, key)
+		}
+	}
+	return
+}
+
+func (rl *RateLimiter) burst() int {
+	if rl.Burst > 0 {
+		return rl.Burst
+	}
+	return DefaultRateLimitBurst
+}
+
+func (rl *RateLimiter) now() time.Time {
+	if rl.NowProvider != nil {
+		return rl.NowProvider()
+	}
+	return time.Now()
+}
+
+var rateLimitKeyFormatter = NewTextOutputFormatter(OptTextNoColor(), OptTextHideTimestamp())
+
+// rateLimitKey returns a hash of the flag, scope path and message of an event.
+func rateLimitKey(e Event, path []string) uint64 {
+	hash := fnv.New64a()
+	fmt.Fprint(hash, e.GetFlag(), "\x00", strings.Join(path, "/"), "\x00")
+	switch typed := e.(type) {
+	case MessageEvent:
+		fmt.Fprint(hash, typed.Text)
+	case ErrorEvent:
+		fmt.Fprint(hash, typed.Err)
+	case TextWritable:
+		buffer := new(bytes.Buffer)
+		typed.WriteText(rateLimitKeyFormatter, buffer)
+		_, _ = hash.Write(buffer.Bytes())
+	}
+	return hash.Sum64()
+}
@@ -0,0 +1,119 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	its := assert.New(t)
+
+	now := time.Date(2022, 01, 01, 12, 00, 00, 00, time.UTC)
+	buffer := new(bytes.Buffer)
+	log := Memory(buffer)
+	limiter := NewRateLimiter(log, 1, 2, time.Minute)
+	limiter.NowProvider = func() time.Time { return now }
+	log.Filter(Error, FilterNameRateLimit, limiter.Filter)
+
+	for x := 0; x < 10; x++ {
+		log.Errorf("hot loop")
+	}
+	log.Errorf("other message")
+	log.WithPath("sub").Errorf("hot loop")
+	its.Equal(3, strings.Count(buffer.String(), "[error] hot loop"))
+	its.Equal(1, strings.Count(buffer.String(), "[sub] [error] hot loop"))
+	its.Equal(1, strings.Count(buffer.String(), "other message"))
+
+	now = now.Add(time.Second)
+	buffer.Reset()
+	log.Errorf("hot loop")
+	log.Errorf("hot loop")
+	its.Equal(1, strings.Count(buffer.String(), "hot loop"), buffer.String())
+
+	now = now.Add(time.Minute)
+	buffer.Reset()
+	log.Errorf("hot loop")
+	its.Contains(buffer.String(), fmt.Sprintf("[error] suppressed %d similar messages", 9))
+	its.Equal(1, strings.Count(buffer.String(), "suppressed"))
+	its.Equal(1, strings.Count(buffer.String(), "[error] hot loop"))
+}
+
+func TestRateLimiterFlush(t *testing.T) {
+	its := assert.New(t)
+
+	now := time.Date(2022, 01, 01, 12, 00, 00, 00, time.UTC)
+	buffer := new(bytes.Buffer)
+	log := Memory(buffer)
+	limiter := NewRateLimiter(log, 1, 1, time.Hour)
+	limiter.NowProvider = func() time.Time { return now }
+	log.Filter(Error, FilterNameRateLimit, limiter.Filter)
+
+	for x := 0; x < 5; x++ {
+		log.Errorf("hot loop")
+	}
+	its.NotContains(buffer.String(), "suppressed")
+
+	limiter.Flush()
+	its.Contains(buffer.String(), "[error] suppressed 4 similar messages")
+
+	buffer.Reset()
+	limiter.Flush()
+	its.Empty(buffer.String())
+}
+
+func TestRateLimiterSummaryTimer(t *testing.T) {
+	its := assert.New(t)
+
+	log := Memory(new(bytes.Buffer))
+	defer log.Close()
+	limiter := NewRateLimiter(log, 0.001, 1, 10*time.Millisecond)
+	log.Filter(Error, FilterNameRateLimit, limiter.Filter)
+
+	summaries := make(chan string, 1)
+	log.Listen(Error, "summaries", NewMessageEventListener(func(_ context.Context, me MessageEvent) {
+		if strings.HasPrefix(me.Text, "suppressed") {
+			summaries <- me.Text
+		}
+	}))
+
+	// the flood stops after the first few events, but the summary is still reported.
+	for x := 0; x < 3; x++ {
+		log.Errorf("hot loop")
+	}
+	select {
+	case summary := <-summaries:
+		its.Equal("suppressed 2 similar messages", summary)
+	case <-time.After(5 * time.Second):
+		its.FailNow("the summary should be triggered by the timer")
+	}
+}
+
+func TestRateLimiterLoggerDrain(t *testing.T) {
+	its := assert.New(t)
+
+	buffer := new(bytes.Buffer)
+	log, err := New(
+		OptConfig(Config{RateLimit: RateLimitConfig{Rate: 0.001, Burst: 1, SummaryInterval: time.Hour}}),
+		OptOutput(buffer),
+		OptText(OptTextNoColor(), OptTextHideTimestamp()),
+	)
+	its.Nil(err)
+	for x := 0; x < 3; x++ {
+		log.Errorf("hot loop")
+	}
+	log.Drain()
+	its.Contains(buffer.String(), "[error] suppressed 2 similar messages")
+}
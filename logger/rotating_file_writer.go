@@ -0,0 +1,448 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+// RotatingFileWriter defaults.
+const (
+	// DefaultRotatingFileBackupTimeFormat is the time format used in backup file names.
+	DefaultRotatingFileBackupTimeFormat = "2006-01-02T15-04-05.000"
+	// DefaultRotatingFileMode is the file mode log files are created with.
+	DefaultRotatingFileMode os.FileMode = 0644
+)
+
+var (
+	_ io.WriteCloser = (*RotatingFileWriter)(nil)
+)
+
+// NewRotatingFileWriter opens a file at a given path for appending, creating it
+// (and its parent directories) if it doesn't exist.
+func NewRotatingFileWriter(path string, opts ...RotatingFileWriterOption) (*RotatingFileWriter, error) {
+	rfw := RotatingFileWriter{
+		Path: path,
+	}
+	for _, opt := range opts {
+		opt(&rfw)
+	}
+	if err := rfw.open(); err != nil {
+		return nil, err
+	}
+	if len(rfw.ReopenSignals) > 0 {
+		rfw.signals = make(chan os.Signal, 1)
+		rfw.stopped = make(chan struct{})
+		signal.Notify(rfw.signals, rfw.ReopenSignals...)
+		go rfw.reopenOnSignal(rfw.signals, rfw.stopped)
+	}
+	return &rfw, nil
+}
+
+// RotatingFileWriterOption mutates a rotating file writer.
+type RotatingFileWriterOption func(*RotatingFileWriter)
+
+// OptRotatingFileMaxSize sets the size in bytes a file can grow to before it is rotated.
+func OptRotatingFileMaxSize(maxSizeBytes int64) RotatingFileWriterOption {
+	return func(rfw *RotatingFileWriter) { rfw.MaxSize = maxSizeBytes }
+}
+
+// OptRotatingFileMaxAge sets how long after a file was started that it is rotated.
+func OptRotatingFileMaxAge(maxAge time.Duration) RotatingFileWriterOption {
+	return func(rfw *RotatingFileWriter) { rfw.MaxAge = maxAge }
+}
+
+// OptRotatingFileMaxBackups sets the maximum number of rotated files that are kept.
+func OptRotatingFileMaxBackups(maxBackups int) RotatingFileWriterOption {
+	return func(rfw *RotatingFileWriter) { rfw.MaxBackups = maxBackups }
+}
+
+// OptRotatingFileCompress sets if rotated files are compressed with gzip.
+func OptRotatingFileCompress(compress bool) RotatingFileWriterOption {
+	return func(rfw *RotatingFileWriter) { rfw.Compress = compress }
+}
+
+// OptRotatingFileReopenSignals sets the signals that cause the file to be reopened, e.g. `syscall.SIGHUP`.
+func OptRotatingFileReopenSignals(signals ...os.Signal) RotatingFileWriterOption {
+	return func(rfw *RotatingFileWriter) { rfw.ReopenSignals = signals }
+}
+
+// RotatingFileWriter is a writer to a file that is rotated by size and by age.
+//
+// Rotated files are renamed with a timestamp suffix, e.g. `app.log.2022-01-01T12-00-00.000`, with a sequence
+// number added if a backup with that timestamp exists (e.g. `app.log.2022-01-01T12-00-00.000-1`), optionally compressed (with a `.gz` extension), and the oldest are removed to keep at
+// most `MaxBackups`. The file can also be reopened (see `Reopen` and `ReopenSignals`) so that
+// it can be rotated externally, e.g. by logrotate.
+//
+// Rotated files are compressed in the background, so that writes are not blocked; `Close` waits
+// for them to be compressed, and returns any errors compressing them.
+//
+// Writes are serialized, so it is safe to use with or without an `InterlockedWriter`.
+type RotatingFileWriter struct {
+	// Path is the file path.
+	Path string
+	// MaxSize is the size in bytes a file can grow to before it is rotated; it is disabled if unset.
+	MaxSize int64
+	// MaxAge is how long after a file was started that it is rotated; it is disabled if unset.
+	//
+	// A file that has contents when it is opened, e.g. when the process restarts, was started
+	// when it was last rotated (by the timestamp of the newest backup), or else when it was last modified.
+	MaxAge time.Duration
+	// MaxBackups is the maximum number of rotated files that are kept; all are kept if unset.
+	MaxBackups int
+	// Compress sets if rotated files are compressed with gzip.
+	Compress bool
+	// ReopenSignals are signals that cause the file to be reopened.
+	ReopenSignals []os.Signal
+	// NowProvider optionally returns the current time.
+	NowProvider func() time.Time
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	signals  chan os.Signal
+	stopped  chan struct{}
+
+	compressMu  sync.Mutex
+	compressing sync.WaitGroup
+	compressErr error
+}
+
+// Write writes to the file, rotating it first if the write would exceed the max size or the file is older than the max age.
+//
+// If the rotation fails the contents are still written to the file at the path, and the rotation error is returned.
+func (rfw *RotatingFileWriter) Write(contents []byte) (int, error) {
+	rfw.mu.Lock()
+	defer rfw.mu.Unlock()
+
+	if rfw.file == nil {
+		return 0, ex.New(os.ErrClosed)
+	}
+	var rotateErr error
+	if rfw.shouldRotateUnsafe(int64(len(contents))) {
+		rotateErr = rfw.rotateUnsafe()
+		if rfw.file == nil {
+			return 0, rotateErr
+		}
+	}
+	written, err := rfw.file.Write(contents)
+	rfw.size += int64(written)
+	if err != nil {
+		return written, ex.New(err, ex.OptInner(rotateErr))
+	}
+	return written, rotateErr
+}
+
+// Rotate rotates the file.
+func (rfw *RotatingFileWriter) Rotate() error {
+	rfw.mu.Lock()
+	defer rfw.mu.Unlock()
+	return rfw.rotateUnsafe()
+}
+
+// Reopen closes and reopens the file at the path, e.g. after it was moved by an external tool.
+func (rfw *RotatingFileWriter) Reopen() error {
+	rfw.mu.Lock()
+	defer rfw.mu.Unlock()
+	if err := rfw.closeUnsafe(); err != nil {
+		return err
+	}
+	return rfw.openUnsafe()
+}
+
+// Close stops listening for reopen signals, closes the file, and waits for rotated files to be compressed.
+func (rfw *RotatingFileWriter) Close() error {
+	if rfw.signals != nil {
+		signal.Stop(rfw.signals)
+		rfw.mu.Lock()
+		if rfw.stopped != nil {
+			close(rfw.stopped)
+			rfw.stopped = nil
+		}
+		rfw.mu.Unlock()
+	}
+	rfw.mu.Lock()
+	err := rfw.closeUnsafe()
+	rfw.mu.Unlock()
+
+	rfw.compressing.Wait()
+	rfw.compressMu.Lock()
+	defer rfw.compressMu.Unlock()
+	err = ex.Append(err, rfw.compressErr)
+	rfw.compressErr = nil
+	return err
+}
+
+// Backups returns the paths of the rotated files, oldest first.
+func (rfw *RotatingFileWriter) Backups() ([]string, error) {
+	backups, err := rfw.backups()
+	if err != nil {
+		return nil, err
+	}
+	output := make([]string, 0, len(backups))
+	for _, backup := range backups {
+		output = append(output, backup.path)
+	}
+	return output, nil
+}
+
+type rotatingFileBackup struct {
+	path     string
+	rotated  time.Time
+	sequence int
+}
+
+// backups returns the rotated files, oldest first.
+func (rfw *RotatingFileWriter) backups() ([]rotatingFileBackup, error) {
+	matches, err := filepath.Glob(rfw.Path + ".*")
+	if err != nil {
+		return nil, ex.New(err)
+	}
+	var backups []rotatingFileBackup
+	for _, match := range matches {
+		if rotated, sequence, ok := parseBackupSuffix(strings.TrimPrefix(match, rfw.Path+".")); ok {
+			backups = append(backups, rotatingFileBackup{path: match, rotated: rotated, sequence: sequence})
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].rotated.Equal(backups[j].rotated) {
+			return backups[i].sequence < backups[j].sequence
+		}
+		return backups[i].rotated.Before(backups[j].rotated)
+	})
+	return backups, nil
+}
+
+// parseBackupSuffix parses the rotation time and sequence number from a backup file suffix,
+// e.g. `2022-01-01T12-00-00.000` or `2022-01-01T12-00-00.000-1.gz`.
+func parseBackupSuffix(suffix string) (rotated time.Time, sequence int, ok bool) {
+	suffix = strings.TrimSuffix(suffix, ".gz")
+	if len(suffix) > len(DefaultRotatingFileBackupTimeFormat) {
+		value := strings.TrimPrefix(suffix[len(DefaultRotatingFileBackupTimeFormat):], "-")
+		var err error
+		if sequence, err = strconv.Atoi(value); err != nil || sequence < 1 {
+			return
+		}
+		suffix = suffix[:len(DefaultRotatingFileBackupTimeFormat)]
+	}
+	rotated, err := time.Parse(DefaultRotatingFileBackupTimeFormat, suffix)
+	ok = err == nil
+	return
+}
+
+func (rfw *RotatingFileWriter) reopenOnSignal(signals <-chan os.Signal, stopped <-chan struct{}) {
+	for {
+		select {
+		case <-stopped:
+			return
+		case <-signals:
+			_ = rfw.Reopen()
+		}
+	}
+}
+
+func (rfw *RotatingFileWriter) shouldRotateUnsafe(size int64) bool {
+	if rfw.MaxSize > 0 && rfw.size > 0 && rfw.size+size > rfw.MaxSize {
+		return true
+	}
+	if rfw.MaxAge > 0 && rfw.now().Sub(rfw.openedAt) >= rfw.MaxAge {
+		return true
+	}
+	return false
+}
+
+// rotateUnsafe rotates the file, reopening the file at the path even if the rotation fails
+// so that later writes are not lost.
+func (rfw *RotatingFileWriter) rotateUnsafe() error {
+	err := rfw.rotateFileUnsafe()
+	if openErr := rfw.openUnsafe(); openErr != nil {
+		return ex.Append(err, openErr)
+	}
+	return err
+}
+
+func (rfw *RotatingFileWriter) rotateFileUnsafe() error {
+	if err := rfw.closeUnsafe(); err != nil {
+		return err
+	}
+	backup, err := rfw.backupPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(rfw.Path, backup); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return ex.New(err)
+	}
+	if rfw.Compress {
+		rfw.compressing.Add(1)
+		go rfw.compressBackup(backup)
+		return nil
+	}
+	return rfw.prune()
+}
+
+// compressBackup compresses a rotated file and then prunes the backups, without holding the write lock.
+//
+// Errors are returned by `Close`.
+func (rfw *RotatingFileWriter) compressBackup(backup string) {
+	defer rfw.compressing.Done()
+	rfw.compressMu.Lock()
+	defer rfw.compressMu.Unlock()
+	err := compressFile(backup)
+	if err == nil {
+		err = rfw.prune()
+	}
+	if err != nil {
+		rfw.compressErr = ex.Append(rfw.compressErr, err)
+	}
+}
+
+// backupPath returns the path to rotate the file to, adding a sequence number
+// if a backup with the same timestamp exists, e.g. from rotating twice in a millisecond.
+func (rfw *RotatingFileWriter) backupPath() (string, error) {
+	base := rfw.Path + "." + rfw.now().UTC().Format(DefaultRotatingFileBackupTimeFormat)
+	backup := base
+	for sequence := 1; ; sequence++ {
+		exists, err := backupExists(backup)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return backup, nil
+		}
+		backup = base + "-" + strconv.Itoa(sequence)
+	}
+}
+
+func backupExists(path string) (bool, error) {
+	for _, candidate := range []string{path, path + ".gz"} {
+		if _, err := os.Stat(candidate); err == nil {
+			return true, nil
+		} else if !os.IsNotExist(err) {
+			return false, ex.New(err)
+		}
+	}
+	return false, nil
+}
+
+func (rfw *RotatingFileWriter) prune() error {
+	if rfw.MaxBackups <= 0 {
+		return nil
+	}
+	backups, err := rfw.Backups()
+	if err != nil {
+		return err
+	}
+	for len(backups) > rfw.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil && !os.IsNotExist(err) {
+			return ex.New(err)
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+func (rfw *RotatingFileWriter) open() error {
+	rfw.mu.Lock()
+	defer rfw.mu.Unlock()
+	return rfw.openUnsafe()
+}
+
+func (rfw *RotatingFileWriter) openUnsafe() error {
+	if err := os.MkdirAll(filepath.Dir(rfw.Path), 0755); err != nil {
+		return ex.New(err)
+	}
+	file, err := os.OpenFile(rfw.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, DefaultRotatingFileMode)
+	if err != nil {
+		return ex.New(err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return ex.New(err)
+	}
+	rfw.file = file
+	rfw.size = info.Size()
+	rfw.openedAt = rfw.now()
+	if rfw.size > 0 {
+		rfw.openedAt = rfw.startedAt(info)
+	}
+	return nil
+}
+
+// startedAt returns when a file that has contents was started, i.e. when the newest backup
+// was rotated, or if there are no backups (or the newest is newer than the file), when it was last modified.
+func (rfw *RotatingFileWriter) startedAt(info os.FileInfo) time.Time {
+	startedAt := info.ModTime()
+	if backups, err := rfw.backups(); err == nil && len(backups) > 0 {
+		if rotated := backups[len(backups)-1].rotated; rotated.Before(startedAt) {
+			startedAt = rotated
+		}
+	}
+	return startedAt
+}
+
+func (rfw *RotatingFileWriter) closeUnsafe() error {
+	if rfw.file == nil {
+		return nil
+	}
+	err := rfw.file.Close()
+	rfw.file = nil
+	return ex.New(err)
+}
+
+func (rfw *RotatingFileWriter) now() time.Time {
+	if rfw.NowProvider != nil {
+		return rfw.NowProvider()
+	}
+	return time.Now()
+}
+
+// compressFile gzips a file, removing the original.
+func compressFile(path string) error {
+	source, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return ex.New(err)
+	}
+	defer source.Close()
+
+	destination, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, DefaultRotatingFileMode)
+	if err != nil {
+		return ex.New(err)
+	}
+	compressor := gzip.NewWriter(destination)
+	if _, err = io.Copy(compressor, source); err != nil {
+		_ = destination.Close()
+		return ex.New(err)
+	}
+	if err = compressor.Close(); err != nil {
+		_ = destination.Close()
+		return ex.New(err)
+	}
+	if err = destination.Close(); err != nil {
+		return ex.New(err)
+	}
+	return ex.New(os.Remove(path))
+}
@@ -0,0 +1,203 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/ex"
+)
+
+func TestRotatingFileWriterMaxSize(t *testing.T) {
+	its := assert.New(t)
+
+	now := time.Date(2022, 01, 01, 12, 00, 00, 00, time.UTC)
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	rfw, err := NewRotatingFileWriter(path, OptRotatingFileMaxSize(10), OptRotatingFileMaxBackups(2))
+	its.Nil(err)
+	defer rfw.Close()
+	rfw.NowProvider = func() time.Time { now = now.Add(time.Second); return now }
+
+	for _, line := range []string{"one\n", "two\n", "three\n", "four\n", "five\n", "six\n"} {
+		_, err = rfw.Write([]byte(line))
+		its.Nil(err)
+	}
+
+	backups, err := rfw.Backups()
+	its.Nil(err)
+	its.Len(backups, 2)
+	its.True(strings.HasPrefix(filepath.Base(backups[0]), "app.log.2022-01-01T12-00-"))
+
+	contents, err := os.ReadFile(backups[1])
+	its.Nil(err)
+	its.Equal("four\nfive\n", string(contents))
+	contents, err = os.ReadFile(path)
+	its.Nil(err)
+	its.Equal("six\n", string(contents))
+}
+
+func TestRotatingFileWriterMaxAgeCompress(t *testing.T) {
+	its := assert.New(t)
+
+	now := time.Date(2022, 01, 01, 12, 00, 00, 00, time.UTC)
+	path := filepath.Join(t.TempDir(), "app.log")
+	rfw, err := NewRotatingFileWriter(path,
+		OptRotatingFileMaxAge(time.Hour),
+		OptRotatingFileCompress(true),
+	)
+	its.Nil(err)
+	defer rfw.Close()
+	rfw.NowProvider = func() time.Time { return now }
+	its.Nil(rfw.Reopen())
+
+	_, err = rfw.Write([]byte("first\n"))
+	its.Nil(err)
+	now = now.Add(time.Hour)
+	_, err = rfw.Write([]byte("second\n"))
+	its.Nil(err)
+	// rotated files are compressed in the background
+	its.Nil(rfw.Close())
+
+	backups, err := rfw.Backups()
+	its.Nil(err)
+	its.Len(backups, 1)
+	its.Equal(path+".2022-01-01T13-00-00.000.gz", backups[0])
+
+	file, err := os.Open(backups[0])
+	its.Nil(err)
+	defer file.Close()
+	decompressor, err := gzip.NewReader(file)
+	its.Nil(err)
+	contents, err := io.ReadAll(decompressor)
+	its.Nil(err)
+	its.Equal("first\n", string(contents))
+}
+
+func TestRotatingFileWriterMaxAgeExisting(t *testing.T) {
+	its := assert.New(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	modified := time.Now().Add(-2 * time.Hour)
+	its.Nil(os.WriteFile(path, []byte("before restart\n"), 0644))
+	its.Nil(os.Chtimes(path, modified, modified))
+
+	// the age of an existing file is not reset when it is opened
+	rfw, err := NewRotatingFileWriter(path, OptRotatingFileMaxAge(time.Hour))
+	its.Nil(err)
+	_, err = rfw.Write([]byte("after restart\n"))
+	its.Nil(err)
+	its.Nil(rfw.Close())
+	backups, err := rfw.Backups()
+	its.Nil(err)
+	its.Len(backups, 1)
+	contents, err := os.ReadFile(backups[0])
+	its.Nil(err)
+	its.Equal("before restart\n", string(contents))
+
+	// a file last rotated over the max age ago is rotated, even if it was modified recently
+	its.Nil(os.Remove(backups[0]))
+	rotated := time.Now().Add(-2 * time.Hour).UTC()
+	its.Nil(os.WriteFile(path+"."+rotated.Format(DefaultRotatingFileBackupTimeFormat), nil, 0644))
+	rfw, err = NewRotatingFileWriter(path, OptRotatingFileMaxAge(time.Hour))
+	its.Nil(err)
+	_, err = rfw.Write([]byte("second restart\n"))
+	its.Nil(err)
+	its.Nil(rfw.Close())
+	backups, err = rfw.Backups()
+	its.Nil(err)
+	its.Len(backups, 2)
+	contents, err = os.ReadFile(path)
+	its.Nil(err)
+	its.Equal("second restart\n", string(contents))
+}
+
+func TestRotatingFileWriterReopen(t *testing.T) {
+	its := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	rfw, err := NewRotatingFileWriter(path)
+	its.Nil(err)
+
+	log := MustNew(OptAll(), OptOutput(nil), OptSink(NewTextOutputFormatter(OptTextNoColor(), OptTextHideTimestamp()), rfw))
+	log.Info("before")
+	its.Nil(os.Rename(path, path+".1"))
+	its.Nil(rfw.Reopen())
+	log.Info("after")
+	log.Close()
+
+	contents, err := os.ReadFile(path + ".1")
+	its.Nil(err)
+	its.Equal("[info] before\n", string(contents))
+	contents, err = os.ReadFile(path)
+	its.Nil(err)
+	its.Equal("[info] after\n", string(contents))
+
+	_, err = rfw.Write([]byte("closed"))
+	its.NotNil(err)
+}
+
+func TestRotatingFileWriterSameTimestamp(t *testing.T) {
+	its := assert.New(t)
+
+	now := time.Date(2022, 01, 01, 12, 00, 00, 00, time.UTC)
+	path := filepath.Join(t.TempDir(), "app.log")
+	rfw, err := NewRotatingFileWriter(path, OptRotatingFileMaxSize(4))
+	its.Nil(err)
+	defer rfw.Close()
+	rfw.NowProvider = func() time.Time { return now }
+
+	for _, line := range []string{"one\n", "two\n", "six\n", "ten\n"} {
+		_, err = rfw.Write([]byte(line))
+		its.Nil(err)
+	}
+
+	backups, err := rfw.Backups()
+	its.Nil(err)
+	its.Equal([]string{
+		path + ".2022-01-01T12-00-00.000",
+		path + ".2022-01-01T12-00-00.000-1",
+		path + ".2022-01-01T12-00-00.000-2",
+	}, backups)
+	for index, expected := range []string{"one\n", "two\n", "six\n"} {
+		contents, err := os.ReadFile(backups[index])
+		its.Nil(err)
+		its.Equal(expected, string(contents))
+	}
+}
+
+func TestRotatingFileWriterRotateFailed(t *testing.T) {
+	its := assert.New(t)
+
+	// backup names for a file name this long are over the file name limit, so rotating fails.
+	path := filepath.Join(t.TempDir(), strings.Repeat("a", 240)+".log")
+	rfw, err := NewRotatingFileWriter(path, OptRotatingFileMaxSize(8))
+	its.Nil(err)
+	defer rfw.Close()
+
+	_, err = rfw.Write([]byte("one\n"))
+	its.Nil(err)
+	_, err = rfw.Write([]byte("two\n"))
+	its.Nil(err)
+	written, err := rfw.Write([]byte("three\n"))
+	its.NotNil(err, "the rotation error should be returned")
+	its.Equal(6, written)
+	_, err = rfw.Write([]byte("four\n"))
+	its.False(ex.Is(err, os.ErrClosed))
+
+	contents, err := os.ReadFile(path)
+	its.Nil(err)
+	its.Equal("one\ntwo\nthree\nfour\n", string(contents))
+}
@@ -0,0 +1,59 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package logger
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+// FilterNameSample is the filter name used for sample filters set from config.
+const FilterNameSample = "sample"
+
+// ErrInvalidSampleRate is returned when a sample rate is not of the form `flag=N` for a positive N.
+const ErrInvalidSampleRate ex.Class = "logger; invalid sample rate, must be of the form `flag=N`"
+
+// NewSampleFilter returns a filter that passes 1 in every N events, starting with the first.
+//
+// A rate less than or equal to 1 passes every event. The filter keeps its own count, so a separate
+// filter should be created for each flag that is sampled.
+func NewSampleFilter(rate int) Filter {
+	var count uint64
+	return func(ctx context.Context, e Event) (Event, bool) {
+		if rate <= 1 || isRateLimitSkipped(ctx) {
+			return e, false
+		}
+		seen := atomic.AddUint64(&count, 1)
+		return e, (seen-1)%uint64(rate) != 0
+	}
+}
+
+// ParseSampleRates parses sample rates of the form `flag=N`, e.g. `debug=10`.
+func ParseSampleRates(values ...string) (map[string]int, error) {
+	output := make(map[string]int)
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		pieces := strings.SplitN(value, "=", 2)
+		if len(pieces) != 2 {
+			return nil, ex.New(ErrInvalidSampleRate, ex.OptMessagef("value: %s", value))
+		}
+		rate, err := strconv.Atoi(strings.TrimSpace(pieces[1]))
+		if err != nil || rate < 1 {
+			return nil, ex.New(ErrInvalidSampleRate, ex.OptMessagef("value: %s", value))
+		}
+		output[strings.TrimSpace(pieces[0])] = rate
+	}
+	return output, nil
+}
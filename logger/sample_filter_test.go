@@ -0,0 +1,51 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func TestSampleFilter(t *testing.T) {
+	its := assert.New(t)
+
+	buffer := new(bytes.Buffer)
+	log := Memory(buffer)
+	log.Filter(Debug, FilterNameSample, NewSampleFilter(3))
+
+	for x := 0; x < 7; x++ {
+		log.Debugf("debug %d", x)
+		log.Infof("info %d", x)
+	}
+	its.Equal(3, strings.Count(buffer.String(), "[debug]"))
+	its.Contains(buffer.String(), "debug 0")
+	its.Contains(buffer.String(), "debug 3")
+	its.Contains(buffer.String(), "debug 6")
+	its.Equal(7, strings.Count(buffer.String(), "[info]"))
+
+	_, filtered := NewSampleFilter(1)(context.Background(), NewMessageEvent(Info, "test"))
+	its.False(filtered)
+}
+
+func TestParseSampleRates(t *testing.T) {
+	its := assert.New(t)
+
+	rates, err := ParseSampleRates("debug=10", " info = 2 ", "")
+	its.Nil(err)
+	its.Equal(map[string]int{Debug: 10, Info: 2}, rates)
+
+	_, err = ParseSampleRates("debug=0")
+	its.NotNil(err)
+	_, err = ParseSampleRates("debug=foo")
+	its.NotNil(err)
+}
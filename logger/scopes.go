@@ -147,6 +147,18 @@ func (s Scopes) Scopes() []string {
 	return scopes
 }
 
+// Copy returns a copy of the scopes.
+func (s Scopes) Copy() *Scopes {
+	output := &Scopes{
+		all:    s.all,
+		scopes: make(map[string]bool, len(s.scopes)),
+	}
+	for key, value := range s.scopes {
+		output.scopes[key] = value
+	}
+	return output
+}
+
 //
 // internal helpers
 //
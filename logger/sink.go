@@ -0,0 +1,16 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package logger
+
+import "io"
+
+// Sink is an additional formatter and output that events are written to.
+type Sink struct {
+	Formatter WriteFormatter
+	Output    io.Writer
+}
@@ -8,6 +8,8 @@ Use of this source code is governed by a MIT license that can be found in the LI
 package logger
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"sort"
 	"strings"
@@ -30,3 +32,36 @@ func FormatLabels(tf TextFormatter, keyColor ansi.Color, labels Labels) string {
 	}
 	return strings.Join(values, " ")
 }
+
+// FormatEventText returns the text of an event without color, that is what
+// a text formatter writes after the timestamp, scope path and flag.
+func FormatEventText(e Event) string {
+	if typed, ok := e.(TextWritable); ok {
+		buffer := new(bytes.Buffer)
+		typed.WriteText(TextOutputFormatter{NoColor: true}, buffer)
+		return buffer.String()
+	}
+	if stringer, ok := e.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return ""
+}
+
+// getTracingIDs returns the trace and span ids set on a context
+// by `tracing.WithTraceAnnotations`, and the remaining annotations.
+func getTracingIDs(ctx context.Context) (traceID, spanID string, annotations Annotations) {
+	for key, value := range GetAnnotations(ctx) {
+		switch key {
+		case AnnotationTracingTraceID:
+			traceID = fmt.Sprint(value)
+		case AnnotationTracingSpanID:
+			spanID = fmt.Sprint(value)
+		default:
+			if annotations == nil {
+				annotations = make(Annotations)
+			}
+			annotations[key] = value
+		}
+	}
+	return
+}
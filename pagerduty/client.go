@@ -7,12 +7,17 @@ Use of this source code is governed by a MIT license that can be found in the LI
 
 package pagerduty
 
-import "context"
+import (
+	"context"
+
+	"github.com/blend/go-sdk/r2"
+)
 
 // Client is the interface pagerduty clients implement.
 type Client interface {
 	CreateIncident(context.Context, CreateIncidentInput) (Incident, error)
 	UpdateIncident(context.Context, string, UpdateIncidentInput) (Incident, error)
 	ListIncidents(context.Context, ...ListIncidentOption) (ListIncidentsOutput, error)
+	ListIncidentsPaginator(...ListIncidentOption) *r2.Paginator
 	GetService(context.Context, string) (Service, error)
 }
@@ -47,6 +47,48 @@ func (hc HTTPClient) ListIncidents(ctx context.Context, opts ...ListIncidentOpti
 	return
 }
 
+// ListAllIncidents lists incidents across all pages with a given client.
+//
+// Use the variadic options to set constraining query parameters to filter or sort the incidents;
+// `OptListIncidentsLimit` sets the page size and `OptListIncidentsOffset` the offset of the first page.
+func ListAllIncidents(ctx context.Context, client Client, opts ...ListIncidentOption) (output []Incident, err error) {
+	paginator := client.ListIncidentsPaginator(opts...)
+	for paginator.Next(ctx) {
+		var incident Incident
+		if err = paginator.Scan(&incident); err != nil {
+			return
+		}
+		output = append(output, incident)
+	}
+	err = paginator.Err()
+	return
+}
+
+// ListIncidentsPaginator returns a paginator that lazily lists incidents across all pages.
+//
+// Use the variadic options to set constraining query parameters to filter or sort the incidents;
+// `OptListIncidentsLimit` sets the page size and `OptListIncidentsOffset` the offset of the first page.
+func (hc HTTPClient) ListIncidentsPaginator(opts ...ListIncidentOption) *r2.Paginator {
+	var options ListIncidentsOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	strategy := &r2.OffsetPagination{
+		Offset:   options.Offset,
+		Limit:    options.Limit,
+		MorePath: "more",
+	}
+	// the offset and limit are set by the pagination strategy for each page
+	options.Offset, options.Limit = 0, 0
+	return r2.NewPaginator(func(ctx context.Context, pageOptions ...r2.Option) *r2.Request {
+		callOptions := append([]r2.Option{
+			r2.OptGet(),
+			r2.OptPath("/incidents"),
+		}, options.Options()...)
+		return hc.Request(ctx, append(callOptions, pageOptions...)...)
+	}, strategy, r2.OptPaginatorItemsPath("incidents"))
+}
+
 // OptListIncidentsDateRange sets a field on the options.
 func OptListIncidentsDateRange(dateRange string) ListIncidentOption {
 	return func(lio *ListIncidentsOptions) { lio.DateRange = dateRange }
@@ -163,7 +205,7 @@ func (lio ListIncidentsOptions) Options() (output []r2.Option) {
 		}
 	}
 	if lio.Limit > 0 {
-		output = append(output, r2.OptQueryValue("limit ", fmt.Sprint(lio.Limit)))
+		output = append(output, r2.OptQueryValue("limit", fmt.Sprint(lio.Limit)))
 	}
 	if lio.Offset > 0 {
 		output = append(output, r2.OptQueryValue("offset", fmt.Sprint(lio.Offset)))
@@ -0,0 +1,115 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package pagerduty
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/webutil"
+)
+
+// mockIncidentsServer serves the given number of incidents, paged by the offset and limit query parameters.
+func mockIncidentsServer(total int, queries *[]url.Values) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		*queries = append(*queries, query)
+		offset, _ := strconv.Atoi(query.Get("offset"))
+		limit, _ := strconv.Atoi(query.Get("limit"))
+		if limit == 0 {
+			limit = 25
+		}
+		output := ListIncidentsOutput{Offset: offset, Limit: limit}
+		for index := offset; index < total && index < offset+limit; index++ {
+			output.Incidents = append(output.Incidents, Incident{ID: fmt.Sprintf("incident-%d", index)})
+		}
+		output.More = offset+limit < total
+		_ = webutil.WriteJSON(rw, http.StatusOK, output)
+	}))
+}
+
+func TestListIncidents(t *testing.T) {
+	its := assert.New(t)
+
+	var queries []url.Values
+	server := mockIncidentsServer(5, &queries)
+	defer server.Close()
+
+	client := HTTPClient{Config: Config{Addr: server.URL, Token: "token"}}
+	output, err := client.ListIncidents(context.Background(),
+		OptListIncidentsLimit(2),
+		OptListIncidentsOffset(1),
+		OptListIncidentsStatuses(IncidentStatusTriggered, IncidentStatusAcknowledged),
+		OptListIncidentsTotal(true),
+	)
+	its.Nil(err)
+	its.Len(output.Incidents, 2)
+	its.Equal("incident-1", output.Incidents[0].ID)
+	its.True(output.More)
+
+	its.Len(queries, 1)
+	its.Equal("2", queries[0].Get("limit"))
+	its.Equal("1", queries[0].Get("offset"))
+	its.Equal([]string{"triggered", "acknowledged"}, queries[0]["statuses[]"])
+	its.Equal("true", queries[0].Get("total"))
+}
+
+func TestListAllIncidents(t *testing.T) {
+	its := assert.New(t)
+
+	var queries []url.Values
+	server := mockIncidentsServer(5, &queries)
+	defer server.Close()
+
+	client := HTTPClient{Config: Config{Addr: server.URL, Token: "token"}}
+	incidents, err := ListAllIncidents(context.Background(), client, OptListIncidentsLimit(2), OptListIncidentsSortBy("created_at"))
+	its.Nil(err)
+	its.Len(incidents, 5)
+	for index, incident := range incidents {
+		its.Equal(fmt.Sprintf("incident-%d", index), incident.ID)
+	}
+	its.Len(queries, 3)
+	for index, query := range queries {
+		its.Equal([]string{"0", "2", "4"}[index], query.Get("offset"))
+		its.Equal("2", query.Get("limit"))
+		its.Equal("created_at", query.Get("sort_by"))
+	}
+
+	queries = nil
+	incidents, err = ListAllIncidents(context.Background(), client, OptListIncidentsLimit(2), OptListIncidentsOffset(3))
+	its.Nil(err)
+	its.Len(incidents, 2)
+	its.Equal("incident-3", incidents[0].ID)
+	its.Len(queries, 1)
+}
+
+func TestListIncidentsPaginator(t *testing.T) {
+	its := assert.New(t)
+
+	var queries []url.Values
+	server := mockIncidentsServer(3, &queries)
+	defer server.Close()
+
+	client := HTTPClient{Config: Config{Addr: server.URL, Token: "token"}}
+	paginator := client.ListIncidentsPaginator(OptListIncidentsLimit(2))
+	var ids []string
+	for paginator.Next(context.Background()) {
+		var incident Incident
+		its.Nil(paginator.Scan(&incident))
+		ids = append(ids, incident.ID)
+	}
+	its.Nil(paginator.Err())
+	its.Equal([]string{"incident-0", "incident-1", "incident-2"}, ids)
+	its.Len(queries, 2)
+}
@@ -0,0 +1,218 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/blend/go-sdk/breaker"
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/logger"
+	"github.com/blend/go-sdk/stats"
+)
+
+// Circuit breaker constants.
+const (
+	// MetricNameCircuitBreakerState is the gauge that tracks the breaker state per host.
+	//
+	// The value is 0 for closed, 1 for half-open and 2 for open.
+	MetricNameCircuitBreakerState = FlagCircuitBreaker + ".state"
+	// MetricNameCircuitBreakerRejected is the count of requests rejected by an open breaker.
+	MetricNameCircuitBreakerRejected = FlagCircuitBreaker + ".rejected"
+	// TagCircuitBreakerHost is the stats tag for the breaker host.
+	TagCircuitBreakerHost = "url_hostname"
+)
+
+// DefaultCircuitBreakerFailureStatusCodes are the response status codes that count as failures by default.
+var DefaultCircuitBreakerFailureStatusCodes = []int{
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// ErrCircuitOpen is returned when a request is rejected because the breaker for its host is open.
+const ErrCircuitOpen ex.Class = "r2; circuit breaker open"
+
+// errCircuitBreakerFailureStatus marks a response status as a failure for the breaker.
+const errCircuitBreakerFailureStatus ex.Class = "r2; circuit breaker failure status"
+
+// ErrIsCircuitOpen returns if the error is an `ErrCircuitOpen`.
+func ErrIsCircuitOpen(err error) bool {
+	if ex.Is(err, ErrCircuitOpen) {
+		return true
+	}
+	if typed, ok := err.(*url.Error); ok {
+		return ex.Is(typed.Err, ErrCircuitOpen)
+	}
+	return false
+}
+
+// NewCircuitBreaker returns a new circuit breaker.
+func NewCircuitBreaker(opts ...CircuitBreakerOption) *CircuitBreaker {
+	cb := CircuitBreaker{
+		FailureStatusCodes: DefaultCircuitBreakerFailureStatusCodes,
+	}
+	for _, opt := range opts {
+		opt(&cb)
+	}
+	return &cb
+}
+
+// CircuitBreakerOption mutates a circuit breaker.
+type CircuitBreakerOption func(*CircuitBreaker)
+
+// OptCircuitBreakerConfig sets the config used for each host's breaker, replacing all of the breaker defaults.
+func OptCircuitBreakerConfig(cfg breaker.Config) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) { cb.Config = cfg }
+}
+
+// OptCircuitBreakerOptions sets additional options used for each host's breaker.
+func OptCircuitBreakerOptions(opts ...breaker.Option) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) { cb.BreakerOptions = append(cb.BreakerOptions, opts...) }
+}
+
+// OptCircuitBreakerFailureStatusCodes sets the response status codes that count as failures.
+func OptCircuitBreakerFailureStatusCodes(statusCodes ...int) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) { cb.FailureStatusCodes = statusCodes }
+}
+
+// OptCircuitBreakerLog sets the logger that breaker state changes are written to.
+func OptCircuitBreakerLog(log logger.Triggerable) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) { cb.Log = log }
+}
+
+// OptCircuitBreakerStats sets the stats collector that breaker state gauges are written to.
+func OptCircuitBreakerStats(collector stats.Collector) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) { cb.Stats = collector }
+}
+
+// CircuitBreaker holds a breaker per host and wraps transports so that
+// requests to a host fail fast while that host's breaker is open.
+//
+// A single circuit breaker should be shared between requests, typically
+// by passing it to `OptCircuitBreaker` as a default request option.
+type CircuitBreaker struct {
+	// Config is the config used for each host's breaker; if it is unset the breaker defaults are used.
+	Config breaker.Config
+	// BreakerOptions are additional options used for each host's breaker.
+	BreakerOptions []breaker.Option
+	// FailureStatusCodes are the response status codes that count as failures.
+	FailureStatusCodes []int
+	// Log is an optional logger that state changes are written to.
+	Log logger.Triggerable
+	// Stats is an optional collector that state gauges are written to.
+	Stats stats.Collector
+
+	mu       sync.Mutex
+	breakers map[string]*breaker.Breaker
+}
+
+// State returns the current state of the breaker for a given host.
+func (cb *CircuitBreaker) State(ctx context.Context, host string) breaker.State {
+	return cb.breaker(host).EvaluateState(ctx)
+}
+
+// IsFailureStatus returns if a given status code counts as a failure.
+func (cb *CircuitBreaker) IsFailureStatus(statusCode int) bool {
+	for _, failureStatusCode := range cb.FailureStatusCodes {
+		if failureStatusCode == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// RoundTripper returns a round tripper that wraps a given transport.
+//
+// If the transport is unset, `http.DefaultTransport` is used.
+func (cb *CircuitBreaker) RoundTripper(transport http.RoundTripper) http.RoundTripper {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &CircuitBreakerTransport{CircuitBreaker: cb, Transport: transport}
+}
+
+// breaker returns the breaker for a given host, creating it if it doesn't exist.
+func (cb *CircuitBreaker) breaker(host string) *breaker.Breaker {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.breakers == nil {
+		cb.breakers = make(map[string]*breaker.Breaker)
+	}
+	if b, ok := cb.breakers[host]; ok {
+		return b
+	}
+	var opts []breaker.Option
+	if cb.Config != (breaker.Config{}) {
+		opts = append(opts, breaker.OptConfig(cb.Config))
+	}
+	opts = append(opts, cb.BreakerOptions...)
+	opts = append(opts, breaker.OptOnStateChange(func(ctx context.Context, from, to breaker.State, _ int64) {
+		cb.onStateChange(ctx, host, from, to)
+	}))
+	b := breaker.New(opts...)
+	cb.breakers[host] = b
+	return b
+}
+
+func (cb *CircuitBreaker) onStateChange(ctx context.Context, host string, from, to breaker.State) {
+	if cb.Stats != nil {
+		_ = cb.Stats.Gauge(MetricNameCircuitBreakerState, float64(to), stats.Tag(TagCircuitBreakerHost, host))
+	}
+	logger.MaybeTriggerContext(ctx, cb.Log, NewCircuitBreakerEvent(host, from, to))
+}
+
+var (
+	_ http.RoundTripper = (*CircuitBreakerTransport)(nil)
+)
+
+// CircuitBreakerTransport is a round tripper that checks the
+// breaker for the request host before calling the wrapped transport.
+type CircuitBreakerTransport struct {
+	CircuitBreaker *CircuitBreaker
+	Transport      http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+//
+// Transport errors and responses with a failure status count against the host's breaker.
+// Responses are returned as is; if the breaker is open an `ErrCircuitOpen` is returned
+// without calling the wrapped transport.
+func (cbt *CircuitBreakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	var res *http.Response
+	_, err := cbt.CircuitBreaker.breaker(host).Intercept(breaker.ActionerFunc(func(_ context.Context, _ interface{}) (interface{}, error) {
+		var err error
+		res, err = cbt.Transport.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		if cbt.CircuitBreaker.IsFailureStatus(res.StatusCode) {
+			return nil, ex.New(errCircuitBreakerFailureStatus)
+		}
+		return nil, nil
+	})).Action(req.Context(), nil)
+	if err != nil {
+		if breaker.ErrIsOpen(err) || breaker.ErrIsTooManyRequests(err) {
+			if cbt.CircuitBreaker.Stats != nil {
+				_ = cbt.CircuitBreaker.Stats.Increment(MetricNameCircuitBreakerRejected, stats.Tag(TagCircuitBreakerHost, host))
+			}
+			return nil, ex.New(ErrCircuitOpen, ex.OptMessagef("host: %s", host), ex.OptInner(err))
+		}
+		if ex.Is(err, errCircuitBreakerFailureStatus) {
+			return res, nil
+		}
+		return nil, err
+	}
+	return res, nil
+}
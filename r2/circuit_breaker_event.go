@@ -0,0 +1,58 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/blend/go-sdk/breaker"
+	"github.com/blend/go-sdk/logger"
+)
+
+// FlagCircuitBreaker is a logger event flag for circuit breaker state changes.
+const FlagCircuitBreaker = "http.client.circuit_breaker"
+
+var (
+	_ logger.Event        = (*CircuitBreakerEvent)(nil)
+	_ logger.TextWritable = (*CircuitBreakerEvent)(nil)
+	_ logger.JSONWritable = (*CircuitBreakerEvent)(nil)
+)
+
+// NewCircuitBreakerEvent returns a new circuit breaker event.
+func NewCircuitBreakerEvent(host string, from, to breaker.State) CircuitBreakerEvent {
+	return CircuitBreakerEvent{
+		Host: host,
+		From: from,
+		To:   to,
+	}
+}
+
+// CircuitBreakerEvent is an event triggered when a host's breaker changes state.
+type CircuitBreakerEvent struct {
+	Host string
+	From breaker.State
+	To   breaker.State
+}
+
+// GetFlag implements logger.Event.
+func (e CircuitBreakerEvent) GetFlag() string { return FlagCircuitBreaker }
+
+// WriteText writes the event to a text writer.
+func (e CircuitBreakerEvent) WriteText(tf logger.TextFormatter, wr io.Writer) {
+	fmt.Fprintf(wr, "%s %s -> %s", e.Host, e.From.String(), e.To.String())
+}
+
+// Decompose implements logger.JSONWritable.
+func (e CircuitBreakerEvent) Decompose() map[string]interface{} {
+	return map[string]interface{}{
+		"host": e.Host,
+		"from": e.From.String(),
+		"to":   e.To.String(),
+	}
+}
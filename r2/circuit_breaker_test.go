@@ -0,0 +1,147 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/breaker"
+	"github.com/blend/go-sdk/logger"
+	"github.com/blend/go-sdk/stats"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	its := assert.New(t)
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	buf := new(bytes.Buffer)
+	log, err := logger.New(logger.OptOutput(buf), logger.OptText(logger.OptTextNoColor(), logger.OptTextHideTimestamp()), logger.OptAll())
+	its.Nil(err)
+	collector := stats.NewMockCollector(32)
+
+	cb := NewCircuitBreaker(
+		OptCircuitBreakerConfig(breaker.Config{
+			HalfOpenMaxActions:   breaker.DefaultHalfOpenMaxActions,
+			ClosedExpiryInterval: breaker.DefaultClosedExpiryInterval,
+			OpenExpiryInterval:   time.Hour,
+		}),
+		OptCircuitBreakerOptions(breaker.OptOpenFailureThreshold(1)),
+		OptCircuitBreakerLog(log),
+		OptCircuitBreakerStats(collector),
+	)
+
+	for x := 0; x < 2; x++ {
+		res, err := New(server.URL, OptCircuitBreaker(cb)).Discard()
+		its.Nil(err)
+		its.Equal(http.StatusServiceUnavailable, res.StatusCode)
+	}
+	its.Equal(2, atomic.LoadInt32(&calls))
+
+	serverURL, _ := url.Parse(server.URL)
+	its.Equal(breaker.StateOpen, cb.State(context.Background(), serverURL.Host))
+
+	_, err = New(server.URL, OptCircuitBreaker(cb)).Discard()
+	its.NotNil(err)
+	its.True(ErrIsCircuitOpen(err))
+	its.Equal(2, atomic.LoadInt32(&calls))
+
+	its.Equal(1, collector.GetCount(MetricNameCircuitBreakerState))
+	its.Equal(1, collector.GetCount(MetricNameCircuitBreakerRejected))
+	its.Contains(buf.String(), "[http.client.circuit_breaker] "+serverURL.Host+" closed -> open")
+}
+
+func TestCircuitBreakerFailureStatusCodes(t *testing.T) {
+	its := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cb := NewCircuitBreaker(
+		OptCircuitBreakerOptions(breaker.OptOpenFailureThreshold(0)),
+		OptCircuitBreakerFailureStatusCodes(http.StatusInternalServerError),
+	)
+	for x := 0; x < 3; x++ {
+		res, err := New(server.URL, OptCircuitBreaker(cb)).Discard()
+		its.Nil(err)
+		its.Equal(http.StatusServiceUnavailable, res.StatusCode)
+	}
+	serverURL, _ := url.Parse(server.URL)
+	its.Equal(breaker.StateClosed, cb.State(context.Background(), serverURL.Host))
+}
+
+func TestCircuitBreakerPerHost(t *testing.T) {
+	its := assert.New(t)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	healthy := mockServerOK()
+	defer healthy.Close()
+
+	cb := NewCircuitBreaker(OptCircuitBreakerOptions(breaker.OptOpenFailureThreshold(0)))
+
+	_, err := New(failing.URL, OptCircuitBreaker(cb)).Discard()
+	its.Nil(err)
+	_, err = New(failing.URL, OptCircuitBreaker(cb)).Discard()
+	its.True(ErrIsCircuitOpen(err))
+
+	res, err := New(healthy.URL, OptCircuitBreaker(cb)).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusOK, res.StatusCode)
+}
+
+func TestCircuitBreakerSharedClient(t *testing.T) {
+	its := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	const requests = 16
+	cb := NewCircuitBreaker(OptCircuitBreakerOptions(breaker.OptOpenFailureThreshold(requests)))
+	client := &http.Client{Transport: &http.Transport{}}
+	transport := client.Transport
+
+	// each failure should count once, however many requests share the client.
+	for x := 0; x < requests; x++ {
+		res, err := New(server.URL, OptClient(client), OptCircuitBreaker(cb)).Discard()
+		its.Nil(err)
+		its.Equal(http.StatusServiceUnavailable, res.StatusCode)
+	}
+	its.True(client.Transport == transport, "the shared client should not be mutated")
+
+	serverURL, _ := url.Parse(server.URL)
+	its.Equal(breaker.StateClosed, cb.State(context.Background(), serverURL.Host))
+	_, err := New(server.URL, OptClient(client), OptCircuitBreaker(cb)).Discard()
+	its.Nil(err)
+	its.Equal(breaker.StateOpen, cb.State(context.Background(), serverURL.Host))
+
+	// clients that already use the breaker are not wrapped again.
+	wrapped := &http.Client{Transport: cb.RoundTripper(transport)}
+	req := New(server.URL, OptClient(wrapped), OptCircuitBreaker(cb))
+	its.Nil(req.Err)
+	its.True(req.Client == wrapped)
+}
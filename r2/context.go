@@ -27,3 +27,21 @@ func GetParameterizedPath(ctx context.Context) string {
 	path, _ := ctx.Value(parameterizedPathKey{}).(string)
 	return path
 }
+
+type attemptKey struct{}
+
+// WithAttempt adds the attempt number of a request to a context.
+//
+// It is set on each attempt's request by `OptRetry`, starting at 1, and can be read by
+// `OnRequest` and `OnResponse` listeners and tracers with `GetAttempt`.
+func WithAttempt(ctx context.Context, attempt uint) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+// GetAttempt gets the attempt number of a request from a context.
+//
+// It returns 0 if the request was not sent with retries.
+func GetAttempt(ctx context.Context) uint {
+	attempt, _ := ctx.Value(attemptKey{}).(uint)
+	return attempt
+}
@@ -0,0 +1,217 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+// OAuth2 token source defaults.
+const (
+	// DefaultOAuth2ExpiryDelta is how long before a token expires that it is refreshed.
+	DefaultOAuth2ExpiryDelta = 30 * time.Second
+	// DefaultOAuth2FetchTimeout is how long a token fetch can take.
+	DefaultOAuth2FetchTimeout = 30 * time.Second
+)
+
+// ErrOAuth2TokenUnset is returned when a token fetcher returns neither a token nor an error.
+const ErrOAuth2TokenUnset ex.Class = "r2; oauth2 token fetcher returned an empty token"
+
+// OAuth2TokenFetcher fetches a new token.
+type OAuth2TokenFetcher func(context.Context) (*oauth2.Token, error)
+
+// NewOAuth2ClientCredentialsTokenSource returns a token source that fetches tokens
+// using the client credentials grant for a given config.
+func NewOAuth2ClientCredentialsTokenSource(cfg clientcredentials.Config) *OAuth2TokenSource {
+	return &OAuth2TokenSource{
+		Fetcher:      cfg.Token,
+		ExpiryDelta:  DefaultOAuth2ExpiryDelta,
+		FetchTimeout: DefaultOAuth2FetchTimeout,
+	}
+}
+
+// OAuth2TokenSource caches a token until shortly before it expires.
+//
+// Concurrent callers share a single refresh; while a token is fetched other
+// callers wait on the result rather than fetching their own. The fetch keeps the
+// values of the first caller's context but not its cancellation, so one cancelled
+// caller does not fail the others; it is bounded by the fetch timeout instead.
+type OAuth2TokenSource struct {
+	// Fetcher fetches a new token.
+	Fetcher OAuth2TokenFetcher
+	// ExpiryDelta is how long before a token expires that it is refreshed.
+	ExpiryDelta time.Duration
+	// FetchTimeout is how long a token fetch can take; it defaults to `DefaultOAuth2FetchTimeout`.
+	FetchTimeout time.Duration
+	// NowProvider optionally returns the current time.
+	NowProvider func() time.Time
+
+	mu    sync.Mutex
+	token *oauth2.Token
+	fetch *oauth2TokenFetch
+}
+
+// oauth2TokenFetch is the result of a token fetch shared by the callers waiting on it.
+type oauth2TokenFetch struct {
+	done  chan struct{}
+	token *oauth2.Token
+	err   error
+}
+
+// Token returns the cached token, fetching a new token if the cached token is unset or expiring.
+//
+// It returns early if the context is cancelled while waiting on a fetch.
+func (ts *OAuth2TokenSource) Token(ctx context.Context) (*oauth2.Token, error) {
+	ts.mu.Lock()
+	if ts.validUnsafe() {
+		token := ts.token
+		ts.mu.Unlock()
+		return token, nil
+	}
+	fetch := ts.fetch
+	if fetch == nil {
+		fetch = &oauth2TokenFetch{done: make(chan struct{})}
+		ts.fetch = fetch
+		go ts.doFetch(detachedContext{ctx}, fetch)
+	}
+	ts.mu.Unlock()
+
+	select {
+	case <-fetch.done:
+		return fetch.token, fetch.err
+	case <-ctx.Done():
+		return nil, ex.New(ctx.Err())
+	}
+}
+
+func (ts *OAuth2TokenSource) doFetch(ctx context.Context, fetch *oauth2TokenFetch) {
+	fetchTimeout := ts.FetchTimeout
+	if fetchTimeout <= 0 {
+		fetchTimeout = DefaultOAuth2FetchTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	token, err := ts.Fetcher(ctx)
+	if err != nil {
+		err = ex.New(err)
+	} else if token == nil {
+		err = ex.New(ErrOAuth2TokenUnset)
+	}
+
+	ts.mu.Lock()
+	if err == nil {
+		ts.token = token
+	} else {
+		token = nil
+	}
+	ts.fetch = nil
+	ts.mu.Unlock()
+
+	fetch.token, fetch.err = token, err
+	close(fetch.done)
+}
+
+// Invalidate clears the cached token if it is the given token.
+//
+// Passing the token that was rejected means that only one of several
+// concurrently rejected callers causes a refresh.
+func (ts *OAuth2TokenSource) Invalidate(token *oauth2.Token) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ts.token == token {
+		ts.token = nil
+	}
+}
+
+func (ts *OAuth2TokenSource) validUnsafe() bool {
+	if ts.token == nil || ts.token.AccessToken == "" {
+		return false
+	}
+	if ts.token.Expiry.IsZero() {
+		return true
+	}
+	return ts.now().Add(ts.ExpiryDelta).Before(ts.token.Expiry)
+}
+
+func (ts *OAuth2TokenSource) now() time.Time {
+	if ts.NowProvider != nil {
+		return ts.NowProvider()
+	}
+	return time.Now()
+}
+
+// detachedContext keeps the values of a context, but not its cancellation or deadline.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+var (
+	_ http.RoundTripper = (*OAuth2Transport)(nil)
+)
+
+// OAuth2Transport is a round tripper that sets the authorization header from a token source.
+//
+// If the remote responds with a 401, the token is invalidated and the request
+// is retried once with a new token, provided the request body can be replayed.
+type OAuth2Transport struct {
+	Source    *OAuth2TokenSource
+	Transport http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (ot *OAuth2Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := ot.Source.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	res, err := ot.Transport.RoundTrip(ot.authorize(req, token))
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusUnauthorized {
+		return res, nil
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return res, nil
+	}
+
+	ot.Source.Invalidate(token)
+	token, err = ot.Source.Token(req.Context())
+	if err != nil {
+		return res, nil
+	}
+	retry := ot.authorize(req, token)
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return res, nil
+		}
+	}
+	_ = res.Body.Close()
+	return ot.Transport.RoundTrip(retry)
+}
+
+// authorize returns a copy of the request with the authorization header set,
+// as round trippers should not modify the original request.
+func (ot *OAuth2Transport) authorize(req *http.Request, token *oauth2.Token) *http.Request {
+	copy := req.Clone(req.Context())
+	token.SetAuthHeader(copy)
+	return copy
+}
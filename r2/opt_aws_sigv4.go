@@ -0,0 +1,45 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+// OptAWSSigV4 signs the request with AWS signature version 4 for a given service
+// (e.g. `execute-api` or `es`), region and credentials.
+//
+// The signature is computed when the request is sent, after all other options have been applied.
+func OptAWSSigV4(service, region string, creds *credentials.Credentials) Option {
+	signer := v4.NewSigner(creds)
+	return OptOnRequest(func(req *http.Request) error {
+		body, err := readRequestBody(req)
+		if err != nil {
+			return err
+		}
+		var seeker io.ReadSeeker
+		if body != nil {
+			seeker = bytes.NewReader(body)
+		}
+		if _, err = signer.Sign(req, seeker, service, region, time.Now().UTC()); err != nil {
+			return ex.New(err)
+		}
+		if body != nil {
+			req.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		return nil
+	})
+}
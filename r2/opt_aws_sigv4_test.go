@@ -0,0 +1,49 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func TestOptAWSSigV4(t *testing.T) {
+	its := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		authorization := r.Header.Get("Authorization")
+		if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKID/") ||
+			!strings.Contains(authorization, "/us-east-1/execute-api/aws4_request") ||
+			r.Header.Get("X-Amz-Date") == "" ||
+			r.Header.Get("X-Amz-Security-Token") != "session-token" {
+			rw.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(rw, string(body))
+	}))
+	defer server.Close()
+
+	creds := credentials.NewStaticCredentials("AKID", "SECRET", "session-token")
+	contents, res, err := New(server.URL,
+		OptPost(),
+		OptBodyBytes([]byte("hello")),
+		OptAWSSigV4("execute-api", "us-east-1", creds),
+	).Bytes()
+	its.Nil(err)
+	its.Equal(http.StatusOK, res.StatusCode)
+	its.Equal("hello", string(contents))
+}
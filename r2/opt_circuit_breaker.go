@@ -0,0 +1,35 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"net/http"
+)
+
+// OptCircuitBreaker wraps the client transport with a given circuit breaker.
+//
+// The circuit breaker should be shared between requests so that per host state
+// is kept between calls. It wraps the transport that is set when the option is applied,
+// so it should be applied after any options that set or mutate the transport.
+//
+// The request client is copied before its transport is wrapped, so a client shared
+// between requests (e.g. with `OptClient`) is not wrapped again on every request.
+func OptCircuitBreaker(cb *CircuitBreaker) Option {
+	return func(r *Request) error {
+		if r.Client == nil {
+			r.Client = &http.Client{}
+		}
+		if typed, ok := r.Client.Transport.(*CircuitBreakerTransport); ok && typed.CircuitBreaker == cb {
+			return nil
+		}
+		client := *r.Client
+		client.Transport = cb.RoundTripper(client.Transport)
+		r.Client = &client
+		return nil
+	}
+}
@@ -0,0 +1,33 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/blend/go-sdk/webutil"
+)
+
+// OptHMACSignature signs the request with an hmac-sha256 signature of the canonical
+// form of the request, set to a given header.
+//
+// The `X-Signature-Timestamp` header is set to the current unix time each time the request is sent, including retries.
+// If the header name is unset, `X-Signature` is used; if the canonicalizer is unset,
+// `webutil.DefaultHMACCanonicalizer` is used. The signature is computed when the request is
+// sent, after all other options have been applied.
+func OptHMACSignature(key []byte, headerName string, canonicalizer webutil.HMACCanonicalizer) Option {
+	return OptOnRequest(func(req *http.Request) error {
+		body, err := readRequestBody(req)
+		if err != nil {
+			return err
+		}
+		webutil.SignRequestHMAC(req, body, key, headerName, canonicalizer, time.Now().UTC())
+		return nil
+	})
+}
@@ -0,0 +1,53 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/webutil"
+)
+
+func TestOptHMACSignature(t *testing.T) {
+	its := assert.New(t)
+
+	key := []byte("secret")
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := webutil.VerifyRequestHMAC(r, body, key, "X-Hub-Signature", nil, time.Minute, time.Now()); err != nil {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = rw.Write(body)
+	}))
+	defer server.Close()
+
+	contents, res, err := New(server.URL,
+		OptPost(),
+		OptPath("/hooks"),
+		OptQueryValue("type", "foo"),
+		OptHMACSignature(key, "X-Hub-Signature", nil),
+		OptBodyBytes([]byte(`{"foo":"bar"}`)),
+	).Bytes()
+	its.Nil(err)
+	its.Equal(http.StatusOK, res.StatusCode)
+	its.Equal(`{"foo":"bar"}`, string(contents))
+
+	res, err = New(server.URL,
+		OptPost(),
+		OptHMACSignature([]byte("not-secret"), "X-Hub-Signature", nil),
+		OptBodyBytes([]byte(`{"foo":"bar"}`)),
+	).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusUnauthorized, res.StatusCode)
+}
@@ -0,0 +1,48 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"net/http"
+
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// OptOAuth2ClientCredentials authorizes requests with tokens fetched using
+// the client credentials grant for a given config.
+//
+// The token source is created when the option is created, so create the option
+// once and reuse it (e.g. as a default option) to share cached tokens between requests.
+// It wraps the transport that is set when the option is applied,
+// so it should be applied after any options that set or mutate the transport.
+func OptOAuth2ClientCredentials(cfg clientcredentials.Config) Option {
+	return OptOAuth2TokenSource(NewOAuth2ClientCredentialsTokenSource(cfg))
+}
+
+// OptOAuth2TokenSource authorizes requests with tokens from a given token source.
+//
+// The request client is copied before its transport is wrapped, so a client shared
+// between requests (e.g. with `OptClient`) is not wrapped again on every request.
+func OptOAuth2TokenSource(source *OAuth2TokenSource) Option {
+	return func(r *Request) error {
+		if r.Client == nil {
+			r.Client = &http.Client{}
+		}
+		if typed, ok := r.Client.Transport.(*OAuth2Transport); ok && typed.Source == source {
+			return nil
+		}
+		client := *r.Client
+		transport := client.Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		client.Transport = &OAuth2Transport{Source: source, Transport: transport}
+		r.Client = &client
+		return nil
+	}
+}
@@ -0,0 +1,212 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func mockTokenServer(fetches *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		fetch := atomic.AddInt32(fetches, 1)
+		rw.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(rw, `{"access_token":"token-%d","token_type":"bearer","expires_in":3600}`, fetch)
+	}))
+}
+
+func TestOptOAuth2ClientCredentials(t *testing.T) {
+	its := assert.New(t)
+
+	var fetches int32
+	tokenServer := mockTokenServer(&fetches)
+	defer tokenServer.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(rw, r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	opt := OptOAuth2ClientCredentials(clientcredentials.Config{
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		TokenURL:     tokenServer.URL,
+	})
+
+	wg := sync.WaitGroup{}
+	for x := 0; x < 8; x++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			contents, _, err := New(server.URL, opt).Bytes()
+			its.Nil(err)
+			its.Equal("Bearer token-1", string(contents))
+		}()
+	}
+	wg.Wait()
+	its.Equal(1, atomic.LoadInt32(&fetches))
+}
+
+func TestOptOAuth2ClientCredentialsRetryUnauthorized(t *testing.T) {
+	its := assert.New(t)
+
+	var fetches int32
+	tokenServer := mockTokenServer(&fetches)
+	defer tokenServer.Close()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.Header.Get("Authorization") == "Bearer token-1" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body := make([]byte, 5)
+		_, _ = r.Body.Read(body)
+		fmt.Fprintf(rw, "%s %s", r.Header.Get("Authorization"), string(body))
+	}))
+	defer server.Close()
+
+	opt := OptOAuth2ClientCredentials(clientcredentials.Config{TokenURL: tokenServer.URL})
+	contents, res, err := New(server.URL, OptPost(), OptBodyBytes([]byte("hello")), opt).Bytes()
+	its.Nil(err)
+	its.Equal(http.StatusOK, res.StatusCode)
+	its.Equal("Bearer token-2 hello", string(contents))
+	its.Equal(2, atomic.LoadInt32(&fetches))
+	its.Equal(2, atomic.LoadInt32(&calls))
+}
+
+func TestOAuth2TokenSourceExpiry(t *testing.T) {
+	its := assert.New(t)
+
+	now := time.Date(2022, 01, 01, 12, 00, 00, 00, time.UTC)
+	var fetches int
+	ts := &OAuth2TokenSource{
+		Fetcher: func(_ context.Context) (*oauth2.Token, error) {
+			fetches++
+			return &oauth2.Token{AccessToken: fmt.Sprint(fetches), Expiry: now.Add(time.Minute)}, nil
+		},
+		ExpiryDelta: DefaultOAuth2ExpiryDelta,
+		NowProvider: func() time.Time { return now },
+	}
+
+	token, err := ts.Token(context.Background())
+	its.Nil(err)
+	its.Equal("1", token.AccessToken)
+
+	now = now.Add(20 * time.Second)
+	token, err = ts.Token(context.Background())
+	its.Nil(err)
+	its.Equal("1", token.AccessToken)
+
+	now = now.Add(20 * time.Second)
+	token, err = ts.Token(context.Background())
+	its.Nil(err)
+	its.Equal("2", token.AccessToken)
+
+	ts.Invalidate(&oauth2.Token{AccessToken: "2"})
+	token, err = ts.Token(context.Background())
+	its.Nil(err)
+	its.Equal("2", token.AccessToken)
+
+	ts.Invalidate(token)
+	token, err = ts.Token(context.Background())
+	its.Nil(err)
+	its.Equal("3", token.AccessToken)
+}
+
+func TestOptOAuth2TokenSourceSharedClient(t *testing.T) {
+	its := assert.New(t)
+
+	var fetches int32
+	tokenServer := mockTokenServer(&fetches)
+	defer tokenServer.Close()
+
+	var unauthorized int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if len(r.Header.Values("Authorization")) != 1 {
+			atomic.AddInt32(&unauthorized, 1)
+		}
+		fmt.Fprint(rw, r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &http.Transport{}}
+	transport := client.Transport
+	opt := OptOAuth2ClientCredentials(clientcredentials.Config{TokenURL: tokenServer.URL})
+	for x := 0; x < 16; x++ {
+		req := New(server.URL, OptClient(client), opt)
+		its.Nil(req.Err)
+		typed, ok := req.Client.Transport.(*OAuth2Transport)
+		its.True(ok)
+		its.True(typed.Transport == transport, "the transport should be wrapped once")
+
+		contents, _, err := req.Bytes()
+		its.Nil(err)
+		its.Equal("Bearer token-1", string(contents))
+	}
+	its.True(client.Transport == transport, "the shared client should not be mutated")
+	its.Zero(atomic.LoadInt32(&unauthorized))
+	its.Equal(1, atomic.LoadInt32(&fetches))
+}
+
+func TestOAuth2TokenSourceCancelledCaller(t *testing.T) {
+	its := assert.New(t)
+
+	release := make(chan struct{})
+	var fetches int32
+	ts := &OAuth2TokenSource{
+		Fetcher: func(ctx context.Context) (*oauth2.Token, error) {
+			atomic.AddInt32(&fetches, 1)
+			select {
+			case <-release:
+				return &oauth2.Token{AccessToken: "token"}, nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error)
+	go func() {
+		_, err := ts.Token(ctx)
+		cancelled <- err
+	}()
+
+	// wait for the first caller to start the fetch before cancelling it.
+	for atomic.LoadInt32(&fetches) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	its.NotNil(<-cancelled)
+
+	// the fetch continues for the callers still waiting.
+	waiting := make(chan *oauth2.Token)
+	go func() {
+		token, err := ts.Token(context.Background())
+		its.Nil(err)
+		waiting <- token
+	}()
+	close(release)
+	token := <-waiting
+	its.NotNil(token)
+	its.Equal("token", token.AccessToken)
+	its.Equal(1, atomic.LoadInt32(&fetches))
+}
@@ -0,0 +1,208 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/blend/go-sdk/retry"
+	"github.com/blend/go-sdk/webutil"
+)
+
+// Retry defaults.
+const (
+	DefaultRetryMaxAttempts   = 3
+	DefaultRetryDelay         = 100 * time.Millisecond
+	DefaultRetryMaxRetryAfter = 30 * time.Second
+)
+
+// DefaultRetryStatusCodes are the response status codes that are retried by default.
+var DefaultRetryStatusCodes = []int{
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+	http.StatusTooManyRequests,
+}
+
+// OptRetry sets the request to retry on connection errors and retryable status codes.
+//
+// Requests are only retried if their body can be replayed with `GetBody`. Requests that fail
+// to connect are always retried, as nothing was sent; otherwise only idempotent requests (see `IsIdempotent`)
+// are retried, since the server may already have received them. Retries that would wait past the
+// request context's deadline are not made, and the last attempt's result is returned instead. Each attempt is reported to the `OnRequest` and `OnResponse` listeners and the `Tracer`, with
+// the attempt number available from the request context with `GetAttempt`.
+func OptRetry(opts ...RetryOption) Option {
+	return func(r *Request) error {
+		policy := RetryPolicy{
+			MaxAttempts:   DefaultRetryMaxAttempts,
+			DelayProvider: retry.ExponentialBackoff(DefaultRetryDelay),
+			StatusCodes:   DefaultRetryStatusCodes,
+			MaxRetryAfter: DefaultRetryMaxRetryAfter,
+		}
+		for _, opt := range opts {
+			opt(&policy)
+		}
+		r.Retry = &policy
+		return nil
+	}
+}
+
+// RetryOption mutates a retry policy.
+type RetryOption func(*RetryPolicy)
+
+// OptRetryMaxAttempts sets the maximum number of attempts, including the first; zero is unbounded.
+func OptRetryMaxAttempts(maxAttempts uint) RetryOption {
+	return func(rp *RetryPolicy) { rp.MaxAttempts = maxAttempts }
+}
+
+// OptRetryDelayProvider sets the delay provider used between attempts.
+//
+// The delay provider is called with the zero based index of the retry.
+func OptRetryDelayProvider(delayProvider retry.DelayProvider) RetryOption {
+	return func(rp *RetryPolicy) { rp.DelayProvider = delayProvider }
+}
+
+// OptRetryStatusCodes sets the response status codes that are retried.
+func OptRetryStatusCodes(statusCodes ...int) RetryOption {
+	return func(rp *RetryPolicy) { rp.StatusCodes = statusCodes }
+}
+
+// OptRetryMaxRetryAfter caps how long a `Retry-After` response header can delay the next attempt; zero is uncapped.
+//
+// It defaults to `DefaultRetryMaxRetryAfter`.
+func OptRetryMaxRetryAfter(maxRetryAfter time.Duration) RetryOption {
+	return func(rp *RetryPolicy) { rp.MaxRetryAfter = maxRetryAfter }
+}
+
+// RetryPolicy governs how requests are retried.
+type RetryPolicy struct {
+	MaxAttempts   uint
+	DelayProvider retry.DelayProvider
+	StatusCodes   []int
+	MaxRetryAfter time.Duration
+}
+
+// CanRetry returns if a request can be retried at all, i.e. if its body can be replayed.
+func (rp RetryPolicy) CanRetry(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// ShouldRetry returns if an attempt's result should be retried.
+//
+// Failures to connect are retried for any request; other connection errors and
+// retryable status codes are only retried for idempotent requests.
+func (rp RetryPolicy) ShouldRetry(req *http.Request, res *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if err != nil {
+		return IsDialError(err) || (IsIdempotent(req) && IsConnectionError(err))
+	}
+	if res == nil || !IsIdempotent(req) {
+		return false
+	}
+	for _, statusCode := range rp.StatusCodes {
+		if res.StatusCode == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// Delay returns the delay before the next attempt, after a given (one based) attempt.
+//
+// A `Retry-After` header on the response takes precedence over the delay provider.
+func (rp RetryPolicy) Delay(ctx context.Context, attempt uint, res *http.Response) time.Duration {
+	if res != nil {
+		if retryAfter, ok := ParseRetryAfter(res.Header.Get(webutil.HeaderRetryAfter), time.Now()); ok {
+			if rp.MaxRetryAfter > 0 && retryAfter > rp.MaxRetryAfter {
+				return rp.MaxRetryAfter
+			}
+			return retryAfter
+		}
+	}
+	if rp.DelayProvider == nil {
+		return 0
+	}
+	return rp.DelayProvider(ctx, attempt-1)
+}
+
+// ParseRetryAfter parses a `Retry-After` header value, which is either a number of seconds or an http date.
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := date.Sub(now); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// IsIdempotent returns if a request can be safely sent more than once, i.e. if it is a GET, HEAD,
+// OPTIONS, TRACE, PUT or DELETE, or it has an `Idempotency-Key` header.
+func IsIdempotent(req *http.Request) bool {
+	if req.Header.Get(webutil.HeaderIdempotencyKey) != "" {
+		return true
+	}
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsDialError returns if an error is from failing to connect to a server, in which case nothing was sent.
+func IsDialError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// IsConnectionError returns if an error is from failing to connect to, or losing the connection to, a server.
+//
+// Unlike `IsDialError` the request may have been partially or fully sent when the connection was lost.
+func IsConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
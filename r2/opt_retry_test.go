@@ -0,0 +1,221 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/retry"
+	"github.com/blend/go-sdk/webutil"
+)
+
+func TestOptRetry(t *testing.T) {
+	its := assert.New(t)
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		if atomic.AddInt32(&calls, 1) < 3 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+		fmt.Fprint(rw, string(body))
+	}))
+	defer server.Close()
+
+	var requestAttempts, responseAttempts []uint
+	contents, res, err := New(server.URL,
+		OptPut(),
+		OptBodyBytes([]byte("hello")),
+		OptRetry(OptRetryDelayProvider(retry.ConstantDelay(time.Millisecond))),
+		OptOnRequest(func(req *http.Request) error {
+			requestAttempts = append(requestAttempts, GetAttempt(req.Context()))
+			return nil
+		}),
+		OptOnResponse(func(req *http.Request, _ *http.Response, _ time.Time, _ error) error {
+			responseAttempts = append(responseAttempts, GetAttempt(req.Context()))
+			return nil
+		}),
+	).Bytes()
+	its.Nil(err)
+	its.Equal(http.StatusOK, res.StatusCode)
+	its.Equal("hello", string(contents), "the body should be replayed on each attempt")
+	its.Equal(3, atomic.LoadInt32(&calls))
+	its.Equal([]uint{1, 2, 3}, requestAttempts)
+	its.Equal([]uint{1, 2, 3}, responseAttempts)
+}
+
+func TestOptRetryMaxAttempts(t *testing.T) {
+	its := assert.New(t)
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		rw.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	res, err := New(server.URL,
+		OptRetry(OptRetryMaxAttempts(2), OptRetryDelayProvider(retry.ConstantDelay(time.Millisecond))),
+	).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusTooManyRequests, res.StatusCode)
+	its.Equal(2, atomic.LoadInt32(&calls))
+}
+
+func TestOptRetryNonIdempotent(t *testing.T) {
+	its := assert.New(t)
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		rw.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	opts := []Option{
+		OptPost(),
+		OptBodyBytes([]byte("hello")),
+		OptRetry(OptRetryDelayProvider(retry.ConstantDelay(time.Millisecond))),
+	}
+	res, err := New(server.URL, opts...).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusBadGateway, res.StatusCode)
+	its.Equal(1, atomic.LoadInt32(&calls))
+
+	// posts with an idempotency key are retried.
+	atomic.StoreInt32(&calls, 0)
+	_, err = New(server.URL, append(opts, OptHeaderValue(webutil.HeaderIdempotencyKey, "key"))...).Discard()
+	its.Nil(err)
+	its.Equal(DefaultRetryMaxAttempts, atomic.LoadInt32(&calls))
+}
+
+func TestOptRetryConnectionError(t *testing.T) {
+	its := assert.New(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	its.Nil(err)
+	addr := listener.Addr().String()
+	its.Nil(listener.Close())
+
+	// posts that fail to connect are retried, as nothing was sent.
+	var attempts uint
+	_, err = New("http://"+addr,
+		OptPost(),
+		OptRetry(OptRetryDelayProvider(retry.ConstantDelay(time.Millisecond))),
+		OptOnRequest(func(req *http.Request) error {
+			attempts = GetAttempt(req.Context())
+			return nil
+		}),
+	).Discard()
+	its.NotNil(err)
+	its.True(IsDialError(err))
+	its.Equal(DefaultRetryMaxAttempts, attempts)
+}
+
+func TestOptRetryRetryAfter(t *testing.T) {
+	its := assert.New(t)
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			rw.Header().Set(webutil.HeaderRetryAfter, "1")
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// the retry after delay is past the context deadline, so the first response is returned without waiting.
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	res, err := New(server.URL,
+		OptContext(ctx),
+		OptRetry(OptRetryDelayProvider(retry.ConstantDelay(time.Millisecond))),
+	).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusServiceUnavailable, res.StatusCode)
+	its.True(time.Since(started) < 500*time.Millisecond)
+	its.Equal(1, atomic.LoadInt32(&calls))
+
+	atomic.StoreInt32(&calls, 0)
+	res, err = New(server.URL,
+		OptRetry(OptRetryMaxRetryAfter(time.Millisecond)),
+	).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusOK, res.StatusCode)
+	its.Equal(2, atomic.LoadInt32(&calls))
+}
+
+func TestOptRetryDefaultMaxRetryAfter(t *testing.T) {
+	its := assert.New(t)
+
+	req := New("http://localhost", OptRetry())
+	its.Nil(req.Err)
+	res := &http.Response{Header: http.Header{webutil.HeaderRetryAfter: []string{"3600"}}}
+	its.Equal(DefaultRetryMaxRetryAfter, req.Retry.Delay(context.Background(), 1, res))
+}
+
+func TestOptRetryConnectionLost(t *testing.T) {
+	its := assert.New(t)
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = io.ReadAll(req.Body)
+		conn, _, err := rw.(http.Hijacker).Hijack()
+		if err == nil {
+			_ = conn.Close()
+		}
+	}))
+	defer server.Close()
+
+	retryOpt := OptRetry(OptRetryDelayProvider(retry.ConstantDelay(time.Millisecond)))
+
+	// the server may have acted on a post before the connection was lost, so it isn't retried.
+	_, err := New(server.URL, OptPost(), OptBodyBytes([]byte("hello")), retryOpt).Discard()
+	its.NotNil(err)
+	its.True(IsConnectionError(err))
+	its.False(IsDialError(err))
+	its.Equal(1, atomic.LoadInt32(&calls))
+
+	atomic.StoreInt32(&calls, 0)
+	_, err = New(server.URL, OptPut(), OptBodyBytes([]byte("hello")), retryOpt).Discard()
+	its.NotNil(err)
+	its.Equal(DefaultRetryMaxAttempts, atomic.LoadInt32(&calls))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	its := assert.New(t)
+
+	now := time.Date(2022, 01, 02, 03, 04, 05, 0, time.UTC)
+	delay, ok := ParseRetryAfter("120", now)
+	its.True(ok)
+	its.Equal(2*time.Minute, delay)
+
+	delay, ok = ParseRetryAfter(now.Add(time.Minute).Format(http.TimeFormat), now)
+	its.True(ok)
+	its.Equal(time.Minute, delay)
+
+	_, ok = ParseRetryAfter("", now)
+	its.False(ok)
+	_, ok = ParseRetryAfter("soon", now)
+	its.False(ok)
+}
@@ -0,0 +1,208 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/webutil"
+)
+
+// Pagination defaults.
+const (
+	DefaultPaginationOffsetParam = "offset"
+	DefaultPaginationLimitParam  = "limit"
+	DefaultPaginationCursorParam = "cursor"
+)
+
+// ErrInvalidJSONPath is returned when a json path does not resolve within a page body.
+const ErrInvalidJSONPath ex.Class = "r2; json path does not resolve within page body"
+
+// PaginationStrategy determines how the pages of a paginated api are requested.
+type PaginationStrategy interface {
+	// First returns the request options for the first page.
+	First() []Option
+	// Next returns the request options for the page after a given page,
+	// or false if the given page is the last page.
+	Next(*Page) ([]Option, bool, error)
+}
+
+var (
+	_ PaginationStrategy = (*LinkHeaderPagination)(nil)
+	_ PaginationStrategy = (*CursorPagination)(nil)
+	_ PaginationStrategy = (*OffsetPagination)(nil)
+)
+
+// LinkHeaderPagination follows the `next` link of the `Link` response header (RFC 5988).
+type LinkHeaderPagination struct{}
+
+// First implements PaginationStrategy.
+func (lhp LinkHeaderPagination) First() []Option { return nil }
+
+// Next implements PaginationStrategy.
+func (lhp LinkHeaderPagination) Next(page *Page) ([]Option, bool, error) {
+	next, ok := webutil.FindLink(webutil.ParseLinkHeader(page.Response.Header.Values(webutil.HeaderLink)...), "next")
+	if !ok || next.URL == "" {
+		return nil, false, nil
+	}
+	nextURL, err := page.Response.Request.URL.Parse(next.URL)
+	if err != nil {
+		return nil, false, ex.New(err)
+	}
+	return []Option{OptURL(nextURL.String())}, true, nil
+}
+
+// CursorPagination reads a cursor from each page body and sets it as a query parameter for the next page.
+//
+// Iteration stops when the cursor is missing, null or empty.
+type CursorPagination struct {
+	// CursorPath is the dot separated path to the next cursor within the page body, e.g. `meta.next_cursor`.
+	CursorPath string
+	// CursorParam is the query parameter the cursor is set to; it defaults to `cursor`.
+	CursorParam string
+}
+
+// First implements PaginationStrategy.
+func (cp CursorPagination) First() []Option { return nil }
+
+// Next implements PaginationStrategy.
+func (cp CursorPagination) Next(page *Page) ([]Option, bool, error) {
+	cursor, err := jsonPathString(page.Body, cp.CursorPath)
+	if err != nil {
+		return nil, false, err
+	}
+	if cursor == "" {
+		return nil, false, nil
+	}
+	param := cp.CursorParam
+	if param == "" {
+		param = DefaultPaginationCursorParam
+	}
+	return []Option{OptQueryValue(param, cursor)}, true, nil
+}
+
+// OffsetPagination requests pages by offset and limit query parameters.
+//
+// If `MorePath` is set, iteration stops when the boolean at that path is false,
+// otherwise it stops when a page has fewer items than the limit (or no items if the limit is unset).
+type OffsetPagination struct {
+	// Offset is the offset of the first page.
+	Offset int
+	// Limit is the page size; if unset, the limit parameter is not sent.
+	Limit int
+	// OffsetParam is the offset query parameter; it defaults to `offset`.
+	OffsetParam string
+	// LimitParam is the limit query parameter; it defaults to `limit`.
+	LimitParam string
+	// MorePath is an optional dot separated path to a boolean within the page body
+	// that is true if there are more pages, e.g. `more`.
+	MorePath string
+
+	offset int
+}
+
+// First implements PaginationStrategy.
+func (op *OffsetPagination) First() []Option {
+	op.offset = op.Offset
+	return op.options()
+}
+
+// Next implements PaginationStrategy.
+func (op *OffsetPagination) Next(page *Page) ([]Option, bool, error) {
+	if len(page.Items) == 0 {
+		return nil, false, nil
+	}
+	if op.MorePath != "" {
+		more, err := jsonPathString(page.Body, op.MorePath)
+		if err != nil {
+			return nil, false, err
+		}
+		if more != "true" {
+			return nil, false, nil
+		}
+	} else if op.Limit > 0 && len(page.Items) < op.Limit {
+		return nil, false, nil
+	}
+	op.offset += len(page.Items)
+	return op.options(), true, nil
+}
+
+func (op *OffsetPagination) options() (output []Option) {
+	offsetParam, limitParam := op.OffsetParam, op.LimitParam
+	if offsetParam == "" {
+		offsetParam = DefaultPaginationOffsetParam
+	}
+	if limitParam == "" {
+		limitParam = DefaultPaginationLimitParam
+	}
+	output = append(output, OptQueryValue(offsetParam, strconv.Itoa(op.offset)))
+	if op.Limit > 0 {
+		output = append(output, OptQueryValue(limitParam, strconv.Itoa(op.Limit)))
+	}
+	return
+}
+
+// jsonPath returns the raw json at a dot separated path within a body.
+//
+// It returns nil if any element of the path is missing.
+func jsonPath(body []byte, path string) (json.RawMessage, error) {
+	current := json.RawMessage(body)
+	if path == "" {
+		return current, nil
+	}
+	for _, key := range strings.Split(path, ".") {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(current, &object); err != nil {
+			return nil, ex.New(ErrInvalidJSONPath, ex.OptMessagef("path: %s", path), ex.OptInner(err))
+		}
+		value, ok := object[key]
+		if !ok {
+			return nil, nil
+		}
+		current = value
+	}
+	return current, nil
+}
+
+// jsonPathItems returns the array at a dot separated path within a body.
+func jsonPathItems(body []byte, path string) ([]json.RawMessage, error) {
+	raw, err := jsonPath(body, path)
+	if err != nil || raw == nil {
+		return nil, err
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, ex.New(ErrInvalidJSONPath, ex.OptMessagef("path: %s", path), ex.OptInner(err))
+	}
+	return items, nil
+}
+
+// jsonPathString returns the scalar at a dot separated path within a body as a string.
+//
+// It returns an empty string if the path is missing or null.
+func jsonPathString(body []byte, path string) (string, error) {
+	raw, err := jsonPath(body, path)
+	if err != nil || raw == nil {
+		return "", err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return "", ex.New(ErrInvalidJSONPath, ex.OptMessagef("path: %s", path), ex.OptInner(err))
+	}
+	if value == nil {
+		return "", nil
+	}
+	return fmt.Sprint(value), nil
+}
@@ -0,0 +1,233 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+// ErrPageNon2xxStatus is returned when a page request returns a non-2xx status.
+const ErrPageNon2xxStatus ex.Class = "r2; page request returned a non-2xx status"
+
+// PageRequestBuilder returns the request for a page.
+//
+// The given options are provided by the pagination strategy (e.g. query values
+// or the url of the next page) and should be applied after any other options.
+type PageRequestBuilder func(ctx context.Context, opts ...Option) *Request
+
+// Page is a fetched page of results.
+type Page struct {
+	// Response is the page response; its body has already been read into `Body`.
+	Response *http.Response
+	// Body is the page response body.
+	Body []byte
+	// Items are the page items.
+	Items []json.RawMessage
+}
+
+// NewPaginator returns a new paginator.
+func NewPaginator(builder PageRequestBuilder, strategy PaginationStrategy, opts ...PaginatorOption) *Paginator {
+	p := Paginator{
+		Builder:  builder,
+		Strategy: strategy,
+	}
+	for _, opt := range opts {
+		opt(&p)
+	}
+	return &p
+}
+
+// PaginatorOption mutates a paginator.
+type PaginatorOption func(*Paginator)
+
+// OptPaginatorItemsPath sets the dot separated path to the items array within each page body, e.g. `data.items`.
+//
+// If unset, the page body itself is expected to be an array.
+func OptPaginatorItemsPath(path string) PaginatorOption {
+	return func(p *Paginator) { p.ItemsPath = path }
+}
+
+// OptPaginatorPrefetch sets if the next page should be fetched concurrently while the items of the current page are read.
+func OptPaginatorPrefetch(prefetch bool) PaginatorOption {
+	return func(p *Paginator) { p.Prefetch = prefetch }
+}
+
+// Paginator lazily iterates the items of a paginated api.
+//
+// Pages are only fetched as items are read, e.g.
+//
+//	p := r2.NewPaginator(builder, &r2.LinkHeaderPagination{})
+//	for p.Next(ctx) {
+//		var foo Foo
+//		if err := p.Scan(&foo); err != nil {
+//			return err
+//		}
+//		...
+//	}
+//	if err := p.Err(); err != nil {
+//		return err
+//	}
+//
+// A paginator is not safe to use from multiple goroutines.
+type Paginator struct {
+	// Builder returns the request for a page.
+	Builder PageRequestBuilder
+	// Strategy determines how the next page is requested.
+	Strategy PaginationStrategy
+	// ItemsPath is the dot separated path to the items array within each page body.
+	ItemsPath string
+	// Prefetch fetches the next page concurrently while the items of the current page are read.
+	Prefetch bool
+
+	started bool
+	last    *Page
+	pending <-chan pageResult
+	page    *Page
+	index   int
+	done    bool
+	err     error
+}
+
+type pageResult struct {
+	page *Page
+	err  error
+}
+
+// Next advances to the next item, fetching the next page if required.
+//
+// It returns false when there are no more items, when a page fails to be fetched,
+// or when the context is canceled; check `Err` to tell these apart.
+func (p *Paginator) Next(ctx context.Context) bool {
+	for {
+		if p.err != nil {
+			return false
+		}
+		if err := ctx.Err(); err != nil {
+			p.err = err
+			return false
+		}
+		if p.page != nil && p.index+1 < len(p.page.Items) {
+			p.index++
+			return true
+		}
+		if p.done {
+			return false
+		}
+		page, err := p.nextPage(ctx)
+		if err != nil {
+			p.err = err
+			return false
+		}
+		if page == nil {
+			p.done = true
+			return false
+		}
+		p.page, p.index = page, -1
+	}
+}
+
+// Item returns the current item.
+func (p *Paginator) Item() json.RawMessage {
+	if p.page == nil || p.index < 0 || p.index >= len(p.page.Items) {
+		return nil
+	}
+	return p.page.Items[p.index]
+}
+
+// Scan decodes the current item into a given value.
+func (p *Paginator) Scan(v interface{}) error {
+	return ex.New(json.Unmarshal(p.Item(), v))
+}
+
+// Page returns the page of the current item.
+func (p *Paginator) Page() *Page {
+	return p.page
+}
+
+// Err returns the error that stopped iteration, if any.
+func (p *Paginator) Err() error {
+	return p.err
+}
+
+func (p *Paginator) nextPage(ctx context.Context) (*Page, error) {
+	if p.pending == nil {
+		p.pending = p.request(ctx)
+	}
+	var result pageResult
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result = <-p.pending:
+	}
+	p.pending = nil
+	if result.err != nil || result.page == nil {
+		return nil, result.err
+	}
+	p.last = result.page
+	if p.Prefetch {
+		p.pending = p.request(ctx)
+	}
+	return result.page, nil
+}
+
+// request requests the page after the last fetched page, in the background if prefetching.
+func (p *Paginator) request(ctx context.Context) <-chan pageResult {
+	results := make(chan pageResult, 1)
+	var opts []Option
+	var ok bool
+	var err error
+	if !p.started {
+		p.started = true
+		opts, ok = p.Strategy.First(), true
+	} else {
+		opts, ok, err = p.Strategy.Next(p.last)
+	}
+	if err != nil || !ok {
+		results <- pageResult{err: err}
+		return results
+	}
+	if p.Prefetch {
+		go func() {
+			page, err := p.fetch(ctx, opts)
+			results <- pageResult{page: page, err: err}
+		}()
+		return results
+	}
+	page, err := p.fetch(ctx, opts)
+	results <- pageResult{page: page, err: err}
+	return results
+}
+
+func (p *Paginator) fetch(ctx context.Context, opts []Option) (*Page, error) {
+	res, err := p.Builder(ctx, opts...).Do()
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, ex.New(err)
+	}
+	if res.StatusCode < http.StatusOK || res.StatusCode > 299 {
+		return nil, ex.New(ErrPageNon2xxStatus, ex.OptMessagef("url: %s, status: %d", res.Request.URL.String(), res.StatusCode))
+	}
+	items, err := jsonPathItems(body, p.ItemsPath)
+	if err != nil {
+		return nil, err
+	}
+	return &Page{
+		Response: res,
+		Body:     body,
+		Items:    items,
+	}, nil
+}
@@ -0,0 +1,142 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/webutil"
+)
+
+type paginatorTestItem struct {
+	ID int `json:"id"`
+}
+
+func paginatorBuilder(server *httptest.Server) PageRequestBuilder {
+	return func(ctx context.Context, opts ...Option) *Request {
+		return New(server.URL, append([]Option{OptContext(ctx), OptPath("/items")}, opts...)...)
+	}
+}
+
+func paginatorIDs(its *assert.Assertions, ctx context.Context, p *Paginator) (output []int) {
+	for p.Next(ctx) {
+		var item paginatorTestItem
+		its.Nil(p.Scan(&item))
+		output = append(output, item.ID)
+	}
+	return
+}
+
+func TestPaginatorLinkHeader(t *testing.T) {
+	its := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 2 {
+			rw.Header().Set(webutil.HeaderLink, fmt.Sprintf(`</items?page=%d>; rel="next"`, page+1))
+		}
+		fmt.Fprintf(rw, `[{"id":%d},{"id":%d}]`, page*2, page*2+1)
+	}))
+	defer server.Close()
+
+	p := NewPaginator(paginatorBuilder(server), LinkHeaderPagination{})
+	its.Equal([]int{0, 1, 2, 3, 4, 5}, paginatorIDs(its, context.Background(), p))
+	its.Nil(p.Err())
+	its.False(p.Next(context.Background()))
+}
+
+func TestPaginatorCursor(t *testing.T) {
+	its := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("after") {
+		case "":
+			fmt.Fprint(rw, `{"data":{"items":[{"id":1},{"id":2}]},"meta":{"next":"abc"}}`)
+		case "abc":
+			fmt.Fprint(rw, `{"data":{"items":[]},"meta":{"next":"def"}}`)
+		case "def":
+			fmt.Fprint(rw, `{"data":{"items":[{"id":3}]},"meta":{"next":null}}`)
+		default:
+			rw.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	p := NewPaginator(paginatorBuilder(server),
+		CursorPagination{CursorPath: "meta.next", CursorParam: "after"},
+		OptPaginatorItemsPath("data.items"),
+	)
+	its.Equal([]int{1, 2, 3}, paginatorIDs(its, context.Background(), p))
+	its.Nil(p.Err())
+}
+
+func TestPaginatorOffset(t *testing.T) {
+	its := assert.New(t)
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		more := offset+limit < 7
+		var items []string
+		for x := offset; x < offset+limit && x < 7; x++ {
+			items = append(items, fmt.Sprintf(`{"id":%d}`, x))
+		}
+		fmt.Fprintf(rw, `{"more":%t,"things":[%s]}`, more, strings.Join(items, ","))
+	}))
+	defer server.Close()
+
+	p := NewPaginator(paginatorBuilder(server),
+		&OffsetPagination{Offset: 1, Limit: 3, MorePath: "more"},
+		OptPaginatorItemsPath("things"),
+		OptPaginatorPrefetch(true),
+	)
+	its.Equal([]int{1, 2, 3, 4, 5, 6}, paginatorIDs(its, context.Background(), p))
+	its.Nil(p.Err())
+	its.Equal(2, atomic.LoadInt32(&calls))
+
+	atomic.StoreInt32(&calls, 0)
+	p = NewPaginator(paginatorBuilder(server), &OffsetPagination{Limit: 4}, OptPaginatorItemsPath("things"))
+	its.Equal([]int{0, 1, 2, 3, 4, 5, 6}, paginatorIDs(its, context.Background(), p))
+	its.Equal(2, atomic.LoadInt32(&calls))
+}
+
+func TestPaginatorErrors(t *testing.T) {
+	its := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "1" {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		rw.Header().Set(webutil.HeaderLink, `</items?page=1>; rel="next"`)
+		fmt.Fprint(rw, `[{"id":1},{"id":2}]`)
+	}))
+	defer server.Close()
+
+	p := NewPaginator(paginatorBuilder(server), LinkHeaderPagination{})
+	its.Equal([]int{1, 2}, paginatorIDs(its, context.Background(), p))
+	its.True(ex.Is(p.Err(), ErrPageNon2xxStatus))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p = NewPaginator(paginatorBuilder(server), LinkHeaderPagination{})
+	its.True(p.Next(ctx))
+	cancel()
+	its.False(p.Next(ctx))
+	its.Equal(context.Canceled, p.Err())
+}
@@ -0,0 +1,92 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+// Cassette is a set of recorded http interactions.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions" yaml:"interactions"`
+}
+
+// Interaction is a recorded request and response pair.
+type Interaction struct {
+	Request  CassetteRequest  `json:"request" yaml:"request"`
+	Response CassetteResponse `json:"response" yaml:"response"`
+}
+
+// CassetteRequest is the recorded request of an interaction.
+type CassetteRequest struct {
+	Method  string      `json:"method" yaml:"method"`
+	URL     string      `json:"url" yaml:"url"`
+	Headers http.Header `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body    string      `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+// CassetteResponse is the recorded response of an interaction.
+type CassetteResponse struct {
+	StatusCode int         `json:"statusCode" yaml:"statusCode"`
+	Headers    http.Header `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body       string      `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+// ReadCassette reads a cassette from a given path.
+//
+// Files with a `.json` extension are read as json, otherwise they are read as yaml.
+func ReadCassette(path string) (*Cassette, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, ex.New(err)
+	}
+	var cassette Cassette
+	if isJSONCassette(path) {
+		err = json.Unmarshal(contents, &cassette)
+	} else {
+		err = yaml.Unmarshal(contents, &cassette)
+	}
+	if err != nil {
+		return nil, ex.New(ErrCassetteInvalid, ex.OptMessagef("path: %s", path), ex.OptInner(err))
+	}
+	return &cassette, nil
+}
+
+// WriteCassette writes a cassette to a given path, creating parent directories if required.
+//
+// Files with a `.json` extension are written as json, otherwise they are written as yaml.
+func WriteCassette(path string, cassette *Cassette) error {
+	var contents []byte
+	var err error
+	if isJSONCassette(path) {
+		contents, err = json.MarshalIndent(cassette, "", "  ")
+	} else {
+		contents, err = yaml.Marshal(cassette)
+	}
+	if err != nil {
+		return ex.New(err)
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			return ex.New(err)
+		}
+	}
+	return ex.New(os.WriteFile(path, contents, 0644))
+}
+
+func isJSONCassette(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".json")
+}
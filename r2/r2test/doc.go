@@ -31,5 +31,18 @@ During tests we can add a default option:
 	...
 
 We will now return the mocked response instead of reaching out to the remote for the call.
+
+For calls to third party APIs a `Recorder` can capture real interactions to a cassette file
+and serve them back during tests:
+
+	rec, err := r2test.NewRecorder("testdata/foos.yml", r2test.OptRecorderMode(r2test.ModeRecord))
+	...
+	defer rec.Close()
+	a := APIClient{ Remote: "https://api.example.com", Defaults: []r2.Option{rec.Option()} }
+
+Secrets such as the `Authorization` header are redacted with the `sanitize` package before
+they are written. Once recorded, omit the mode to replay the cassette, optionally with
+`r2test.OptRecorderStrict(true)` to fail on requests that don't match a recorded interaction.
+Cassettes with a `.json` extension are written as json, otherwise they are written as yaml.
 */
 package r2test // import "github.com/blend/go-sdk/r2/r2test"
@@ -0,0 +1,63 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2test
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// Matcher returns if a request matches a recorded request.
+//
+// The request body is passed separately as it has already been read.
+type Matcher func(req *http.Request, body []byte, recorded CassetteRequest) bool
+
+// MatchMethod matches on the request method.
+func MatchMethod(req *http.Request, _ []byte, recorded CassetteRequest) bool {
+	return req.Method == recorded.Method
+}
+
+// MatchURL matches on the request url.
+//
+// Query parameters are compared regardless of order.
+func MatchURL(req *http.Request, _ []byte, recorded CassetteRequest) bool {
+	recordedURL, err := url.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+	if req.URL.Scheme != recordedURL.Scheme || req.URL.Host != recordedURL.Host || req.URL.Path != recordedURL.Path {
+		return false
+	}
+	return req.URL.Query().Encode() == recordedURL.Query().Encode()
+}
+
+// MatchPath matches on the request url path, ignoring the scheme, host and query.
+func MatchPath(req *http.Request, _ []byte, recorded CassetteRequest) bool {
+	recordedURL, err := url.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+	return req.URL.Path == recordedURL.Path
+}
+
+// MatchBody matches on the request body.
+func MatchBody(_ *http.Request, body []byte, recorded CassetteRequest) bool {
+	return string(body) == recorded.Body
+}
+
+// MatchHeaders returns a matcher that matches on the values of the given headers.
+func MatchHeaders(headers ...string) Matcher {
+	return func(req *http.Request, _ []byte, recorded CassetteRequest) bool {
+		for _, header := range headers {
+			if req.Header.Get(header) != recorded.Headers.Get(header) {
+				return false
+			}
+		}
+		return true
+	}
+}
@@ -0,0 +1,403 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/r2"
+	"github.com/blend/go-sdk/sanitize"
+	"github.com/blend/go-sdk/webutil"
+)
+
+// Recorder errors.
+const (
+	// ErrCassetteInvalid is returned when a cassette file cannot be parsed.
+	ErrCassetteInvalid ex.Class = "r2test; cassette invalid"
+	// ErrCassetteNoMatch is returned in strict replay mode when a request does not match a recorded interaction.
+	ErrCassetteNoMatch ex.Class = "r2test; no recorded interaction matches request"
+)
+
+// ErrIsCassetteNoMatch returns if the error is an `ErrCassetteNoMatch`.
+func ErrIsCassetteNoMatch(err error) bool {
+	if ex.Is(err, ErrCassetteNoMatch) {
+		return true
+	}
+	if typed, ok := err.(*url.Error); ok {
+		return ex.Is(typed.Err, ErrCassetteNoMatch)
+	}
+	return false
+}
+
+// RecorderMode is the mode of a recorder.
+type RecorderMode int
+
+// RecorderMode values.
+const (
+	// ModeReplay serves responses from the cassette.
+	ModeReplay RecorderMode = iota
+	// ModeRecord calls the remote and records interactions to the cassette.
+	ModeRecord
+)
+
+// DefaultRedactedValue is the value redacted headers, query params and body values are replaced with.
+const DefaultRedactedValue = "[REDACTED]"
+
+var (
+	_ http.RoundTripper = (*Recorder)(nil)
+)
+
+// NewRecorder returns a new recorder for a given cassette path.
+//
+// In replay mode (the default) the cassette is read immediately; in record mode
+// interactions are written to the cassette when the recorder is closed.
+func NewRecorder(path string, opts ...RecorderOption) (*Recorder, error) {
+	rec := Recorder{
+		Path:      path,
+		Transport: http.DefaultTransport,
+		Matchers:  []Matcher{MatchMethod, MatchURL},
+		Sanitizer: sanitize.NewRequestSanitizer(
+			sanitize.OptRequestKeyValuesSanitizer(sanitize.KeyValuesSanitizerFunc(redactKeyValues)),
+		),
+	}
+	for _, opt := range opts {
+		opt(&rec)
+	}
+	if rec.Mode == ModeReplay {
+		cassette, err := ReadCassette(path)
+		if err != nil {
+			return nil, err
+		}
+		rec.Cassette = cassette
+	} else {
+		rec.Cassette = new(Cassette)
+	}
+	rec.used = make([]bool, len(rec.Cassette.Interactions))
+	return &rec, nil
+}
+
+// RecorderOption mutates a recorder.
+type RecorderOption func(*Recorder)
+
+// OptRecorderMode sets the recorder mode.
+func OptRecorderMode(mode RecorderMode) RecorderOption {
+	return func(rec *Recorder) { rec.Mode = mode }
+}
+
+// OptRecorderTransport sets the transport used to call the remote.
+func OptRecorderTransport(transport http.RoundTripper) RecorderOption {
+	return func(rec *Recorder) { rec.Transport = transport }
+}
+
+// OptRecorderMatchers sets the matchers a request must satisfy to match a recorded interaction.
+//
+// The default matchers are `MatchMethod` and `MatchURL`.
+func OptRecorderMatchers(matchers ...Matcher) RecorderOption {
+	return func(rec *Recorder) { rec.Matchers = matchers }
+}
+
+// OptRecorderSanitizer sets the sanitizer used to redact recorded requests.
+//
+// The disallowed headers are also redacted from recorded responses.
+func OptRecorderSanitizer(sanitizer sanitize.RequestSanitizer) RecorderOption {
+	return func(rec *Recorder) { rec.Sanitizer = sanitizer }
+}
+
+// OptRecorderBodySanitizer sets the sanitizer used to redact recorded request bodies.
+//
+// By default form and json bodies have the values of the sanitizer's disallowed query params redacted.
+func OptRecorderBodySanitizer(bodySanitizer BodySanitizer) RecorderOption {
+	return func(rec *Recorder) { rec.BodySanitizer = bodySanitizer }
+}
+
+// OptRecorderStrict sets if unmatched requests should fail in replay mode.
+func OptRecorderStrict(strict bool) RecorderOption {
+	return func(rec *Recorder) { rec.Strict = strict }
+}
+
+// BodySanitizer returns a request body with any secrets redacted.
+type BodySanitizer func(req *http.Request, body []byte) []byte
+
+// Recorder is a round tripper that records interactions to, or replays them from, a cassette.
+//
+// Requests are sanitized before they are recorded, and before they are matched
+// against recorded interactions, so redacted values still match on replay.
+type Recorder struct {
+	// Path is the cassette path.
+	Path string
+	// Mode is the recorder mode.
+	Mode RecorderMode
+	// Transport is the transport used to call the remote in record mode,
+	// or for unmatched requests in non-strict replay mode.
+	Transport http.RoundTripper
+	// Matchers determine if a request matches a recorded interaction.
+	Matchers []Matcher
+	// Sanitizer redacts secrets from recorded interactions.
+	Sanitizer sanitize.RequestSanitizer
+	// BodySanitizer redacts secrets from recorded request bodies; if unset
+	// form and json bodies have the sanitizer's disallowed query params redacted.
+	BodySanitizer BodySanitizer
+	// Strict causes unmatched requests to fail in replay mode
+	// instead of being passed through to the transport.
+	Strict bool
+	// Cassette holds the interactions.
+	Cassette *Cassette
+
+	mu   sync.Mutex
+	used []bool
+}
+
+// Option returns an r2 option that sets the client transport to the recorder.
+func (rec *Recorder) Option() r2.Option {
+	return r2.OptTransport(rec)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (rec *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	req, body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if rec.Mode == ModeRecord {
+		return rec.record(req, body)
+	}
+	return rec.replay(req, body)
+}
+
+// Save writes the cassette to the path.
+func (rec *Recorder) Save() error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return WriteCassette(rec.Path, rec.Cassette)
+}
+
+// Close saves the cassette if the recorder is in record mode.
+func (rec *Recorder) Close() error {
+	if rec.Mode == ModeRecord {
+		return rec.Save()
+	}
+	return nil
+}
+
+func (rec *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	res, err := rec.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, ex.New(err)
+	}
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+
+	sanitized := rec.Sanitizer.Sanitize(req)
+	interaction := Interaction{
+		Request: CassetteRequest{
+			Method:  sanitized.Method,
+			URL:     sanitized.URL.String(),
+			Headers: sanitized.Header,
+			Body:    string(rec.sanitizeBody(req, body)),
+		},
+		Response: CassetteResponse{
+			StatusCode: res.StatusCode,
+			Headers:    rec.sanitizeHeaders(res.Header),
+			Body:       string(resBody),
+		},
+	}
+
+	rec.mu.Lock()
+	rec.Cassette.Interactions = append(rec.Cassette.Interactions, interaction)
+	rec.used = append(rec.used, true)
+	rec.mu.Unlock()
+	return res, nil
+}
+
+func (rec *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	sanitized, sanitizedBody := rec.Sanitizer.Sanitize(req), rec.sanitizeBody(req, body)
+	rec.mu.Lock()
+	interaction, ok := rec.matchUnsafe(sanitized, sanitizedBody)
+	rec.mu.Unlock()
+	if !ok {
+		if rec.Strict {
+			return nil, ex.New(ErrCassetteNoMatch, ex.OptMessagef("%s %s", req.Method, req.URL.String()))
+		}
+		return rec.Transport.RoundTrip(req)
+	}
+
+	header := make(http.Header)
+	for key, values := range interaction.Response.Headers {
+		header[key] = append([]string(nil), values...)
+	}
+	return &http.Response{
+		Status:        http.StatusText(interaction.Response.StatusCode),
+		StatusCode:    interaction.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(interaction.Response.Body))),
+		ContentLength: int64(len(interaction.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// matchUnsafe returns the first unused interaction that matches the request,
+// falling back to the first used interaction that matches.
+func (rec *Recorder) matchUnsafe(req *http.Request, body []byte) (Interaction, bool) {
+	fallback := -1
+	for index, interaction := range rec.Cassette.Interactions {
+		if !rec.matches(req, body, interaction.Request) {
+			continue
+		}
+		if !rec.used[index] {
+			rec.used[index] = true
+			return interaction, true
+		}
+		if fallback < 0 {
+			fallback = index
+		}
+	}
+	if fallback >= 0 {
+		return rec.Cassette.Interactions[fallback], true
+	}
+	return Interaction{}, false
+}
+
+func (rec *Recorder) matches(req *http.Request, body []byte, recorded CassetteRequest) bool {
+	for _, matcher := range rec.Matchers {
+		if !matcher(req, body, recorded) {
+			return false
+		}
+	}
+	return true
+}
+
+func (rec *Recorder) sanitizeHeaders(headers http.Header) http.Header {
+	output := make(http.Header)
+	for key, values := range headers {
+		if rec.Sanitizer.IsHeaderDisallowed(key) && rec.Sanitizer.KeyValuesSanitizer != nil {
+			output[key] = rec.Sanitizer.KeyValuesSanitizer.SanitizeKeyValues(key, values...)
+			continue
+		}
+		output[key] = values
+	}
+	return output
+}
+
+func (rec *Recorder) sanitizeBody(req *http.Request, body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	if rec.BodySanitizer != nil {
+		return rec.BodySanitizer(req, body)
+	}
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get(webutil.HeaderContentType))
+	switch mediaType {
+	case webutil.ContentTypeApplicationFormEncoded:
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return body
+		}
+		var redacted bool
+		for key, keyValues := range values {
+			if rec.Sanitizer.IsQueryParamDisallowed(key) {
+				values[key] = redactKeyValues(key, keyValues...)
+				redacted = true
+			}
+		}
+		if redacted {
+			return []byte(values.Encode())
+		}
+	case "application/json":
+		var value interface{}
+		if err := json.Unmarshal(body, &value); err != nil {
+			return body
+		}
+		if rec.redactJSON(value) {
+			if output, err := json.Marshal(value); err == nil {
+				return output
+			}
+		}
+	}
+	return body
+}
+
+// redactJSON redacts the disallowed keys of any objects in a decoded json value, returning if any were redacted.
+func (rec *Recorder) redactJSON(value interface{}) (redacted bool) {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, child := range typed {
+			if rec.Sanitizer.IsQueryParamDisallowed(key) {
+				typed[key] = DefaultRedactedValue
+				redacted = true
+				continue
+			}
+			if rec.redactJSON(child) {
+				redacted = true
+			}
+		}
+	case []interface{}:
+		for _, child := range typed {
+			if rec.redactJSON(child) {
+				redacted = true
+			}
+		}
+	}
+	return
+}
+
+// readRequestBody reads the body of a request, and returns the request to pass to the transport.
+//
+// As a round tripper must not modify the request, the body is read through `GetBody` if it is set;
+// otherwise the body is read, and a clone of the request with a copy of the body is returned.
+func readRequestBody(req *http.Request) (*http.Request, []byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil, nil
+	}
+	if req.GetBody != nil {
+		bodyCopy, err := req.GetBody()
+		if err != nil {
+			_ = req.Body.Close()
+			return nil, nil, ex.New(err)
+		}
+		defer bodyCopy.Close()
+		body, err := io.ReadAll(bodyCopy)
+		if err != nil {
+			_ = req.Body.Close()
+			return nil, nil, ex.New(err)
+		}
+		return req, body, nil
+	}
+	defer req.Body.Close()
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, nil, ex.New(err)
+	}
+	clone := req.Clone(req.Context())
+	clone.Body = io.NopCloser(bytes.NewReader(body))
+	clone.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return clone, body, nil
+}
+
+func redactKeyValues(_ string, values ...string) []string {
+	output := make([]string, len(values))
+	for index := range values {
+		output[index] = DefaultRedactedValue
+	}
+	return output
+}
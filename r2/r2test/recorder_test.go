@@ -0,0 +1,228 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/r2"
+)
+
+func TestRecorderRecordReplay(t *testing.T) {
+	its := assert.New(t)
+
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		http.SetCookie(rw, &http.Cookie{Name: "session", Value: "secret-session"})
+		rw.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(rw, "%s %s %s", r.Method, r.URL.Path, string(body))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "fixtures", "cassette.yml")
+	rec, err := NewRecorder(path, OptRecorderMode(ModeRecord))
+	its.Nil(err)
+
+	contents, res, err := r2.New(server.URL,
+		r2.OptPath("/foos"),
+		r2.OptPost(),
+		r2.OptHeaderValue("Authorization", "Bearer secret-token"),
+		r2.OptBodyBytes([]byte("hello")),
+		rec.Option(),
+	).Bytes()
+	its.Nil(err)
+	its.Equal(http.StatusCreated, res.StatusCode)
+	its.Equal("POST /foos hello", string(contents))
+	its.Nil(rec.Close())
+	its.Equal(1, calls)
+
+	raw, err := os.ReadFile(path)
+	its.Nil(err)
+	its.NotContains(string(raw), "secret-token")
+	its.NotContains(string(raw), "secret-session")
+	its.Contains(string(raw), DefaultRedactedValue)
+
+	replay, err := NewRecorder(path, OptRecorderStrict(true))
+	its.Nil(err)
+	contents, res, err = r2.New(server.URL,
+		r2.OptPath("/foos"),
+		r2.OptPost(),
+		r2.OptBodyBytes([]byte("hello")),
+		replay.Option(),
+	).Bytes()
+	its.Nil(err)
+	its.Equal(http.StatusCreated, res.StatusCode)
+	its.Equal("POST /foos hello", string(contents))
+	its.Equal(1, calls)
+}
+
+func TestRecorderRequestUnmodified(t *testing.T) {
+	its := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = rw.Write(body)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.yml")
+	rec, err := NewRecorder(path, OptRecorderMode(ModeRecord))
+	its.Nil(err)
+
+	// a body without `GetBody` is read and sent with a clone of the request
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/foos", io.MultiReader(strings.NewReader("hello")))
+	its.Nil(err)
+	body := req.Body
+	res, err := rec.RoundTrip(req)
+	its.Nil(err)
+	contents, err := io.ReadAll(res.Body)
+	its.Nil(err)
+	its.Equal("hello", string(contents))
+	its.True(body == req.Body)
+	its.Nil(req.GetBody)
+	its.Nil(rec.Close())
+
+	// a body with `GetBody` is read through it
+	replay, err := NewRecorder(path, OptRecorderStrict(true))
+	its.Nil(err)
+	req, err = http.NewRequest(http.MethodPost, server.URL+"/foos", strings.NewReader("hello"))
+	its.Nil(err)
+	res, err = replay.RoundTrip(req)
+	its.Nil(err)
+	contents, err = io.ReadAll(res.Body)
+	its.Nil(err)
+	its.Equal("hello", string(contents))
+	contents, err = io.ReadAll(req.Body)
+	its.Nil(err)
+	its.Equal("hello", string(contents))
+}
+
+func TestRecorderReplayStrict(t *testing.T) {
+	its := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	its.Nil(WriteCassette(path, &Cassette{
+		Interactions: []Interaction{
+			{
+				Request:  CassetteRequest{Method: http.MethodGet, URL: "http://test.invalid/foos?b=2&a=1"},
+				Response: CassetteResponse{StatusCode: http.StatusOK, Body: "first"},
+			},
+			{
+				Request:  CassetteRequest{Method: http.MethodGet, URL: "http://test.invalid/foos?a=1&b=2"},
+				Response: CassetteResponse{StatusCode: http.StatusOK, Body: "second"},
+			},
+		},
+	}))
+
+	rec, err := NewRecorder(path, OptRecorderStrict(true))
+	its.Nil(err)
+	its.Len(rec.Cassette.Interactions, 2)
+
+	for _, expected := range []string{"first", "second", "first"} {
+		contents, _, err := r2.New("http://test.invalid/foos?a=1&b=2", rec.Option()).Bytes()
+		its.Nil(err)
+		its.Equal(expected, string(contents))
+	}
+
+	_, err = r2.New("http://test.invalid/bars", rec.Option()).Discard()
+	its.NotNil(err)
+	its.True(ErrIsCassetteNoMatch(err))
+}
+
+func TestRecorderMatchers(t *testing.T) {
+	its := assert.New(t)
+
+	recorded := CassetteRequest{
+		Method:  http.MethodPost,
+		URL:     "http://test.invalid/foos",
+		Headers: http.Header{"X-Tenant": []string{"one"}},
+		Body:    "hello",
+	}
+	req, _ := http.NewRequest(http.MethodPost, "https://other.invalid/foos", nil)
+	req.Header.Set("X-Tenant", "one")
+
+	its.True(MatchMethod(req, nil, recorded))
+	its.False(MatchURL(req, nil, recorded))
+	its.True(MatchPath(req, nil, recorded))
+	its.True(MatchBody(req, []byte("hello"), recorded))
+	its.False(MatchBody(req, []byte("goodbye"), recorded))
+	its.True(MatchHeaders("X-Tenant")(req, nil, recorded))
+	req.Header.Set("X-Tenant", "two")
+	its.False(MatchHeaders("X-Tenant")(req, nil, recorded))
+}
+
+func TestReadCassetteMissing(t *testing.T) {
+	its := assert.New(t)
+
+	_, err := NewRecorder(filepath.Join(t.TempDir(), "missing.yml"))
+	its.NotNil(err)
+}
+
+func TestRecorderReplayRedacted(t *testing.T) {
+	its := assert.New(t)
+
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+		rw.WriteHeader(http.StatusOK)
+		fmt.Fprint(rw, "token")
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.yml")
+	rec, err := NewRecorder(path, OptRecorderMode(ModeRecord))
+	its.Nil(err)
+
+	options := func(recorder *Recorder) []r2.Option {
+		return []r2.Option{
+			r2.OptPath("/oauth/token"),
+			r2.OptQueryValue("access_token", "secret-access-token"),
+			r2.OptPostFormValue("grant_type", "client_credentials"),
+			r2.OptPostFormValue("client_secret", "secret-client-secret"),
+			recorder.Option(),
+		}
+	}
+	_, err = r2.New(server.URL, options(rec)...).Discard()
+	its.Nil(err)
+	_, err = r2.New(server.URL, append(options(rec), r2.OptJSONBody(map[string]interface{}{
+		"grant_type":    "client_credentials",
+		"client_secret": "secret-client-secret",
+	}))...).Discard()
+	its.Nil(err)
+	its.Nil(rec.Close())
+	its.Equal(2, calls)
+
+	raw, err := os.ReadFile(path)
+	its.Nil(err)
+	its.NotContains(string(raw), "secret-access-token")
+	its.NotContains(string(raw), "secret-client-secret")
+	its.Contains(string(raw), "client_credentials")
+
+	// requests with redacted values should still match their recorded interactions.
+	replay, err := NewRecorder(path, OptRecorderStrict(true), OptRecorderMatchers(MatchMethod, MatchURL, MatchBody))
+	its.Nil(err)
+	contents, _, err := r2.New(server.URL, options(replay)...).Bytes()
+	its.Nil(err)
+	its.Equal("token", string(contents))
+	_, err = r2.New(server.URL, append(options(replay), r2.OptJSONBody(map[string]interface{}{
+		"grant_type":    "client_credentials",
+		"client_secret": "secret-client-secret",
+	}))...).Discard()
+	its.Nil(err)
+	its.Equal(2, calls)
+}
@@ -0,0 +1,41 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+// readRequestBody reads the body of a request so that it can be signed,
+// leaving the request with a body (and `GetBody`) that can be read again.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	var body io.ReadCloser = req.Body
+	if req.GetBody != nil {
+		var err error
+		if body, err = req.GetBody(); err != nil {
+			return nil, ex.New(err)
+		}
+	}
+	defer body.Close()
+	contents, err := io.ReadAll(body)
+	if err != nil {
+		return nil, ex.New(err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(contents))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(contents)), nil
+	}
+	return contents, nil
+}
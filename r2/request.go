@@ -68,6 +68,8 @@ type Request struct {
 	OnRequest []OnRequestListener
 	// OnResponse is an array of response lifecycle hooks used typically for logging.
 	OnResponse []OnResponseListener
+	// Retry is an optional policy to retry failed requests; it is typically set with `OptRetry`.
+	Retry *RetryPolicy
 }
 
 // WithContext implements the `WithContext` method for the underlying request.
@@ -88,10 +90,10 @@ func (r Request) Do() (*http.Response, error) {
 	}
 
 	if len(r.Request.PostForm) > 0 && r.Request.Body == nil {
-		body := r.Request.PostForm.Encode()
-		buffer := bytes.NewBufferString(body)
-		r.Request.ContentLength = int64(buffer.Len())
-		r.Request.Body = io.NopCloser(buffer)
+		body := []byte(r.Request.PostForm.Encode())
+		r.Request.ContentLength = int64(len(body))
+		r.Request.Body = io.NopCloser(bytes.NewReader(body))
+		r.Request.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
 	}
 
 	if r.Request.Body == nil {
@@ -99,37 +101,84 @@ func (r Request) Do() (*http.Response, error) {
 		r.Request.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(http.NoBody), nil }
 	}
 
+	if r.Retry == nil {
+		res, _, err := r.attempt(r.Request)
+		return res, err
+	}
+	return r.doWithRetry()
+}
+
+// doWithRetry sends the request with the retry policy.
+func (r Request) doWithRetry() (*http.Response, error) {
+	ctx := r.Request.Context()
+	canRetry := r.Retry.CanRetry(r.Request)
+	var alarm *time.Timer
+	for attempt := uint(1); ; attempt++ {
+		req := r.Request.WithContext(WithAttempt(ctx, attempt))
+		if attempt > 1 {
+			body, err := r.Request.GetBody()
+			if err != nil {
+				return nil, ex.New(err)
+			}
+			req.Body = body
+		}
+
+		res, final, err := r.attempt(req)
+		if final || !canRetry || (r.Retry.MaxAttempts > 0 && attempt >= r.Retry.MaxAttempts) || !r.Retry.ShouldRetry(req, res, err) {
+			return res, err
+		}
+		delay := r.Retry.Delay(ctx, attempt, res)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return res, err
+		}
+		if res != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+			_ = res.Body.Close()
+		}
+
+		alarm = time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			alarm.Stop()
+			return nil, ex.New(ctx.Err())
+		case <-alarm.C:
+		}
+	}
+}
+
+// attempt sends a request once, reporting it to the tracer and listeners.
+//
+// It returns final if the attempt failed in a way that should not be retried, e.g. a listener failed.
+func (r Request) attempt(req *http.Request) (res *http.Response, final bool, err error) {
 	started := time.Now().UTC()
 	var finisher TraceFinisher
 	if r.Tracer != nil {
-		finisher = r.Tracer.Start(r.Request)
+		finisher = r.Tracer.Start(req)
 	}
 	for _, listener := range r.OnRequest {
-		if err := listener(r.Request); err != nil {
-			return nil, err
+		if err = listener(req); err != nil {
+			return nil, true, err
 		}
 	}
 
-	var err error
-	var res *http.Response
 	if r.Client != nil {
-		res, err = r.Client.Do(r.Request)
+		res, err = r.Client.Do(req)
 	} else {
-		res, err = http.DefaultClient.Do(r.Request)
+		res, err = http.DefaultClient.Do(req)
 	}
 	if finisher != nil {
-		finisher.Finish(r.Request, res, started, err)
+		finisher.Finish(req, res, started, err)
 	}
 	for _, listener := range r.OnResponse {
-		if listenerErr := listener(r.Request, res, started, err); listenerErr != nil {
+		if listenerErr := listener(req, res, started, err); listenerErr != nil {
 			err = ex.Append(err, listenerErr)
-			return nil, err
+			return nil, true, err
 		}
 	}
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
-	return res, nil
+	return res, false, nil
 }
 
 // Close closes the request if there is a closer specified.
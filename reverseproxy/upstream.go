@@ -8,6 +8,9 @@ Use of this source code is governed by a MIT license that can be found in the LI
 package reverseproxy
 
 import (
+	"context"
+	"crypto/tls"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
@@ -57,6 +60,24 @@ func (u *Upstream) UseHTTP2() error {
 	return nil
 }
 
+// UseH2C sets the upstream to use http2 without tls (h2c), e.g. for upstreams behind a sidecar.
+//
+// Requests to the upstream will use "prior knowledge" http2 over plaintext connections,
+// dialed with the existing transport's dialer if one is set.
+func (u *Upstream) UseH2C() error {
+	dial := (&net.Dialer{}).DialContext
+	if typed, ok := u.ReverseProxy.Transport.(*http.Transport); ok && typed.DialContext != nil {
+		dial = typed.DialContext
+	}
+	u.ReverseProxy.Transport = &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return dial(ctx, network, addr)
+		},
+	}
+	return nil
+}
+
 // ServeHTTP
 func (u *Upstream) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	w := webutil.NewStatusResponseWriter(rw)
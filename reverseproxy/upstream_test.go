@@ -13,6 +13,9 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
 	"github.com/blend/go-sdk/assert"
 	"github.com/blend/go-sdk/logger"
 )
@@ -50,3 +53,22 @@ func TestUpstreamServeHTTP(t *testing.T) {
 	assert.Empty(res.Header.Get("X-Forwarded-For"))
 	assert.Empty(res.Header.Get("X-Forwarded-Port"))
 }
+
+func TestUpstreamUseH2C(t *testing.T) {
+	its := assert.New(t)
+
+	srv := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, r.Proto)
+	}), &http2.Server{}))
+	defer srv.Close()
+
+	u := NewUpstream(MustParseURL(srv.URL))
+	its.Nil(u.UseH2C())
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	u.ServeHTTP(rw, req)
+	its.Equal(http.StatusOK, rw.Code)
+	its.Equal("HTTP/2.0", rw.Body.String())
+}
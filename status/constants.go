@@ -18,6 +18,9 @@ const (
 	ErrServiceCheckNotDefined ex.Class = "service check is not defined for service"
 )
 
+// ReadinessCheckName is the service name the readiness check is registered under.
+const ReadinessCheckName = "readiness"
+
 const (
 	// DefaultFreeformTimeout is a timeout.
 	DefaultFreeformTimeout = 10 * time.Second
@@ -71,6 +71,14 @@ func OptCheck(serviceName string, check Checker) ControllerOption {
 	}
 }
 
+// OptReadiness adds a readiness check, e.g. a `*graceful.Readiness`, to the sla checks.
+//
+// The sla endpoint will report the service as unhealthy once the readiness check fails,
+// which a shutdown plan does before it starts draining traffic.
+func OptReadiness(readiness Checker) ControllerOption {
+	return OptCheck(ReadinessCheckName, readiness)
+}
+
 // OptMiddleware adds default middleware for the status routes.
 //
 // Middleware must be set _before_ you register the controller.
@@ -16,6 +16,7 @@ import (
 
 	"github.com/blend/go-sdk/assert"
 	"github.com/blend/go-sdk/configmeta"
+	"github.com/blend/go-sdk/graceful"
 	"github.com/blend/go-sdk/web"
 )
 
@@ -181,3 +182,29 @@ func Test_Controller_getStatusDetails(t *testing.T) {
 	its.Equal(SignalRed, res.Status)
 	its.Equal(SignalRed, res.SubSystems["test-service"].Status)
 }
+
+func Test_Controller_getStatusSLA_readiness(t *testing.T) {
+	t.Parallel()
+	its := assert.New(t)
+
+	readiness := graceful.NewReadiness()
+	statusController := NewController(
+		OptReadiness(readiness),
+	)
+	app := web.MustNew()
+	app.Register(statusController)
+
+	meta, err := web.MockGet(app, "/status/sla").Discard()
+	its.Nil(err)
+	its.Equal(http.StatusServiceUnavailable, meta.StatusCode)
+
+	readiness.SetReady(true)
+	meta, err = web.MockGet(app, "/status/sla").Discard()
+	its.Nil(err)
+	its.Equal(http.StatusOK, meta.StatusCode)
+
+	readiness.SetReady(false)
+	meta, err = web.MockGet(app, "/status/sla").Discard()
+	its.Nil(err)
+	its.Equal(http.StatusServiceUnavailable, meta.StatusCode)
+}
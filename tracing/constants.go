@@ -7,6 +7,10 @@ Use of this source code is governed by a MIT license that can be found in the LI
 
 package tracing
 
+import (
+	"github.com/blend/go-sdk/logger"
+)
+
 // These constants are mostly lifted from the datadog/tracing/ext tag values.
 const (
 	// TagKeyEnvironment is the environment (web, dev, etc.)
@@ -35,6 +39,8 @@ const (
 	TagKeyHTTPCode = "http.status_code"
 	// TagKeyHTTPURL is the url of the request (typically the raw path).
 	TagKeyHTTPURL = "http.url"
+	// TagKeyHTTPRetryAttempt is the attempt number of an outgoing request that is retried.
+	TagKeyHTTPRetryAttempt = "http.retry_attempt"
 	// TagKeyDBApplication is the application that uses a database.
 	TagKeyDBApplication = "db.application"
 	// TagKeyDBName is the database name.
@@ -159,8 +165,8 @@ const (
 
 // LoggerAnnotations
 const (
-	LoggerAnnotationTracingSpanID  = "tracing.span-id"
-	LoggerAnnotationTracingTraceID = "tracing.trace-id"
+	LoggerAnnotationTracingSpanID  = logger.AnnotationTracingSpanID
+	LoggerAnnotationTracingTraceID = logger.AnnotationTracingTraceID
 )
 
 // Priority is a hint given to the backend so that it knows which traces to reject or kept.
@@ -10,13 +10,18 @@ package web
 import (
 	"context"
 	"crypto/tls"
+	"errors"
+	"io/fs"
 	"net"
 	"net/http"
 	"strings"
 	"time"
 
+	"golang.org/x/net/http2"
+
 	"github.com/blend/go-sdk/async"
 	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/graceful"
 	"github.com/blend/go-sdk/logger"
 	"github.com/blend/go-sdk/proxyprotocol"
 	"github.com/blend/go-sdk/webutil"
@@ -82,6 +87,9 @@ type App struct {
 	TLSConfig *tls.Config
 	Server    *http.Server
 	Listener  net.Listener
+	// Handoff is an optional handoff the listener is created with, so it
+	// can be passed to a new process on a graceful restart.
+	Handoff *graceful.Handoff
 
 	Statics map[string]*StaticFileServer
 
@@ -130,10 +138,18 @@ func (a *App) Start() (err error) {
 		}
 
 		var rawListener net.Listener
-		rawListener, err = net.Listen("tcp", a.Server.Addr)
-		if err != nil {
-			err = ex.New(err)
-			return
+		if a.Handoff != nil {
+			rawListener, err = a.Handoff.Listen("tcp", a.Server.Addr)
+			if err != nil {
+				return
+			}
+			defer a.Handoff.Release(rawListener)
+		} else {
+			rawListener, err = net.Listen("tcp", a.Server.Addr)
+			if err != nil {
+				err = ex.New(err)
+				return
+			}
 		}
 		typedListener, ok := rawListener.(*net.TCPListener)
 		if !ok {
@@ -246,6 +262,47 @@ func (a *App) ServeStaticCached(route string, searchPaths []string, middleware .
 	a.Method(webutil.MethodGet, mountedRoute, sfs.Action, middleware...)
 }
 
+// ServeStaticFS serves files from the given filesystem(s), reading them for each request.
+// If the path does not end with "/*filepath" that suffix will be added for you internally.
+//
+// Use `os.DirFS` during development to pick up changes to files on disk without a restart.
+func (a *App) ServeStaticFS(route string, filesystems []fs.FS, middleware ...Middleware) {
+	sfs := NewStaticFileServer(
+		OptStaticFileServerFS(filesystems...),
+		OptStaticFileServerCacheDisabled(true),
+	)
+	mountedRoute := a.formatStaticMountRoute(route)
+	a.Statics[mountedRoute] = sfs
+	a.Method(webutil.MethodGet, mountedRoute, sfs.Action, middleware...)
+}
+
+// ServeStaticCachedFS serves files from the given filesystem(s), such as an `embed.FS`,
+// caching their contents in memory after the first read.
+// If the path does not end with "/*filepath" that suffix will be added for you internally.
+//
+// Etags and content types are read from a manifest generated at build time (see `NewStaticFileManifest`)
+// if a filesystem has one at `StaticFileManifestPath`; otherwise they are computed as files are first read.
+// Errors reading a manifest are logged, and the manifest is ignored.
+func (a *App) ServeStaticCachedFS(route string, filesystems []fs.FS, middleware ...Middleware) {
+	sfs := NewStaticFileServer(
+		OptStaticFileServerFS(filesystems...),
+	)
+	for _, fsys := range filesystems {
+		manifest, err := ReadStaticFileManifest(fsys, StaticFileManifestPath)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			logger.MaybeErrorContext(a.Background(), a.Log, err)
+			continue
+		}
+		sfs.Manifest = sfs.Manifest.Merge(manifest)
+	}
+	mountedRoute := a.formatStaticMountRoute(route)
+	a.Statics[mountedRoute] = sfs
+	a.Method(webutil.MethodGet, mountedRoute, sfs.Action, middleware...)
+}
+
 // SetStaticRewriteRule adds a rewrite rule for a specific statically served path.
 // It mutates the path for the incoming static file request to the fileserver according to the action.
 func (a *App) SetStaticRewriteRule(route, match string, action RewriteAction) error {
@@ -267,6 +324,21 @@ func (a *App) SetStaticHeader(route, key, value string) error {
 	return ex.New("no static fileserver mounted at route", ex.OptMessagef("route: %s", mountedRoute))
 }
 
+// SetStaticManifest sets the precomputed file metadata for the given static path.
+// Etags and content types are read from the manifest instead of being computed from file contents.
+// Files already cached by the static path are evicted so they pick up the manifest.
+func (a *App) SetStaticManifest(route string, manifest StaticFileManifest) error {
+	mountedRoute := a.formatStaticMountRoute(route)
+	if static, hasRoute := a.Statics[mountedRoute]; hasRoute {
+		static.Lock()
+		static.Manifest = manifest
+		static.Cache = nil
+		static.Unlock()
+		return nil
+	}
+	return ex.New("no static fileserver mounted at route", ex.OptMessagef("route: %s", mountedRoute))
+}
+
 // --------------------------------------------------------------------------------
 // Route Registration / HTTP Methods
 // --------------------------------------------------------------------------------
@@ -308,12 +380,12 @@ func (a *App) DELETE(path string, action Action, middleware ...Middleware) {
 
 // Method registers an action for a given method and path with the given middleware.
 func (a *App) Method(method string, path string, action Action, middleware ...Middleware) {
-	a.RouteTree.Handle(method, path, a.RenderAction(NestMiddleware(action, append(middleware, a.BaseMiddleware...)...)))
+	a.RouteTree.Handle(method, path, a.RenderAction(NestMiddleware(rejectDeclaredBodyTooLarge(action), append(middleware, a.BaseMiddleware...)...)))
 }
 
 // MethodBare registers an action for a given method and path with the given middleware that omits logging and tracing.
 func (a *App) MethodBare(method string, path string, action Action, middleware ...Middleware) {
-	a.RouteTree.Handle(method, path, a.RenderActionBare(NestMiddleware(action, append(middleware, a.BaseMiddleware...)...)))
+	a.RouteTree.Handle(method, path, a.RenderActionBare(NestMiddleware(rejectDeclaredBodyTooLarge(action), append(middleware, a.BaseMiddleware...)...)))
 }
 
 // Lookup finds the route data for a given method and path.
@@ -341,6 +413,9 @@ func (a *App) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 
 // RenderAction is the translation step from Action to Handler.
 func (a *App) RenderAction(action Action) Handler {
+	if a.Config.MaxBodyBytes > 0 {
+		action = limitBodyBytes(a.Config.MaxBodyBytes)(action)
+	}
 	return func(w http.ResponseWriter, r *http.Request, route *Route, p RouteParameters) {
 		ctx := NewCtx(webutil.NewStatusResponseWriter(w), r, a.ctxOptions(r.Context(), route, p)...)
 		defer ctx.Close()
@@ -381,6 +456,9 @@ func (a *App) RenderAction(action Action) Handler {
 
 // RenderActionBare is the translation step from Action to Handler that omits logging.
 func (a *App) RenderActionBare(action Action) Handler {
+	if a.Config.MaxBodyBytes > 0 {
+		action = limitBodyBytes(a.Config.MaxBodyBytes)(action)
+	}
 	return func(w http.ResponseWriter, r *http.Request, route *Route, p RouteParameters) {
 		ctx := NewCtx(webutil.NewStatusResponseWriter(w), r, a.ctxOptions(r.Context(), route, p)...)
 		defer ctx.Close()
@@ -438,7 +516,7 @@ func (a *App) formatStaticMountRoute(route string) string {
 }
 
 func (a *App) httpServerOptions() []webutil.HTTPServerOption {
-	return []webutil.HTTPServerOption{
+	opts := []webutil.HTTPServerOption{
 		webutil.OptHTTPServerHandler(a),
 		webutil.OptHTTPServerTLSConfig(a.TLSConfig),
 		webutil.OptHTTPServerAddr(a.Config.BindAddrOrDefault()),
@@ -449,6 +527,17 @@ func (a *App) httpServerOptions() []webutil.HTTPServerOption {
 		webutil.OptHTTPServerIdleTimeout(a.Config.IdleTimeoutOrDefault()),
 		webutil.OptHTTPServerBaseContext(a.BaseContext),
 	}
+	if a.Config.UseH2C || a.Config.HTTP2MaxConcurrentStreams > 0 || a.Config.HTTP2MaxReadFrameSize > 0 {
+		h2s := &http2.Server{
+			MaxConcurrentStreams: uint32(a.Config.HTTP2MaxConcurrentStreams),
+			MaxReadFrameSize:     uint32(a.Config.HTTP2MaxReadFrameSize),
+		}
+		opts = append(opts, webutil.OptHTTPServerHTTP2(h2s))
+		if a.Config.UseH2C {
+			opts = append(opts, webutil.OptHTTPServerH2C(h2s))
+		}
+	}
+	return opts
 }
 
 func (a *App) ctxOptions(ctx context.Context, route *Route, p RouteParameters) []CtxOption {
@@ -501,6 +590,7 @@ func (a *App) logRequest(r *Ctx) {
 		webutil.OptHTTPRequestContentLength(r.Response.ContentLength()),
 		webutil.OptHTTPRequestHeader(r.Response.Header().Clone()),
 		webutil.OptHTTPRequestElapsed(r.Elapsed()),
+		webutil.OptHTTPRequestBodyLimitExceeded(r.BodyLimitExceeded()),
 	)
 	if r.Route != nil {
 		requestEvent.Route = r.Route.String()
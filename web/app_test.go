@@ -10,18 +10,27 @@ package web
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"fmt"
+	"io"
+	"io/fs"
+	"net"
 	"net/http"
+	"os"
 	"strings"
 	"sync"
 	"testing"
+	"testing/fstest"
 	"time"
 
+	"golang.org/x/net/http2"
+
 	"github.com/blend/go-sdk/assert"
 	"github.com/blend/go-sdk/env"
 	"github.com/blend/go-sdk/ex"
 	"github.com/blend/go-sdk/graceful"
 	"github.com/blend/go-sdk/logger"
+	"github.com/blend/go-sdk/r2"
 	"github.com/blend/go-sdk/webutil"
 )
 
@@ -274,6 +283,81 @@ func TestAppStatic(t *testing.T) {
 	assert.True(strings.Contains(string(index), "Test!"), string(index))
 }
 
+func TestAppStaticFS(t *testing.T) {
+	assert := assert.New(t)
+
+	app, err := New()
+	assert.Nil(err)
+
+	app.ServeStaticFS("/static", []fs.FS{os.DirFS("testdata")})
+
+	index, _, err := MockGet(app, "/static/test_file.html").Bytes()
+	assert.Nil(err)
+	assert.True(strings.Contains(string(index), "Test!"), string(index))
+}
+
+func TestAppStaticCachedFS(t *testing.T) {
+	assert := assert.New(t)
+
+	app, err := New()
+	assert.Nil(err)
+
+	assets := fstest.MapFS{
+		"js/app.js": &fstest.MapFile{Data: []byte("console.log('hi');")},
+	}
+	app.ServeStaticCachedFS("/static", []fs.FS{assets})
+
+	// without a manifest, etags are computed when files are first read
+	assert.Empty(app.Statics["/static/*filepath"].Manifest)
+	_, meta, err := MockGet(app, "/static/js/app.js").Bytes()
+	assert.Nil(err)
+	assert.Equal(webutil.ETag([]byte("console.log('hi');")), meta.Header.Get(webutil.HeaderETag))
+
+	assert.Nil(app.SetStaticManifest("/static", StaticFileManifest{
+		"js/app.js": {ETag: `"precomputed"`, ContentType: "text/javascript"},
+	}))
+	assert.NotNil(app.SetStaticManifest("/notaroute", nil))
+
+	contents, meta, err := MockGet(app, "/static/js/app.js").Bytes()
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, meta.StatusCode)
+	assert.Equal("console.log('hi');", string(contents))
+	assert.Equal(`"precomputed"`, meta.Header.Get(webutil.HeaderETag))
+	assert.Equal("text/javascript", meta.Header.Get(webutil.HeaderContentType))
+
+	_, meta, err = MockGet(app, "/static/js/app.js", r2.OptHeaderValue("If-None-Match", `"precomputed"`)).Bytes()
+	assert.Nil(err)
+	assert.Equal(http.StatusNotModified, meta.StatusCode)
+
+	_, meta, err = MockGet(app, "/static/js/missing.js").Bytes()
+	assert.Nil(err)
+	assert.Equal(http.StatusNotFound, meta.StatusCode)
+}
+
+
+func TestAppStaticCachedFSManifest(t *testing.T) {
+	assert := assert.New(t)
+
+	buffer := new(bytes.Buffer)
+	app, err := New(OptLog(logger.Memory(buffer)))
+	assert.Nil(err)
+
+	assets := fstest.MapFS{
+		"js/app.js":            &fstest.MapFile{Data: []byte("console.log('hi');")},
+		StaticFileManifestPath: &fstest.MapFile{Data: []byte(`{"js/app.js":{"etag":"\"built\"","contentType":"text/javascript"}}`)},
+	}
+	invalid := fstest.MapFS{
+		StaticFileManifestPath: &fstest.MapFile{Data: []byte(`{`)},
+	}
+	app.ServeStaticCachedFS("/static", []fs.FS{assets, invalid})
+	assert.Contains(buffer.String(), "[error]")
+
+	_, meta, err := MockGet(app, "/static/js/app.js").Bytes()
+	assert.Nil(err)
+	assert.Equal(`"built"`, meta.Header.Get(webutil.HeaderETag))
+	assert.Equal("text/javascript", meta.Header.Get(webutil.HeaderContentType))
+}
+
 func TestAppStaticSingleFile(t *testing.T) {
 	assert := assert.New(t)
 	app, err := New()
@@ -767,3 +851,41 @@ func TestAppMethodBare(t *testing.T) {
 	agent.Drain()
 	assert.Empty(buffer.String())
 }
+
+func TestAppH2C(t *testing.T) {
+	its := assert.New(t)
+
+	app, err := New(OptBindAddr(DefaultMockBindAddr), OptH2C(true), OptHTTP2MaxConcurrentStreams(10))
+	its.Nil(err)
+	app.GET("/", func(r *Ctx) Result {
+		return Text.Result(r.Request.Proto)
+	})
+
+	go func() { _ = app.Start() }()
+	<-app.NotifyStarted()
+	defer func() { _ = app.Stop() }()
+	its.Nil(app.Server.TLSConfig)
+
+	// http/1.1 is still served on the same port.
+	res, err := http.Get("http://" + app.Listener.Addr().String() + "/")
+	its.Nil(err)
+	defer res.Body.Close()
+	contents, err := io.ReadAll(res.Body)
+	its.Nil(err)
+	its.Equal("HTTP/1.1", strings.TrimSpace(string(contents)))
+
+	client := &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+				return net.Dial(network, addr)
+			},
+		},
+	}
+	res, err = client.Get("http://" + app.Listener.Addr().String() + "/")
+	its.Nil(err)
+	defer res.Body.Close()
+	contents, err = io.ReadAll(res.Body)
+	its.Nil(err)
+	its.Equal("HTTP/2.0", strings.TrimSpace(string(contents)))
+}
@@ -52,11 +52,12 @@ var (
 
 // CachedStaticFile is a memory mapped static file.
 type CachedStaticFile struct {
-	Path     string
-	Size     int
-	ETag     string
-	ModTime  time.Time
-	Contents *bytes.Reader
+	Path        string
+	Size        int
+	ETag        string
+	ContentType string
+	ModTime     time.Time
+	Contents    *bytes.Reader
 }
 
 // Render implements Result.
@@ -67,6 +68,9 @@ func (csf CachedStaticFile) Render(ctx *Ctx) error {
 	if csf.ETag != "" {
 		ctx.Response.Header().Set(webutil.HeaderETag, csf.ETag)
 	}
+	if csf.ContentType != "" {
+		ctx.Response.Header().Set(webutil.HeaderContentType, csf.ContentType)
+	}
 	ctx.WithContext(logger.WithLabel(ctx.Context(), "web.static_file_cached", csf.Path))
 	http.ServeContent(ctx.Response, ctx.Request, csf.Path, csf.ModTime, csf.Contents)
 	return nil
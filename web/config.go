@@ -41,6 +41,7 @@ type Config struct {
 
 	DefaultHeaders      map[string]string `json:"defaultHeaders,omitempty" yaml:"defaultHeaders,omitempty"`
 	MaxHeaderBytes      int               `json:"maxHeaderBytes,omitempty" yaml:"maxHeaderBytes,omitempty" env:"MAX_HEADER_BYTES"`
+	MaxBodyBytes        int64             `json:"maxBodyBytes,omitempty" yaml:"maxBodyBytes,omitempty" env:"MAX_BODY_BYTES"`
 	ReadTimeout         time.Duration     `json:"readTimeout,omitempty" yaml:"readTimeout,omitempty" env:"READ_TIMEOUT"`
 	ReadHeaderTimeout   time.Duration     `json:"readHeaderTimeout,omitempty" yaml:"readHeaderTimeout,omitempty" env:"READ_HEADER_TIMEOUT"`
 	WriteTimeout        time.Duration     `json:"writeTimeout,omitempty" yaml:"writeTimeout,omitempty" env:"WRITE_TIMEOUT"`
@@ -51,6 +52,10 @@ type Config struct {
 	KeepAlivePeriod  time.Duration `json:"keepAlivePeriod,omitempty" yaml:"keepAlivePeriod,omitempty" env:"KEEP_ALIVE_PERIOD"`
 	UseProxyProtocol bool          `json:"useProxyProtocol,omitempty" yaml:"useProxyProtocol,omitempty"`
 
+	UseH2C                    bool `json:"useH2C,omitempty" yaml:"useH2C,omitempty" env:"USE_H2C"`
+	HTTP2MaxConcurrentStreams int  `json:"http2MaxConcurrentStreams,omitempty" yaml:"http2MaxConcurrentStreams,omitempty" env:"HTTP2_MAX_CONCURRENT_STREAMS"`
+	HTTP2MaxReadFrameSize     int  `json:"http2MaxReadFrameSize,omitempty" yaml:"http2MaxReadFrameSize,omitempty" env:"HTTP2_MAX_READ_FRAME_SIZE"`
+
 	Views ViewCacheConfig `json:"views,omitempty" yaml:"views,omitempty"`
 }
 
@@ -74,6 +79,7 @@ func (c *Config) Resolve(ctx context.Context) error {
 		configutil.SetString(&c.CookiePath, configutil.Env("COOKIE_PATH"), configutil.String(c.CookiePath)),
 		configutil.SetString(&c.CookieDomain, configutil.Env("COOKIE_DOMAIN"), configutil.String(c.CookieDomain), configutil.StringFunc(c.ResolveCookieDomain)),
 		configutil.SetInt(&c.MaxHeaderBytes, configutil.Env("MAX_HEADER_BYTES"), configutil.Int(c.MaxHeaderBytes)),
+		configutil.SetInt64(&c.MaxBodyBytes, configutil.Env("MAX_BODY_BYTES"), configutil.Int64(c.MaxBodyBytes)),
 		configutil.SetDuration(&c.ReadTimeout, configutil.Env("READ_TIMEOUT"), configutil.Duration(c.ReadTimeout)),
 		configutil.SetDuration(&c.ReadHeaderTimeout, configutil.Env("READ_HEADER_TIMEOUT"), configutil.Duration(c.ReadHeaderTimeout)),
 		configutil.SetDuration(&c.WriteTimeout, configutil.Env("WRITE_TIMEOUT"), configutil.Duration(c.WriteTimeout)),
@@ -81,6 +87,9 @@ func (c *Config) Resolve(ctx context.Context) error {
 		configutil.SetDuration(&c.ShutdownGracePeriod, configutil.Env("SHUTDOWN_GRACE_PERIOD"), configutil.Duration(c.ShutdownGracePeriod)),
 		configutil.SetBoolPtr(&c.KeepAlive, configutil.Env("KEEP_ALIVE"), configutil.Bool(c.KeepAlive)),
 		configutil.SetDuration(&c.KeepAlivePeriod, configutil.Env("KEEP_ALIVE_PERIOD"), configutil.Duration(c.KeepAlivePeriod)),
+		configutil.SetBool(&c.UseH2C, configutil.Env("USE_H2C"), configutil.Bool(&c.UseH2C)),
+		configutil.SetInt(&c.HTTP2MaxConcurrentStreams, configutil.Env("HTTP2_MAX_CONCURRENT_STREAMS"), configutil.Int(c.HTTP2MaxConcurrentStreams)),
+		configutil.SetInt(&c.HTTP2MaxReadFrameSize, configutil.Env("HTTP2_MAX_READ_FRAME_SIZE"), configutil.Int(c.HTTP2MaxReadFrameSize)),
 	)
 }
 
@@ -20,6 +20,7 @@ import (
 	"github.com/blend/go-sdk/ex"
 	"github.com/blend/go-sdk/logger"
 	"github.com/blend/go-sdk/reflectutil"
+	"github.com/blend/go-sdk/webutil"
 )
 
 var (
@@ -81,15 +82,24 @@ type Ctx struct {
 	Tracer Tracer
 	// RequestStarted is the time the request was received.
 	RequestStarted time.Time
+
+	multipart *webutil.MultipartReader
 }
 
 // Close closes the context.
+//
+// It also removes the temporary files of any multipart parts spooled with `MultipartReader`.
 func (rc *Ctx) Close() error {
 	if rc.Response != nil {
 		if err := rc.Response.Close(); err != nil {
 			return err
 		}
 	}
+	if rc.multipart != nil {
+		if err := rc.multipart.Close(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -248,6 +258,32 @@ func (rc *Ctx) PostBody() ([]byte, error) {
 	return rc.Body, nil
 }
 
+// MultipartReader returns a streaming reader for the parts of a multipart post body.
+//
+// Use this instead of `webutil.PostedFiles` if uploads may be large; parts over the
+// max file size (see `webutil.OptMultipartMaxFileSize`) are reported in the request log event.
+func (rc *Ctx) MultipartReader(opts ...webutil.MultipartReaderOption) (*webutil.MultipartReader, error) {
+	reader, err := webutil.NewMultipartReader(rc.Request, opts...)
+	if err != nil {
+		return nil, err
+	}
+	rc.multipart = reader
+	return reader, nil
+}
+
+// BodyLimitExceeded returns if the request body, or a part read with `MultipartReader`, was over its size limit.
+func (rc *Ctx) BodyLimitExceeded() bool {
+	if rc.multipart != nil && rc.multipart.Exceeded() {
+		return true
+	}
+	if rc.Request != nil {
+		if typed, ok := rc.Request.Body.(*webutil.MaxBytesBody); ok && typed.Exceeded() {
+			return true
+		}
+	}
+	return false
+}
+
 // PostBodyAsString returns the post body as a string.
 func (rc *Ctx) PostBodyAsString() (string, error) {
 	body, err := rc.PostBody()
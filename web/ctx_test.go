@@ -10,7 +10,10 @@ package web
 import (
 	"bytes"
 	"io"
+	"mime/multipart"
+	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -211,3 +214,29 @@ func TestCtxPostBodyAsForm(t *testing.T) {
 	assert.Equal(3.14, p.Cost)
 	assert.Empty(p.Excluded)
 }
+
+func TestCtxCloseMultipart(t *testing.T) {
+	assert := assert.New(t)
+
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "file.txt")
+	assert.Nil(err)
+	_, err = io.WriteString(part, strings.Repeat("a", 1024))
+	assert.Nil(err)
+	assert.Nil(writer.Close())
+
+	req, err := http.NewRequest(http.MethodPost, "/", body)
+	assert.Nil(err)
+	req.Header.Set(webutil.HeaderContentType, writer.FormDataContentType())
+	ctx := NewCtx(webutil.NewMockResponse(new(bytes.Buffer)), req)
+
+	reader, err := ctx.MultipartReader(webutil.OptMultipartSpoolThreshold(64))
+	assert.Nil(err)
+	spooled, err := reader.Next()
+	assert.Nil(err)
+	assert.True(spooled.IsSpooled())
+
+	assert.Nil(ctx.Close())
+	assert.False(spooled.IsSpooled(), "closing the context should remove spooled temp files")
+}
@@ -0,0 +1,11 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package dbsession
+
+// DefaultTableName is the default table sessions are stored in.
+const DefaultTableName = "web_session"
@@ -0,0 +1,27 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+/*
+Package dbsession provides a durable session store for `web.AuthManager` backed by a database table.
+
+Sessions are shared between replicas of a service and survive restarts; use `Migrations` to create the table
+and `Apply` to wire the store into an auth manager:
+
+	store := dbsession.New(conn)
+	if err := store.Migrations().Apply(ctx, conn); err != nil {
+		return err
+	}
+	authManager, err := web.NewAuthManager(web.OptAuthManagerFromConfig(cfg.Web))
+	if err != nil {
+		return err
+	}
+	store.Apply(&authManager)
+
+Expired sessions are never returned by the fetch handler, but their rows remain until `Sweep` is called;
+`Sweeper` returns an interval worker that sweeps on a fixed period.
+*/
+package dbsession // import "github.com/blend/go-sdk/web/dbsession"
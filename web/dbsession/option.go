@@ -0,0 +1,27 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package dbsession
+
+import "github.com/blend/go-sdk/db"
+
+// Option mutates a store.
+type Option func(*Store)
+
+// OptTableName sets the table name sessions are stored in.
+func OptTableName(tableName string) Option {
+	return func(s *Store) {
+		s.TableName = tableName
+	}
+}
+
+// OptInvocationOptions sets options that are applied to every invocation the store makes.
+func OptInvocationOptions(opts ...db.InvocationOption) Option {
+	return func(s *Store) {
+		s.InvocationOptions = append(s.InvocationOptions, opts...)
+	}
+}
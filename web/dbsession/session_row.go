@@ -0,0 +1,66 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package dbsession
+
+import (
+	"time"
+
+	"github.com/blend/go-sdk/web"
+)
+
+// NewSessionRow returns a new session row from a web session.
+func NewSessionRow(session *web.Session) SessionRow {
+	row := SessionRow{
+		SessionID:  session.SessionID,
+		UserID:     session.UserID,
+		BaseURL:    session.BaseURL,
+		CreatedUTC: session.CreatedUTC,
+		UserAgent:  session.UserAgent,
+		RemoteAddr: session.RemoteAddr,
+		State:      session.State,
+	}
+	if !session.ExpiresUTC.IsZero() {
+		expiresUTC := session.ExpiresUTC.UTC()
+		row.ExpiresUTC = &expiresUTC
+	}
+	return row
+}
+
+// SessionRow is the database representation of a session.
+//
+// Sessions that do not expire have a null `expires_utc` column.
+type SessionRow struct {
+	SessionID  string                 `db:"session_id,pk"`
+	UserID     string                 `db:"user_id"`
+	BaseURL    string                 `db:"base_url"`
+	CreatedUTC time.Time              `db:"created_utc"`
+	ExpiresUTC *time.Time             `db:"expires_utc"`
+	UserAgent  string                 `db:"user_agent"`
+	RemoteAddr string                 `db:"remote_addr"`
+	State      map[string]interface{} `db:"state,json"`
+}
+
+// Session returns the web session for the row.
+func (sr SessionRow) Session() *web.Session {
+	session := &web.Session{
+		SessionID:  sr.SessionID,
+		UserID:     sr.UserID,
+		BaseURL:    sr.BaseURL,
+		CreatedUTC: sr.CreatedUTC.UTC(),
+		UserAgent:  sr.UserAgent,
+		RemoteAddr: sr.RemoteAddr,
+		State:      sr.State,
+	}
+	if sr.ExpiresUTC != nil {
+		session.ExpiresUTC = sr.ExpiresUTC.UTC()
+	}
+	if session.State == nil {
+		session.State = map[string]interface{}{}
+	}
+	return session
+}
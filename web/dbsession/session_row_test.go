@@ -0,0 +1,61 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package dbsession
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/web"
+)
+
+func TestSessionRow(t *testing.T) {
+	its := assert.New(t)
+
+	session := web.NewSession("example-string", web.NewSessionID())
+	session.ExpiresUTC = time.Now().UTC().Add(time.Hour)
+	session.State["foo"] = "bar"
+
+	row := NewSessionRow(session)
+	its.Equal(session.SessionID, row.SessionID)
+	its.Equal(session.UserID, row.UserID)
+	its.NotNil(row.ExpiresUTC)
+	its.Equal(session.ExpiresUTC, *row.ExpiresUTC)
+
+	roundTripped := row.Session()
+	its.Equal(session.SessionID, roundTripped.SessionID)
+	its.Equal(session.ExpiresUTC, roundTripped.ExpiresUTC)
+	its.Equal("bar", roundTripped.State["foo"])
+}
+
+func TestSessionRowNoExpiry(t *testing.T) {
+	its := assert.New(t)
+
+	row := NewSessionRow(web.NewSession("example-string", web.NewSessionID()))
+	its.Nil(row.ExpiresUTC)
+
+	session := SessionRow{SessionID: "foo", UserID: "bar"}.Session()
+	its.True(session.ExpiresUTC.IsZero())
+	its.NotNil(session.State)
+	its.False(session.IsExpired())
+}
+
+func TestStoreApply(t *testing.T) {
+	its := assert.New(t)
+
+	store := New(nil, OptTableName("sessions"))
+	its.Equal("sessions", store.TableName)
+	its.Equal("ix_sessions_user_id", store.indexName("user_id"))
+
+	var am web.AuthManager
+	store.Apply(&am)
+	its.NotNil(am.FetchHandler)
+	its.NotNil(am.PersistHandler)
+	its.NotNil(am.RemoveHandler)
+}
@@ -0,0 +1,204 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package dbsession
+
+//github:codeowner @blend/infosec
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/blend/go-sdk/async"
+	"github.com/blend/go-sdk/db"
+	"github.com/blend/go-sdk/db/migration"
+	"github.com/blend/go-sdk/web"
+)
+
+// New returns a new store for a given connection.
+func New(conn *db.Connection, opts ...Option) *Store {
+	store := Store{
+		Conn:      conn,
+		TableName: DefaultTableName,
+	}
+	for _, opt := range opts {
+		opt(&store)
+	}
+	return &store
+}
+
+// Store persists sessions to a database table.
+type Store struct {
+	Conn              *db.Connection
+	TableName         string
+	InvocationOptions []db.InvocationOption
+}
+
+// Apply applies the store to a given auth manager.
+func (s *Store) Apply(am *web.AuthManager) {
+	am.FetchHandler = s.FetchHandler
+	am.PersistHandler = s.PersistHandler
+	am.RemoveHandler = s.RemoveHandler
+}
+
+// Migrations returns the migration suite that creates the sessions table and its indexes.
+func (s *Store) Migrations() *migration.Suite {
+	return migration.New(
+		migration.OptGroups(
+			migration.NewGroupWithAction(
+				migration.TableNotExists(s.TableName),
+				migration.Statements(
+					fmt.Sprintf(`CREATE TABLE %s (
+	session_id varchar(255) NOT NULL PRIMARY KEY,
+	user_id varchar(255) NOT NULL,
+	base_url text,
+	created_utc timestamp NOT NULL,
+	expires_utc timestamp,
+	user_agent text,
+	remote_addr text,
+	state jsonb
+);`, s.TableName),
+				),
+			),
+			migration.NewGroupWithAction(
+				migration.IndexNotExists(s.TableName, s.indexName("user_id")),
+				migration.Statements(
+					fmt.Sprintf("CREATE INDEX %s ON %s (user_id);", s.indexName("user_id"), s.TableName),
+				),
+			),
+			migration.NewGroupWithAction(
+				migration.IndexNotExists(s.TableName, s.indexName("expires_utc")),
+				migration.Statements(
+					fmt.Sprintf("CREATE INDEX %s ON %s (expires_utc);", s.indexName("expires_utc"), s.TableName),
+				),
+			),
+		),
+	)
+}
+
+// FetchHandler is a shim to interface with the auth manager.
+//
+// Expired sessions are treated as if they do not exist.
+func (s *Store) FetchHandler(ctx context.Context, sessionID string) (*web.Session, error) {
+	var row SessionRow
+	found, err := s.invoke(ctx, "web_session_fetch").Query(
+		fmt.Sprintf("SELECT %s FROM %s WHERE session_id = $1 AND (expires_utc IS NULL OR expires_utc > $2)", sessionColumns, s.TableName),
+		sessionID, time.Now().UTC(),
+	).Out(&row)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+	return row.Session(), nil
+}
+
+// PersistHandler is a shim to interface with the auth manager.
+//
+// It inserts the session, or updates it if it already exists.
+func (s *Store) PersistHandler(ctx context.Context, session *web.Session) error {
+	row := NewSessionRow(session)
+	_, err := s.invoke(ctx, "web_session_persist").Exec(
+		fmt.Sprintf(`INSERT INTO %s (%s) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (session_id) DO UPDATE SET
+	user_id = excluded.user_id,
+	base_url = excluded.base_url,
+	expires_utc = excluded.expires_utc,
+	user_agent = excluded.user_agent,
+	remote_addr = excluded.remote_addr,
+	state = excluded.state`, s.TableName, sessionColumns),
+		row.SessionID,
+		row.UserID,
+		row.BaseURL,
+		row.CreatedUTC,
+		row.ExpiresUTC,
+		row.UserAgent,
+		row.RemoteAddr,
+		db.JSON(row.State),
+	)
+	return err
+}
+
+// RemoveHandler is a shim to interface with the auth manager.
+func (s *Store) RemoveHandler(ctx context.Context, sessionID string) error {
+	_, err := s.invoke(ctx, "web_session_remove").Exec(
+		fmt.Sprintf("DELETE FROM %s WHERE session_id = $1", s.TableName),
+		sessionID,
+	)
+	return err
+}
+
+// SessionsForUser returns the unexpired sessions for a given user, newest first.
+func (s *Store) SessionsForUser(ctx context.Context, userID string) (output []*web.Session, err error) {
+	var rows []SessionRow
+	err = s.invoke(ctx, "web_session_for_user").Query(
+		fmt.Sprintf("SELECT %s FROM %s WHERE user_id = $1 AND (expires_utc IS NULL OR expires_utc > $2) ORDER BY created_utc DESC", sessionColumns, s.TableName),
+		userID, time.Now().UTC(),
+	).OutMany(&rows)
+	if err != nil {
+		return
+	}
+	for _, row := range rows {
+		output = append(output, row.Session())
+	}
+	return
+}
+
+// RemoveAllForUser removes every session for a given user, logging them out everywhere.
+//
+// It returns the number of sessions removed.
+func (s *Store) RemoveAllForUser(ctx context.Context, userID string) (int64, error) {
+	res, err := s.invoke(ctx, "web_session_remove_all_for_user").Exec(
+		fmt.Sprintf("DELETE FROM %s WHERE user_id = $1", s.TableName),
+		userID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Sweep removes expired sessions.
+//
+// It returns the number of sessions removed.
+func (s *Store) Sweep(ctx context.Context) (int64, error) {
+	res, err := s.invoke(ctx, "web_session_sweep").Exec(
+		fmt.Sprintf("DELETE FROM %s WHERE expires_utc IS NOT NULL AND expires_utc < $1", s.TableName),
+		time.Now().UTC(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Sweeper returns an interval worker that calls `Sweep` on a given interval.
+//
+// The caller is responsible for starting and stopping the worker, typically with `graceful.Shutdown`.
+func (s *Store) Sweeper(interval time.Duration, opts ...async.IntervalOption) *async.Interval {
+	return async.NewInterval(func(ctx context.Context) error {
+		_, err := s.Sweep(ctx)
+		return err
+	}, interval, opts...)
+}
+
+//
+// utility methods
+//
+
+// sessionColumns are the columns in the order they are inserted.
+const sessionColumns = "session_id, user_id, base_url, created_utc, expires_utc, user_agent, remote_addr, state"
+
+func (s *Store) invoke(ctx context.Context, label string) *db.Invocation {
+	return s.Conn.Invoke(append([]db.InvocationOption{db.OptContext(ctx), db.OptLabel(label)}, s.InvocationOptions...)...)
+}
+
+func (s *Store) indexName(column string) string {
+	return fmt.Sprintf("ix_%s_%s", s.TableName, column)
+}
@@ -0,0 +1,113 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package dbsession
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/stringutil"
+	"github.com/blend/go-sdk/web"
+)
+
+// newTestStore returns a store with a dedicated sessions table that is dropped when the test completes.
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	store := New(defaultDB(), OptTableName(fmt.Sprintf("test_sessions_%s", stringutil.Random(stringutil.LowerLetters, 10))))
+	if err := store.Migrations().Apply(context.TODO(), defaultDB()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_, _ = defaultDB().Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", store.TableName))
+	})
+	return store
+}
+
+func TestStore(t *testing.T) {
+	its := assert.New(t)
+
+	store := newTestStore(t)
+	// the migrations are idempotent
+	its.Nil(store.Migrations().Apply(context.TODO(), defaultDB()))
+
+	session := web.NewSession("example-string", web.NewSessionID())
+	session.ExpiresUTC = time.Now().UTC().Add(time.Hour)
+	session.State["foo"] = "bar"
+	its.Nil(store.PersistHandler(context.TODO(), session))
+
+	fetched, err := store.FetchHandler(context.TODO(), session.SessionID)
+	its.Nil(err)
+	its.NotNil(fetched)
+	its.Equal(session.UserID, fetched.UserID)
+	its.Equal("bar", fetched.State["foo"])
+
+	// persisting again updates the session
+	session.State["foo"] = "baz"
+	session.ExpiresUTC = time.Now().UTC().Add(2 * time.Hour)
+	its.Nil(store.PersistHandler(context.TODO(), session))
+	fetched, err = store.FetchHandler(context.TODO(), session.SessionID)
+	its.Nil(err)
+	its.Equal("baz", fetched.State["foo"])
+
+	its.Nil(store.RemoveHandler(context.TODO(), session.SessionID))
+	fetched, err = store.FetchHandler(context.TODO(), session.SessionID)
+	its.Nil(err)
+	its.Nil(fetched)
+}
+
+func TestStoreExpired(t *testing.T) {
+	its := assert.New(t)
+
+	store := newTestStore(t)
+
+	expired := web.NewSession("example-string", web.NewSessionID())
+	expired.ExpiresUTC = time.Now().UTC().Add(-time.Minute)
+	its.Nil(store.PersistHandler(context.TODO(), expired))
+	its.Nil(store.PersistHandler(context.TODO(), web.NewSession("example-string", web.NewSessionID())))
+
+	fetched, err := store.FetchHandler(context.TODO(), expired.SessionID)
+	its.Nil(err)
+	its.Nil(fetched)
+
+	swept, err := store.Sweep(context.TODO())
+	its.Nil(err)
+	its.Equal(1, swept)
+
+	sessions, err := store.SessionsForUser(context.TODO(), "example-string")
+	its.Nil(err)
+	its.Len(sessions, 1, "sessions that do not expire should not be swept")
+}
+
+func TestStoreSessionsForUser(t *testing.T) {
+	its := assert.New(t)
+
+	store := newTestStore(t)
+	for x := 0; x < 3; x++ {
+		its.Nil(store.PersistHandler(context.TODO(), web.NewSession("example-string", web.NewSessionID())))
+	}
+	its.Nil(store.PersistHandler(context.TODO(), web.NewSession("other-user", web.NewSessionID())))
+
+	sessions, err := store.SessionsForUser(context.TODO(), "example-string")
+	its.Nil(err)
+	its.Len(sessions, 3)
+
+	removed, err := store.RemoveAllForUser(context.TODO(), "example-string")
+	its.Nil(err)
+	its.Equal(3, removed)
+
+	sessions, err = store.SessionsForUser(context.TODO(), "example-string")
+	its.Nil(err)
+	its.Empty(sessions)
+
+	sessions, err = store.SessionsForUser(context.TODO(), "other-user")
+	its.Nil(err)
+	its.Len(sessions, 1)
+}
@@ -38,3 +38,23 @@ func TextProviderAsDefault(action Action) Action {
 		return action(ctx)
 	}
 }
+
+// ProblemProviderAsDefault returns a middleware that sets the context.DefaultResultProvider() to a given problem result provider.
+func ProblemProviderAsDefault(provider *ProblemResultProvider) Middleware {
+	return func(action Action) Action {
+		return func(ctx *Ctx) Result {
+			ctx.DefaultProvider = provider
+			return action(ctx)
+		}
+	}
+}
+
+// NegotiatingProviderAsDefault returns a middleware that sets the context.DefaultResultProvider() to a given negotiating result provider.
+func NegotiatingProviderAsDefault(provider *NegotiatingResultProvider) Middleware {
+	return func(action Action) Action {
+		return func(ctx *Ctx) Result {
+			ctx.DefaultProvider = provider
+			return action(ctx)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/logger"
+	"github.com/blend/go-sdk/webutil"
+)
+
+// EventBrokerAction returns an action that subscribes the requesting client to the topics
+// returned by the topics function, streaming events from the broker until the client disconnects.
+//
+// The client's `Last-Event-ID` header is used to replay events it missed while reconnecting.
+// Routes using this action must not be gzipped.
+func EventBrokerAction(broker *webutil.EventBroker, topics func(*Ctx) []string) Action {
+	return func(ctx *Ctx) Result {
+		subscribed := topics(ctx)
+		if len(subscribed) == 0 {
+			return ctx.DefaultProvider.BadRequest(ex.New(webutil.ErrParameterMissing, ex.OptMessage("no event topics provided")))
+		}
+		err := broker.Serve(ctx.Context(), ctx.Response, ctx.Request.Header.Get(webutil.HeaderLastEventID), subscribed...)
+		if err != nil {
+			logger.MaybeWarningContext(ctx.Context(), ctx.Log, err)
+		}
+		return nil
+	}
+}
+
+// EventBrokerTopicsFromQuery returns a topics function that reads topics from a (repeatable) query string parameter.
+func EventBrokerTopicsFromQuery(key string) func(*Ctx) []string {
+	return func(ctx *Ctx) []string {
+		return ctx.Request.URL.Query()[key]
+	}
+}
@@ -0,0 +1,61 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/webutil"
+)
+
+func TestEventBrokerAction(t *testing.T) {
+	its := assert.New(t)
+
+	broker := webutil.NewEventBroker()
+	broker.Publish("foo", "update", "missed")
+
+	app := MustNew()
+	app.GET("/events", EventBrokerAction(broker, EventBrokerTopicsFromQuery("topic")))
+
+	res, err := MockGet(app, "/events").Discard()
+	its.Nil(err)
+	its.Equal(http.StatusBadRequest, res.StatusCode)
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/events?topic=foo", nil)
+	its.Nil(err)
+	req.Header.Set(webutil.HeaderLastEventID, "0")
+	res, err = http.DefaultClient.Do(req)
+	its.Nil(err)
+	defer res.Body.Close()
+	its.Equal(http.StatusOK, res.StatusCode)
+
+	for broker.SubscriberCount("foo") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	broker.Publish("foo", "update", "live")
+
+	reader := bufio.NewReader(res.Body)
+	var data []string
+	for len(data) < 2 {
+		line, err := reader.ReadString('\n')
+		its.Nil(err)
+		if strings.HasPrefix(line, "data: ") {
+			data = append(data, strings.TrimSpace(strings.TrimPrefix(line, "data: ")))
+		}
+	}
+	its.Equal([]string{"missed", "live"}, data)
+}
@@ -0,0 +1,42 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"bytes"
+	"io"
+	"time"
+
+	"github.com/blend/go-sdk/logger"
+	"github.com/blend/go-sdk/webutil"
+)
+
+// HMACSignature returns a middleware that verifies requests signed with an hmac-sha256
+// signature, e.g. with `r2.OptHMACSignature`.
+//
+// If the header name is unset, `X-Signature` is used; if the canonicalizer is unset,
+// `webutil.DefaultHMACCanonicalizer` is used. If max skew is set, requests whose
+// `X-Signature-Timestamp` is further than max skew from the current time are rejected,
+// limiting how long a captured request can be replayed for.
+// Requests that fail verification are rejected with a 401 (Not Authorized).
+func HMACSignature(key []byte, headerName string, canonicalizer webutil.HMACCanonicalizer, maxSkew time.Duration) Middleware {
+	return func(action Action) Action {
+		return func(ctx *Ctx) Result {
+			body, err := ctx.PostBody()
+			if err != nil {
+				return ctx.DefaultProvider.BadRequest(err)
+			}
+			ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+			if err := webutil.VerifyRequestHMAC(ctx.Request, body, key, headerName, canonicalizer, maxSkew, time.Now().UTC()); err != nil {
+				logger.MaybeDebugfContext(ctx.Context(), ctx.Log, "hmac signature verification failed: %v", err)
+				return ctx.DefaultProvider.NotAuthorized()
+			}
+			return action(ctx)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/r2"
+	"github.com/blend/go-sdk/webutil"
+)
+
+func TestHMACSignature(t *testing.T) {
+	its := assert.New(t)
+
+	key := []byte("secret")
+	app := MustNew()
+	app.POST("/hooks", func(ctx *Ctx) Result {
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			return ctx.DefaultProvider.InternalError(err)
+		}
+		return Text.Result(string(body))
+	}, HMACSignature(key, "", nil, time.Minute))
+
+	contents, meta, err := MockPost(app, "/hooks", io.NopCloser(strings.NewReader("hello")),
+		r2.OptHMACSignature(key, "", nil),
+	).Bytes()
+	its.Nil(err)
+	its.Equal(http.StatusOK, meta.StatusCode)
+	its.Equal("hello", strings.TrimSpace(string(contents)))
+
+	_, meta, err = MockPost(app, "/hooks", io.NopCloser(strings.NewReader("hello"))).Bytes()
+	its.Nil(err)
+	its.Equal(http.StatusUnauthorized, meta.StatusCode)
+
+	_, meta, err = MockPost(app, "/hooks", io.NopCloser(strings.NewReader("hello")),
+		r2.OptHMACSignature([]byte("not-secret"), "", nil),
+	).Bytes()
+	its.Nil(err)
+	its.Equal(http.StatusUnauthorized, meta.StatusCode)
+
+	_, meta, err = MockPost(app, "/hooks", io.NopCloser(strings.NewReader("hello")),
+		r2.OptOnRequest(func(req *http.Request) error {
+			webutil.SignRequestHMAC(req, []byte("hello"), key, "", nil, time.Now().Add(-5*time.Minute))
+			return nil
+		}),
+	).Bytes()
+	its.Nil(err)
+	its.Equal(http.StatusUnauthorized, meta.StatusCode)
+}
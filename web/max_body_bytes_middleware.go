@@ -0,0 +1,67 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"net/http"
+
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/webutil"
+)
+
+// MaxBodyBytes returns a middleware that limits the size of the request body.
+//
+// Requests with a declared content length over the limit are rejected before the action runs,
+// and reading more than the limit from the body returns `webutil.ErrRequestBodyTooLarge`;
+// in either case the result is a 413 (Request Entity Too Large). When used as route middleware,
+// it overrides the app wide limit set with `OptMaxBodyBytes`, allowing either larger or smaller bodies.
+func MaxBodyBytes(limit int64) Middleware {
+	return func(action Action) Action {
+		return limitBodyBytes(limit)(rejectDeclaredBodyTooLarge(action))
+	}
+}
+
+// limitBodyBytes sets the limit on the request body without checking the declared content length,
+// which lets route middleware raise the app wide limit before the check is made.
+func limitBodyBytes(limit int64) Middleware {
+	return func(action Action) Action {
+		return func(ctx *Ctx) Result {
+			if ctx.Request.Body == nil {
+				return action(ctx)
+			}
+			if existing, ok := ctx.Request.Body.(*webutil.MaxBytesBody); ok {
+				existing.Limit = limit
+				return action(ctx)
+			}
+			body := webutil.NewMaxBytesBody(ctx.Request.Body, ctx.Request.ContentLength, limit)
+			ctx.Request.Body = body
+			result := action(ctx)
+			if body.Exceeded() {
+				return bodyTooLarge(ctx, body)
+			}
+			return result
+		}
+	}
+}
+
+// rejectDeclaredBodyTooLarge returns a 413 without calling the action if the
+// declared content length of the body is over its limit.
+func rejectDeclaredBodyTooLarge(action Action) Action {
+	return func(ctx *Ctx) Result {
+		if ctx.Request != nil {
+			if body, ok := ctx.Request.Body.(*webutil.MaxBytesBody); ok && body.Exceeded() {
+				return bodyTooLarge(ctx, body)
+			}
+		}
+		return action(ctx)
+	}
+}
+
+func bodyTooLarge(ctx *Ctx, body *webutil.MaxBytesBody) Result {
+	return ctx.DefaultProvider.Status(http.StatusRequestEntityTooLarge, ex.New(webutil.ErrRequestBodyTooLarge, ex.OptMessagef("limit: %d bytes", body.Limit)))
+}
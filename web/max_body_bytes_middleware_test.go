@@ -0,0 +1,92 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/logger"
+)
+
+func TestMaxBodyBytes(t *testing.T) {
+	its := assert.New(t)
+
+	buffer := new(bytes.Buffer)
+	log := logger.MustNew(
+		logger.OptAll(),
+		logger.OptJSON(),
+		logger.OptOutput(buffer),
+	)
+	app := MustNew(OptLog(log), OptMaxBodyBytes(8))
+	echo := func(ctx *Ctx) Result {
+		body, err := ctx.PostBody()
+		if err != nil {
+			return ctx.DefaultProvider.BadRequest(err)
+		}
+		return Text.Result(string(body))
+	}
+	app.POST("/", echo)
+	app.POST("/large", echo, MaxBodyBytes(64))
+	app.POST("/small", echo, MaxBodyBytes(2))
+
+	contents, meta, err := MockPost(app, "/", io.NopCloser(strings.NewReader("hello"))).Bytes()
+	its.Nil(err)
+	its.Equal(http.StatusOK, meta.StatusCode)
+	its.Equal("hello", strings.TrimSpace(string(contents)))
+
+	_, meta, err = MockPost(app, "/", io.NopCloser(strings.NewReader(strings.Repeat("a", 16)))).Bytes()
+	its.Nil(err)
+	its.Equal(http.StatusRequestEntityTooLarge, meta.StatusCode)
+
+	contents, meta, err = MockPost(app, "/large", io.NopCloser(strings.NewReader(strings.Repeat("a", 16)))).Bytes()
+	its.Nil(err)
+	its.Equal(http.StatusOK, meta.StatusCode)
+	its.Equal(strings.Repeat("a", 16), strings.TrimSpace(string(contents)))
+
+	_, meta, err = MockPost(app, "/small", io.NopCloser(strings.NewReader("hello"))).Bytes()
+	its.Nil(err)
+	its.Equal(http.StatusRequestEntityTooLarge, meta.StatusCode)
+
+	log.Drain()
+	its.Equal(2, strings.Count(buffer.String(), `"bodyLimitExceeded":true`))
+}
+
+func TestMaxBodyBytesDeclaredContentLength(t *testing.T) {
+	its := assert.New(t)
+
+	var calls int
+	ignore := func(_ *Ctx) Result {
+		calls++
+		return NoContent
+	}
+	app := MustNew(OptMaxBodyBytes(8))
+	app.POST("/", ignore)
+	app.POST("/large", ignore, MaxBodyBytes(64))
+
+	newRequest := func(path string) *http.Request {
+		req, err := http.NewRequest(http.MethodPost, path, strings.NewReader(strings.Repeat("a", 16)))
+		its.Nil(err)
+		its.Equal(16, req.ContentLength)
+		return req
+	}
+
+	_, meta, err := Mock(app, newRequest("/")).Bytes()
+	its.Nil(err)
+	its.Equal(http.StatusRequestEntityTooLarge, meta.StatusCode)
+	its.Zero(calls, "the action should not run if the declared body is too large")
+
+	_, meta, err = Mock(app, newRequest("/large")).Bytes()
+	its.Nil(err)
+	its.Equal(http.StatusNoContent, meta.StatusCode)
+	its.Equal(1, calls)
+}
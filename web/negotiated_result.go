@@ -0,0 +1,72 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"github.com/blend/go-sdk/webutil"
+)
+
+var (
+	_ Result           = (*NegotiatedResult)(nil)
+	_ ResultPreRender  = (*NegotiatedResult)(nil)
+	_ ResultPostRender = (*NegotiatedResult)(nil)
+)
+
+// NegotiatedResult renders one of several results based on the request `Accept` header.
+type NegotiatedResult struct {
+	// DefaultContentType is the media type rendered if the client accepts none of the offers.
+	DefaultContentType string
+	// Offers are the media types that can be rendered, in order of preference.
+	Offers []string
+	// Results returns the result for a given media type.
+	Results func(ctx *Ctx, mediaType string) Result
+
+	chosen Result
+}
+
+// PreRender chooses the result to render and calls its pre-render step if it has one.
+func (nr *NegotiatedResult) PreRender(ctx *Ctx) error {
+	if typed, ok := nr.choose(ctx).(ResultPreRender); ok {
+		return typed.PreRender(ctx)
+	}
+	return nil
+}
+
+// Render renders the chosen result.
+func (nr *NegotiatedResult) Render(ctx *Ctx) error {
+	return nr.choose(ctx).Render(ctx)
+}
+
+// PostRender calls the chosen result's post-render step if it has one.
+func (nr *NegotiatedResult) PostRender(ctx *Ctx) error {
+	if typed, ok := nr.choose(ctx).(ResultPostRender); ok {
+		return typed.PostRender(ctx)
+	}
+	return nil
+}
+
+func (nr *NegotiatedResult) choose(ctx *Ctx) Result {
+	if nr.chosen != nil {
+		return nr.chosen
+	}
+	ctx.Response.Header().Add(webutil.HeaderVary, webutil.HeaderAccept)
+
+	var accept string
+	if ctx.Request != nil {
+		accept = ctx.Request.Header.Get(webutil.HeaderAccept)
+	}
+	mediaType := webutil.NegotiateContentType(accept, nr.Offers...)
+	if mediaType == "" {
+		mediaType = nr.DefaultContentType
+	}
+	if mediaType == "" && len(nr.Offers) > 0 {
+		mediaType = nr.Offers[0]
+	}
+	nr.chosen = nr.Results(ctx, mediaType)
+	return nr.chosen
+}
@@ -0,0 +1,128 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+// Negotiated media types.
+const (
+	MediaTypeJSON = "application/json"
+	MediaTypeXML  = "application/xml"
+	MediaTypeText = "text/plain"
+	MediaTypeHTML = "text/html"
+)
+
+var (
+	// assert it implements result provider.
+	_ ResultProvider = (*NegotiatingResultProvider)(nil)
+)
+
+// NewNegotiatingResultProvider returns a new negotiating result provider.
+func NewNegotiatingResultProvider(opts ...NegotiatingResultProviderOption) *NegotiatingResultProvider {
+	nrp := &NegotiatingResultProvider{
+		DefaultContentType: MediaTypeJSON,
+	}
+	for _, opt := range opts {
+		opt(nrp)
+	}
+	return nrp
+}
+
+// NegotiatingResultProviderOption mutates a negotiating result provider.
+type NegotiatingResultProviderOption func(*NegotiatingResultProvider)
+
+// OptNegotiatingDefaultContentType sets the media type rendered when the client accepts none of the offered types.
+//
+// It should be one of `MediaTypeJSON`, `MediaTypeXML`, `MediaTypeText` or `MediaTypeHTML`.
+func OptNegotiatingDefaultContentType(mediaType string) NegotiatingResultProviderOption {
+	return func(nrp *NegotiatingResultProvider) { nrp.DefaultContentType = mediaType }
+}
+
+// NegotiatingResultProvider renders results as json, xml, text or html views
+// based on the request `Accept` header, so actions can return one model for every format.
+//
+// Html is always offered so browsers don't get xml; it is rendered with the context's
+// view cache where there is a view to render (`View` names the view template to use),
+// and as text otherwise.
+type NegotiatingResultProvider struct {
+	DefaultContentType string
+}
+
+// NotFound returns a not found result.
+func (nrp NegotiatingResultProvider) NotFound() Result {
+	return nrp.negotiate(func(provider ResultProvider) Result { return provider.NotFound() })
+}
+
+// NotAuthorized returns a not authorized result.
+func (nrp NegotiatingResultProvider) NotAuthorized() Result {
+	return nrp.negotiate(func(provider ResultProvider) Result { return provider.NotAuthorized() })
+}
+
+// InternalError returns an internal server error result.
+func (nrp NegotiatingResultProvider) InternalError(err error) Result {
+	return nrp.negotiate(func(provider ResultProvider) Result { return provider.InternalError(err) })
+}
+
+// BadRequest returns a bad request result.
+func (nrp NegotiatingResultProvider) BadRequest(err error) Result {
+	return nrp.negotiate(func(provider ResultProvider) Result { return provider.BadRequest(err) })
+}
+
+// Status returns a result for a given status code and response.
+func (nrp NegotiatingResultProvider) Status(statusCode int, response interface{}) Result {
+	return nrp.negotiate(func(provider ResultProvider) Result { return provider.Status(statusCode, response) })
+}
+
+// Result returns an ok result for a model rendered as json, xml or text; html requests are rendered as text.
+func (nrp NegotiatingResultProvider) Result(response interface{}) Result {
+	return nrp.negotiate(func(provider ResultProvider) Result {
+		switch typed := provider.(type) {
+		case JSONResultProvider:
+			return typed.Result(response)
+		case XMLResultProvider:
+			return typed.Result(response)
+		default:
+			return Text.Result(response)
+		}
+	})
+}
+
+// View returns an ok result for a model rendered as json, xml, text, or as html with a given view.
+func (nrp NegotiatingResultProvider) View(viewName string, response interface{}) Result {
+	return nrp.negotiate(func(provider ResultProvider) Result {
+		switch typed := provider.(type) {
+		case JSONResultProvider:
+			return typed.Result(response)
+		case XMLResultProvider:
+			return typed.Result(response)
+		case *ViewCache:
+			return typed.View(viewName, response)
+		default:
+			return Text.Result(response)
+		}
+	})
+}
+
+func (nrp NegotiatingResultProvider) negotiate(result func(ResultProvider) Result) *NegotiatedResult {
+	return &NegotiatedResult{
+		DefaultContentType: nrp.DefaultContentType,
+		Offers:             []string{MediaTypeJSON, MediaTypeXML, MediaTypeText, MediaTypeHTML},
+		Results: func(ctx *Ctx, mediaType string) Result {
+			switch mediaType {
+			case MediaTypeXML:
+				return result(XML)
+			case MediaTypeText:
+				return result(Text)
+			case MediaTypeHTML:
+				if ctx.Views != nil {
+					return result(ctx.Views)
+				}
+				return result(Text)
+			}
+			return result(JSON)
+		},
+	}
+}
@@ -0,0 +1,98 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/r2"
+	"github.com/blend/go-sdk/webutil"
+)
+
+type negotiatedModel struct {
+	Name string `json:"name" xml:"name"`
+}
+
+func (nm negotiatedModel) String() string { return "name=" + nm.Name }
+
+func TestNegotiatingResultProvider(t *testing.T) {
+	its := assert.New(t)
+
+	app := MustNew()
+	app.Views.AddLiterals(`{{ define "model" }}<p>{{ .ViewModel.Name }}</p>{{ end }}`)
+	its.Nil(app.Views.Initialize())
+
+	provider := NewNegotiatingResultProvider()
+	app.GET("/model", func(ctx *Ctx) Result {
+		return provider.View("model", negotiatedModel{Name: "foo"})
+	}, NegotiatingProviderAsDefault(provider))
+	app.GET("/missing", func(ctx *Ctx) Result {
+		return ctx.DefaultProvider.NotFound()
+	}, NegotiatingProviderAsDefault(provider))
+
+	testCases := [...]struct {
+		Accept      string
+		ContentType string
+		Body        string
+	}{
+		{Accept: "", ContentType: webutil.ContentTypeApplicationJSON, Body: `{"name":"foo"}`},
+		{Accept: "application/xml", ContentType: webutil.ContentTypeXML, Body: `<negotiatedModel><name>foo</name></negotiatedModel>`},
+		{Accept: "text/plain;q=0.9, application/json;q=0.5", ContentType: webutil.ContentTypeText, Body: `name=foo`},
+		{Accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", ContentType: webutil.ContentTypeHTML, Body: `<p>foo</p>`},
+		{Accept: "image/png", ContentType: webutil.ContentTypeApplicationJSON, Body: `{"name":"foo"}`},
+	}
+	for _, tc := range testCases {
+		contents, meta, err := MockGet(app, "/model", r2.OptHeaderValue(webutil.HeaderAccept, tc.Accept)).Bytes()
+		its.Nil(err)
+		its.Equal(http.StatusOK, meta.StatusCode, tc.Accept)
+		its.Equal(tc.ContentType, meta.Header.Get(webutil.HeaderContentType), tc.Accept)
+		its.Equal(webutil.HeaderAccept, meta.Header.Get(webutil.HeaderVary), tc.Accept)
+		its.Equal(tc.Body, strings.TrimSpace(string(contents)), tc.Accept)
+	}
+
+	_, meta, err := MockGet(app, "/missing", r2.OptHeaderValue(webutil.HeaderAccept, "application/xml")).Bytes()
+	its.Nil(err)
+	its.Equal(http.StatusNotFound, meta.StatusCode)
+	its.Equal(webutil.ContentTypeXML, meta.Header.Get(webutil.HeaderContentType))
+}
+
+func TestNegotiatingResultProviderDefault(t *testing.T) {
+	its := assert.New(t)
+
+	app := MustNew()
+	provider := NewNegotiatingResultProvider(OptNegotiatingDefaultContentType(MediaTypeText))
+	app.GET("/", func(ctx *Ctx) Result {
+		return provider.Result(negotiatedModel{Name: "bar"})
+	})
+
+	contents, meta, err := MockGet(app, "/", r2.OptHeaderValue(webutil.HeaderAccept, "image/png")).Bytes()
+	its.Nil(err)
+	its.Equal(webutil.ContentTypeText, meta.Header.Get(webutil.HeaderContentType))
+	its.Equal("name=bar", strings.TrimSpace(string(contents)))
+}
+
+func TestNegotiatingResultProviderBrowser(t *testing.T) {
+	its := assert.New(t)
+
+	app := MustNew()
+	provider := NewNegotiatingResultProvider()
+	app.GET("/", func(ctx *Ctx) Result {
+		return provider.Result(negotiatedModel{Name: "bar"})
+	})
+
+	// plain results have no view, so browsers get text instead of the xml they also accept.
+	accept := "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+	contents, meta, err := MockGet(app, "/", r2.OptHeaderValue(webutil.HeaderAccept, accept)).Bytes()
+	its.Nil(err)
+	its.Equal(http.StatusOK, meta.StatusCode)
+	its.Equal(webutil.ContentTypeText, meta.Header.Get(webutil.HeaderContentType))
+	its.Equal("name=bar", strings.TrimSpace(string(contents)))
+}
@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/blend/go-sdk/env"
+	"github.com/blend/go-sdk/graceful"
 	"github.com/blend/go-sdk/logger"
 	"github.com/blend/go-sdk/webutil"
 )
@@ -126,6 +127,15 @@ func OptViews(views *ViewCache) Option {
 	}
 }
 
+// OptHandoff sets the handoff the app listener is created with, so the listener
+// is passed to a new process on a graceful restart (see `graceful.ShutdownWithHandoff`).
+func OptHandoff(handoff *graceful.Handoff) Option {
+	return func(a *App) error {
+		a.Handoff = handoff
+		return nil
+	}
+}
+
 // OptTLSConfig sets the tls config.
 func OptTLSConfig(cfg *tls.Config) Option {
 	return func(a *App) error {
@@ -237,6 +247,40 @@ func OptServerOptions(opts ...webutil.HTTPServerOption) Option {
 	}
 }
 
+// OptH2C sets if the app should serve http2 without tls (h2c) alongside http/1.1 on the same port.
+func OptH2C(useH2C bool) Option {
+	return func(a *App) error {
+		a.Config.UseH2C = useH2C
+		return nil
+	}
+}
+
+// OptHTTP2MaxConcurrentStreams sets the maximum number of concurrent streams per http2 connection.
+func OptHTTP2MaxConcurrentStreams(maxConcurrentStreams int) Option {
+	return func(a *App) error {
+		a.Config.HTTP2MaxConcurrentStreams = maxConcurrentStreams
+		return nil
+	}
+}
+
+// OptHTTP2MaxReadFrameSize sets the largest http2 frame the server will read.
+func OptHTTP2MaxReadFrameSize(maxReadFrameSize int) Option {
+	return func(a *App) error {
+		a.Config.HTTP2MaxReadFrameSize = maxReadFrameSize
+		return nil
+	}
+}
+
+// OptMaxBodyBytes sets the app wide maximum request body size; a request body over the limit results in a 413.
+//
+// Individual routes can override the limit with the `MaxBodyBytes` middleware.
+func OptMaxBodyBytes(maxBodyBytes int64) Option {
+	return func(a *App) error {
+		a.Config.MaxBodyBytes = maxBodyBytes
+		return nil
+	}
+}
+
 // OptReadTimeout sets the read timeout.
 //
 // Note that this will override the config setting if OptConfig comes before it
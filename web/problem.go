@@ -0,0 +1,102 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"encoding/json"
+)
+
+// Problem member names.
+const (
+	ProblemMemberType     = "type"
+	ProblemMemberTitle    = "title"
+	ProblemMemberStatus   = "status"
+	ProblemMemberDetail   = "detail"
+	ProblemMemberInstance = "instance"
+)
+
+var (
+	_ json.Marshaler   = (*Problem)(nil)
+	_ json.Unmarshaler = (*Problem)(nil)
+)
+
+// Problem is an RFC 7807 problem details object.
+//
+// Extensions are serialized as additional members alongside the standard members.
+type Problem struct {
+	// Type is a uri reference that identifies the problem type, "about:blank" if unset.
+	Type string
+	// Title is a short, human-readable summary of the problem type.
+	Title string
+	// Status is the http status code.
+	Status int
+	// Detail is a human-readable explanation specific to this occurrence of the problem.
+	Detail string
+	// Instance is a uri reference that identifies this occurrence of the problem.
+	Instance string
+	// Extensions are additional members of the problem.
+	Extensions map[string]interface{}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	output := make(map[string]interface{}, len(p.Extensions)+5)
+	for key, value := range p.Extensions {
+		output[key] = value
+	}
+	if p.Type != "" {
+		output[ProblemMemberType] = p.Type
+	} else {
+		output[ProblemMemberType] = "about:blank"
+	}
+	if p.Title != "" {
+		output[ProblemMemberTitle] = p.Title
+	}
+	if p.Status != 0 {
+		output[ProblemMemberStatus] = p.Status
+	}
+	if p.Detail != "" {
+		output[ProblemMemberDetail] = p.Detail
+	}
+	if p.Instance != "" {
+		output[ProblemMemberInstance] = p.Instance
+	}
+	return json.Marshal(output)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (p *Problem) UnmarshalJSON(contents []byte) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(contents, &members); err != nil {
+		return err
+	}
+	standard := map[string]interface{}{
+		ProblemMemberType:     &p.Type,
+		ProblemMemberTitle:    &p.Title,
+		ProblemMemberStatus:   &p.Status,
+		ProblemMemberDetail:   &p.Detail,
+		ProblemMemberInstance: &p.Instance,
+	}
+	for key, value := range members {
+		if target, ok := standard[key]; ok {
+			if err := json.Unmarshal(value, target); err != nil {
+				return err
+			}
+			continue
+		}
+		var extension interface{}
+		if err := json.Unmarshal(value, &extension); err != nil {
+			return err
+		}
+		if p.Extensions == nil {
+			p.Extensions = make(map[string]interface{})
+		}
+		p.Extensions[key] = extension
+	}
+	return nil
+}
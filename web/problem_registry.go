@@ -0,0 +1,87 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/validate"
+)
+
+// ProblemType describes the problem rendered for a class of errors.
+type ProblemType struct {
+	// Type is a uri reference that identifies the problem type.
+	Type string
+	// Title is a short, human-readable summary of the problem type.
+	Title string
+	// Status is the http status code for the problem; if unset the caller's default status is used.
+	Status int
+}
+
+// NewProblemRegistry returns a new problem registry.
+//
+// Validation errors (`validate.ErrValidation`) are registered by default as 400s.
+func NewProblemRegistry() *ProblemRegistry {
+	pr := &ProblemRegistry{
+		types: make(map[error]ProblemType),
+	}
+	pr.Register(validate.ErrValidation, ProblemType{
+		Title:  "Validation Error",
+		Status: http.StatusBadRequest,
+	})
+	return pr
+}
+
+// ProblemRegistry maps error classes to problem types.
+//
+// Errors are looked up by their `ex.Class`; for `validate` errors the validation cause
+// (e.g. `validate.ErrRequired`) is looked up first, then `validate.ErrValidation`.
+type ProblemRegistry struct {
+	sync.RWMutex
+	types map[error]ProblemType
+}
+
+// Register registers a problem type for an error class.
+//
+// The class must be comparable, e.g. an `ex.Class`.
+func (pr *ProblemRegistry) Register(class error, problemType ProblemType) *ProblemRegistry {
+	pr.Lock()
+	defer pr.Unlock()
+	pr.types[class] = problemType
+	return pr
+}
+
+// Lookup returns the problem type for an error.
+func (pr *ProblemRegistry) Lookup(err error) (problemType ProblemType, ok bool) {
+	if err == nil {
+		return
+	}
+	pr.RLock()
+	defer pr.RUnlock()
+
+	class := ex.ErrClass(err)
+	if class == validate.ErrValidation {
+		if cause := validate.ErrCause(err); cause != nil && isComparable(cause) {
+			if problemType, ok = pr.types[cause]; ok {
+				return
+			}
+		}
+	}
+	if class != nil && isComparable(class) {
+		problemType, ok = pr.types[class]
+	}
+	return
+}
+
+// isComparable returns if an error can be used as a map key.
+func isComparable(err error) bool {
+	return reflect.TypeOf(err).Comparable()
+}
@@ -0,0 +1,45 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/webutil"
+)
+
+// ProblemResult is an `application/problem+json` result.
+type ProblemResult struct {
+	Problem Problem
+}
+
+// Render renders the result.
+//
+// If the problem instance is unset, it is set to the request path; if the problem
+// status is unset, it is set to 500 (Internal Server Error).
+func (pr *ProblemResult) Render(ctx *Ctx) error {
+	problem := pr.Problem
+	if problem.Status == 0 {
+		problem.Status = http.StatusInternalServerError
+	}
+	if problem.Instance == "" && ctx.Request != nil && ctx.Request.URL != nil {
+		problem.Instance = ctx.Request.URL.Path
+	}
+	ctx.Response.Header().Set(webutil.HeaderContentType, webutil.ContentTypeApplicationProblemJSON)
+	ctx.Response.WriteHeader(problem.Status)
+	if err := json.NewEncoder(ctx.Response).Encode(problem); err != nil {
+		if typed, ok := err.(*net.OpError); ok {
+			return ex.New(webutil.ErrNetWrite, ex.OptInner(typed))
+		}
+		return ex.New(err)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/blend/go-sdk/env"
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/validate"
+)
+
+// Problem extension member names.
+const (
+	ProblemExtensionStackTrace = "stackTrace"
+	ProblemExtensionValue      = "value"
+)
+
+var (
+	// assert it implements result provider.
+	_ ResultProvider = (*ProblemResultProvider)(nil)
+)
+
+// NewProblemResultProvider returns a new problem result provider.
+//
+// By default it uses a new `ProblemRegistry`, and includes stack traces if
+// the `SERVICE_ENV` environment variable is a development environment.
+func NewProblemResultProvider(opts ...ProblemResultProviderOption) *ProblemResultProvider {
+	prp := &ProblemResultProvider{
+		Registry:          NewProblemRegistry(),
+		IncludeStackTrace: env.IsDev(env.Env().ServiceEnv()),
+	}
+	for _, opt := range opts {
+		opt(prp)
+	}
+	return prp
+}
+
+// ProblemResultProviderOption mutates a problem result provider.
+type ProblemResultProviderOption func(*ProblemResultProvider)
+
+// OptProblemRegistry sets the problem registry.
+func OptProblemRegistry(registry *ProblemRegistry) ProblemResultProviderOption {
+	return func(prp *ProblemResultProvider) { prp.Registry = registry }
+}
+
+// OptProblemServiceEnv sets if stack traces are included based on a service environment.
+func OptProblemServiceEnv(serviceEnv string) ProblemResultProviderOption {
+	return func(prp *ProblemResultProvider) { prp.IncludeStackTrace = env.IsDev(serviceEnv) }
+}
+
+// ProblemResultProvider renders errors as RFC 7807 `application/problem+json` results.
+type ProblemResultProvider struct {
+	Registry          *ProblemRegistry
+	IncludeStackTrace bool
+}
+
+// NotFound returns a service response.
+func (prp ProblemResultProvider) NotFound() Result {
+	return prp.Status(http.StatusNotFound, nil)
+}
+
+// NotAuthorized returns a service response.
+func (prp ProblemResultProvider) NotAuthorized() Result {
+	return prp.Status(http.StatusUnauthorized, nil)
+}
+
+// Forbidden returns a 403 Forbidden response.
+func (prp ProblemResultProvider) Forbidden() Result {
+	return prp.Status(http.StatusForbidden, nil)
+}
+
+// InternalError returns a service response.
+func (prp ProblemResultProvider) InternalError(err error) Result {
+	return ResultWithLoggedError(prp.problemResult(http.StatusInternalServerError, err), err)
+}
+
+// BadRequest returns a service response.
+func (prp ProblemResultProvider) BadRequest(err error) Result {
+	return prp.problemResult(http.StatusBadRequest, err)
+}
+
+// Status returns a problem result for a given status.
+//
+// The response can be a `Problem`, an error, or any other value which is used as the problem detail.
+func (prp ProblemResultProvider) Status(statusCode int, response interface{}) Result {
+	switch typed := response.(type) {
+	case Problem:
+		if typed.Status == 0 {
+			typed.Status = statusCode
+		}
+		return &ProblemResult{Problem: typed}
+	case *Problem:
+		return prp.Status(statusCode, *typed)
+	case error:
+		return prp.problemResult(statusCode, typed)
+	case nil:
+		return &ProblemResult{Problem: Problem{Title: http.StatusText(statusCode), Status: statusCode}}
+	default:
+		return &ProblemResult{Problem: Problem{Title: http.StatusText(statusCode), Status: statusCode, Detail: fmt.Sprint(typed)}}
+	}
+}
+
+// Problem returns a problem result for an error, using the status from the registry or a 500.
+func (prp ProblemResultProvider) Problem(err error) Result {
+	return prp.problemResult(http.StatusInternalServerError, err)
+}
+
+// ProblemFor returns the problem for an error, with a given default status.
+func (prp ProblemResultProvider) ProblemFor(defaultStatus int, err error) Problem {
+	problem := Problem{Status: defaultStatus}
+	if err == nil {
+		problem.Title = http.StatusText(defaultStatus)
+		return problem
+	}
+
+	var problemType ProblemType
+	var ok bool
+	if prp.Registry != nil {
+		problemType, ok = prp.Registry.Lookup(err)
+	}
+	if ok && problemType.Status != 0 {
+		problem.Status = problemType.Status
+	}
+	problem.Type = problemType.Type
+	problem.Title = problemType.Title
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+
+	if inner := validate.ErrInner(err); inner != nil {
+		problem.Detail = inner.Error()
+		if inner.Value != nil {
+			problem.Extensions = map[string]interface{}{ProblemExtensionValue: inner.Value}
+		}
+	} else if message := ex.ErrMessage(err); message != "" {
+		problem.Detail = fmt.Sprintf("%v; %s", ex.ErrClass(err), message)
+	} else if class := ex.ErrClass(err); class != nil {
+		problem.Detail = class.Error()
+	} else {
+		problem.Detail = err.Error()
+	}
+
+	if prp.IncludeStackTrace {
+		if stackTrace := ex.ErrStackTrace(err); stackTrace != nil {
+			if problem.Extensions == nil {
+				problem.Extensions = make(map[string]interface{})
+			}
+			problem.Extensions[ProblemExtensionStackTrace] = stackTrace.Strings()
+		}
+	}
+	return problem
+}
+
+func (prp ProblemResultProvider) problemResult(defaultStatus int, err error) *ProblemResult {
+	return &ProblemResult{Problem: prp.ProblemFor(defaultStatus, err)}
+}
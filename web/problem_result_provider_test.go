@@ -0,0 +1,129 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/env"
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/validate"
+	"github.com/blend/go-sdk/webutil"
+)
+
+func TestProblemResultProvider(t *testing.T) {
+	its := assert.New(t)
+
+	const errConflict ex.Class = "resource conflict"
+	registry := NewProblemRegistry().
+		Register(errConflict, ProblemType{Type: "https://example.com/problems/conflict", Title: "Conflict", Status: http.StatusConflict}).
+		Register(validate.ErrStringRequired, ProblemType{Type: "https://example.com/problems/required", Title: "Required"})
+	provider := NewProblemResultProvider(OptProblemRegistry(registry), OptProblemServiceEnv(env.ServiceEnvProd))
+
+	app := MustNew()
+	app.DefaultProvider = provider
+	app.GET("/conflict", func(ctx *Ctx) Result {
+		return provider.Problem(ex.New(errConflict, ex.OptMessage("widget exists")))
+	})
+	app.GET("/required", func(ctx *Ctx) Result {
+		return ctx.DefaultProvider.BadRequest(validate.String(nil).Required()())
+	})
+	app.GET("/invalid", func(ctx *Ctx) Result {
+		value := 1
+		return ctx.DefaultProvider.BadRequest(validate.Int(&value).Min(5)())
+	})
+	app.GET("/missing", func(ctx *Ctx) Result {
+		return ctx.DefaultProvider.NotFound()
+	})
+	app.GET("/error", func(ctx *Ctx) Result {
+		return ctx.DefaultProvider.InternalError(fmt.Errorf("boom"))
+	})
+
+	var problem Problem
+	meta, err := MockGet(app, "/conflict").JSON(&problem)
+	its.Nil(err)
+	its.Equal(http.StatusConflict, meta.StatusCode)
+	its.Equal(webutil.ContentTypeApplicationProblemJSON, meta.Header.Get(webutil.HeaderContentType))
+	its.Equal("https://example.com/problems/conflict", problem.Type)
+	its.Equal("Conflict", problem.Title)
+	its.Equal(http.StatusConflict, problem.Status)
+	its.Equal("resource conflict; widget exists", problem.Detail)
+	its.Equal("/conflict", problem.Instance)
+	its.Empty(problem.Extensions)
+
+	problem = Problem{}
+	meta, err = MockGet(app, "/required").JSON(&problem)
+	its.Nil(err)
+	its.Equal(http.StatusBadRequest, meta.StatusCode)
+	its.Equal("https://example.com/problems/required", problem.Type)
+	its.Equal("Required", problem.Title)
+	its.Equal(validate.ErrStringRequired.Error(), problem.Detail)
+
+	// unregistered validation causes fall back to the validation error type.
+	problem = Problem{}
+	meta, err = MockGet(app, "/invalid").JSON(&problem)
+	its.Nil(err)
+	its.Equal(http.StatusBadRequest, meta.StatusCode)
+	its.Equal("Validation Error", problem.Title)
+
+	problem = Problem{}
+	meta, err = MockGet(app, "/error").JSON(&problem)
+	its.Nil(err)
+	its.Equal(http.StatusInternalServerError, meta.StatusCode)
+	its.Equal("Internal Server Error", problem.Title)
+	its.Equal("boom", problem.Detail)
+
+	problem = Problem{}
+	meta, err = MockGet(app, "/missing").JSON(&problem)
+	its.Nil(err)
+	its.Equal(http.StatusNotFound, meta.StatusCode)
+	its.Equal("Not Found", problem.Title)
+}
+
+func TestProblemResultProviderStackTrace(t *testing.T) {
+	its := assert.New(t)
+
+	problem := NewProblemResultProvider(OptProblemServiceEnv(env.ServiceEnvDev)).ProblemFor(http.StatusInternalServerError, ex.New("boom"))
+	its.NotEmpty(problem.Extensions[ProblemExtensionStackTrace])
+
+	problem = NewProblemResultProvider(OptProblemServiceEnv(env.ServiceEnvProd)).ProblemFor(http.StatusInternalServerError, ex.New("boom"))
+	its.Nil(problem.Extensions[ProblemExtensionStackTrace])
+}
+
+func TestProblemResultStatusUnset(t *testing.T) {
+	its := assert.New(t)
+
+	app := MustNew()
+	app.GET("/problem", func(_ *Ctx) Result {
+		return &ProblemResult{Problem: Problem{Title: "Unknown"}}
+	})
+
+	var problem Problem
+	meta, err := MockGet(app, "/problem").JSON(&problem)
+	its.Nil(err)
+	its.Equal(http.StatusInternalServerError, meta.StatusCode)
+	its.Equal(http.StatusInternalServerError, problem.Status)
+	its.Equal("Unknown", problem.Title)
+}
+
+func TestProblemJSON(t *testing.T) {
+	its := assert.New(t)
+
+	contents, err := Problem{Status: http.StatusTeapot, Title: "Teapot", Extensions: map[string]interface{}{"balance": 30}}.MarshalJSON()
+	its.Nil(err)
+	its.Equal(`{"balance":30,"status":418,"title":"Teapot","type":"about:blank"}`, string(contents))
+
+	var problem Problem
+	its.Nil(problem.UnmarshalJSON(contents))
+	its.Equal(http.StatusTeapot, problem.Status)
+	its.Equal("about:blank", problem.Type)
+	its.Equal(30.0, problem.Extensions["balance"])
+}
@@ -0,0 +1,22 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+/*
+Package redissession provides a durable session store for `web.AuthManager` backed by redis.
+
+Sessions are stored as json strings with a ttl matching the session expiry, so expired sessions
+are removed by redis itself. Each user also has a set of their session ids, which is used to list or
+remove all sessions for a user; stale members of that set are swept whenever it is read.
+
+	store := redissession.New(redisClient)
+	authManager, err := web.NewAuthManager(web.OptAuthManagerFromConfig(cfg.Web))
+	if err != nil {
+		return err
+	}
+	store.Apply(&authManager)
+*/
+package redissession // import "github.com/blend/go-sdk/web/redissession"
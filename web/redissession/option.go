@@ -0,0 +1,18 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package redissession
+
+// Option mutates a store.
+type Option func(*Store)
+
+// OptKeyPrefix sets the prefix for keys the store writes.
+func OptKeyPrefix(keyPrefix string) Option {
+	return func(s *Store) {
+		s.KeyPrefix = keyPrefix
+	}
+}
@@ -0,0 +1,188 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package redissession
+
+//github:codeowner @blend/infosec
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/redis"
+	"github.com/blend/go-sdk/web"
+)
+
+// New returns a new store for a given client.
+func New(client redis.Client, opts ...Option) *Store {
+	store := Store{
+		Client:    client,
+		KeyPrefix: DefaultKeyPrefix,
+	}
+	for _, opt := range opts {
+		opt(&store)
+	}
+	return &store
+}
+
+// Store persists sessions to redis.
+type Store struct {
+	Client    redis.Client
+	KeyPrefix string
+}
+
+// Apply applies the store to a given auth manager.
+func (s *Store) Apply(am *web.AuthManager) {
+	am.FetchHandler = s.FetchHandler
+	am.PersistHandler = s.PersistHandler
+	am.RemoveHandler = s.RemoveHandler
+}
+
+// FetchHandler is a shim to interface with the auth manager.
+func (s *Store) FetchHandler(ctx context.Context, sessionID string) (*web.Session, error) {
+	return s.get(ctx, sessionID)
+}
+
+// PersistHandler is a shim to interface with the auth manager.
+//
+// The session key is given a ttl matching the session expiry; sessions that have
+// already expired are not written. The user's set of session ids is given a ttl
+// matching the latest expiry of the sessions added to it, so it expires once all of
+// them have, and no ttl while it holds a session that does not expire.
+func (s *Store) PersistHandler(ctx context.Context, session *web.Session) error {
+	contents, err := json.Marshal(session)
+	if err != nil {
+		return ex.New(err)
+	}
+	var ttl time.Duration
+	args := []string{s.sessionKey(session.SessionID), string(contents)}
+	if !session.ExpiresUTC.IsZero() {
+		ttl = time.Until(session.ExpiresUTC)
+		if ttl <= 0 {
+			return nil
+		}
+		args = append(args, "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	}
+	if err = s.Client.Do(ctx, nil, redis.OpSET, args...); err != nil {
+		return err
+	}
+
+	userKey := s.userKey(session.UserID)
+	// the ttl of the set is read before adding to it, as a new set has no ttl (-1) like one without an expiry
+	var userTTL int64
+	if err = s.Client.Do(ctx, &userTTL, redis.OpPTTL, userKey); err != nil {
+		return err
+	}
+	if err = s.Client.Do(ctx, nil, redis.OpSADD, userKey, session.SessionID); err != nil {
+		return err
+	}
+	if ttl == 0 {
+		return s.Client.Do(ctx, nil, redis.OpPERSIST, userKey)
+	}
+	// -2 is a set that did not exist; otherwise only extend an existing ttl
+	if userTTL == -2 || (userTTL >= 0 && userTTL < ttl.Milliseconds()) {
+		return s.Client.Do(ctx, nil, redis.OpPEXPIRE, userKey, strconv.FormatInt(ttl.Milliseconds(), 10))
+	}
+	return nil
+}
+
+// RemoveHandler is a shim to interface with the auth manager.
+func (s *Store) RemoveHandler(ctx context.Context, sessionID string) error {
+	session, err := s.get(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if err = s.Client.Do(ctx, nil, redis.OpDEL, s.sessionKey(sessionID)); err != nil {
+		return err
+	}
+	if session == nil {
+		return nil
+	}
+	return s.Client.Do(ctx, nil, redis.OpSREM, s.userKey(session.UserID), sessionID)
+}
+
+// SessionsForUser returns the unexpired sessions for a given user.
+//
+// Session ids whose sessions have expired are swept from the user's set.
+func (s *Store) SessionsForUser(ctx context.Context, userID string) (output []*web.Session, err error) {
+	var sessionIDs []string
+	if err = s.Client.Do(ctx, &sessionIDs, redis.OpSMEMBERS, s.userKey(userID)); err != nil {
+		return
+	}
+
+	var stale []string
+	var session *web.Session
+	for _, sessionID := range sessionIDs {
+		session, err = s.get(ctx, sessionID)
+		if err != nil {
+			return
+		}
+		if session == nil {
+			stale = append(stale, sessionID)
+			continue
+		}
+		output = append(output, session)
+	}
+	if len(stale) > 0 {
+		err = s.Client.Do(ctx, nil, redis.OpSREM, append([]string{s.userKey(userID)}, stale...)...)
+	}
+	return
+}
+
+// RemoveAllForUser removes every session for a given user, logging them out everywhere.
+//
+// It returns the number of sessions removed.
+func (s *Store) RemoveAllForUser(ctx context.Context, userID string) (int64, error) {
+	var sessionIDs []string
+	if err := s.Client.Do(ctx, &sessionIDs, redis.OpSMEMBERS, s.userKey(userID)); err != nil {
+		return 0, err
+	}
+	var removed int64
+	if len(sessionIDs) > 0 {
+		keys := make([]string, 0, len(sessionIDs))
+		for _, sessionID := range sessionIDs {
+			keys = append(keys, s.sessionKey(sessionID))
+		}
+		if err := s.Client.Do(ctx, &removed, redis.OpDEL, keys...); err != nil {
+			return 0, err
+		}
+	}
+	if err := s.Client.Do(ctx, nil, redis.OpDEL, s.userKey(userID)); err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+//
+// utility methods
+//
+
+func (s *Store) get(ctx context.Context, sessionID string) (*web.Session, error) {
+	var contents string
+	if err := s.Client.Do(ctx, &contents, redis.OpGET, s.sessionKey(sessionID)); err != nil {
+		return nil, err
+	}
+	if contents == "" {
+		return nil, nil
+	}
+	var session web.Session
+	if err := json.Unmarshal([]byte(contents), &session); err != nil {
+		return nil, ex.New(err)
+	}
+	return &session, nil
+}
+
+func (s *Store) sessionKey(sessionID string) string {
+	return s.KeyPrefix + ":id:" + sessionID
+}
+
+func (s *Store) userKey(userID string) string {
+	return s.KeyPrefix + ":user:" + userID
+}
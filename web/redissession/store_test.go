@@ -0,0 +1,230 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package redissession
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/redis"
+	"github.com/blend/go-sdk/web"
+)
+
+func TestStore(t *testing.T) {
+	its := assert.New(t)
+
+	mock := newMockRedis()
+	store := New(redis.MockClientFunc(mock.Do))
+
+	session := web.NewSession("example-string", web.NewSessionID())
+	session.ExpiresUTC = time.Now().UTC().Add(time.Hour)
+	session.State["foo"] = "bar"
+	its.Nil(store.PersistHandler(context.TODO(), session))
+
+	fetched, err := store.FetchHandler(context.TODO(), session.SessionID)
+	its.Nil(err)
+	its.NotNil(fetched)
+	its.Equal(session.UserID, fetched.UserID)
+	its.Equal("bar", fetched.State["foo"])
+
+	its.Nil(store.RemoveHandler(context.TODO(), session.SessionID))
+	fetched, err = store.FetchHandler(context.TODO(), session.SessionID)
+	its.Nil(err)
+	its.Nil(fetched)
+	its.Empty(mock.sets[store.userKey(session.UserID)])
+}
+
+func TestStoreExpired(t *testing.T) {
+	its := assert.New(t)
+
+	mock := newMockRedis()
+	store := New(redis.MockClientFunc(mock.Do))
+
+	session := web.NewSession("example-string", web.NewSessionID())
+	session.ExpiresUTC = time.Now().UTC().Add(-time.Minute)
+	its.Nil(store.PersistHandler(context.TODO(), session))
+
+	fetched, err := store.FetchHandler(context.TODO(), session.SessionID)
+	its.Nil(err)
+	its.Nil(fetched)
+}
+
+func TestStoreSessionsForUser(t *testing.T) {
+	its := assert.New(t)
+
+	mock := newMockRedis()
+	store := New(redis.MockClientFunc(mock.Do), OptKeyPrefix("test"))
+
+	for x := 0; x < 3; x++ {
+		its.Nil(store.PersistHandler(context.TODO(), web.NewSession("example-string", web.NewSessionID())))
+	}
+	its.Nil(store.PersistHandler(context.TODO(), web.NewSession("other-user", web.NewSessionID())))
+
+	stale := web.NewSession("example-string", web.NewSessionID())
+	its.Nil(store.PersistHandler(context.TODO(), stale))
+	mock.Lock()
+	delete(mock.values, store.sessionKey(stale.SessionID))
+	mock.Unlock()
+
+	sessions, err := store.SessionsForUser(context.TODO(), "example-string")
+	its.Nil(err)
+	its.Len(sessions, 3)
+	its.Len(mock.sets["test:user:example-string"], 3, "the stale session should have been swept")
+
+	removed, err := store.RemoveAllForUser(context.TODO(), "example-string")
+	its.Nil(err)
+	its.Equal(3, removed)
+
+	sessions, err = store.SessionsForUser(context.TODO(), "example-string")
+	its.Nil(err)
+	its.Empty(sessions)
+
+	sessions, err = store.SessionsForUser(context.TODO(), "other-user")
+	its.Nil(err)
+	its.Len(sessions, 1)
+}
+
+func TestStoreUserSetExpiry(t *testing.T) {
+	its := assert.New(t)
+
+	mock := newMockRedis()
+	store := New(redis.MockClientFunc(mock.Do))
+	userKey := store.userKey("example-string")
+
+	later := web.NewSession("example-string", web.NewSessionID())
+	later.ExpiresUTC = time.Now().UTC().Add(2 * time.Hour)
+	its.Nil(store.PersistHandler(context.TODO(), later))
+	its.InDelta(float64(2*time.Hour), float64(time.Until(mock.expires[userKey])), float64(time.Minute))
+
+	// a session that expires sooner does not shorten the ttl of the set
+	sooner := web.NewSession("example-string", web.NewSessionID())
+	sooner.ExpiresUTC = time.Now().UTC().Add(time.Hour)
+	its.Nil(store.PersistHandler(context.TODO(), sooner))
+	its.InDelta(float64(2*time.Hour), float64(time.Until(mock.expires[userKey])), float64(time.Minute))
+	its.Len(mock.sets[userKey], 2)
+
+	// a session that does not expire removes the ttl of the set
+	its.Nil(store.PersistHandler(context.TODO(), web.NewSession("example-string", web.NewSessionID())))
+	_, ok := mock.expires[userKey]
+	its.False(ok)
+
+	// the set expires with its sessions
+	mock.Lock()
+	mock.expires[userKey] = time.Now().Add(-time.Second)
+	mock.Unlock()
+	sessions, err := store.SessionsForUser(context.TODO(), "example-string")
+	its.Nil(err)
+	its.Empty(sessions)
+}
+
+func newMockRedis() *mockRedis {
+	return &mockRedis{
+		values:  map[string]string{},
+		expires: map[string]time.Time{},
+		sets:    map[string]map[string]bool{},
+	}
+}
+
+// mockRedis is an in memory implementation of the subset of redis the store uses.
+type mockRedis struct {
+	sync.Mutex
+	values  map[string]string
+	expires map[string]time.Time
+	sets    map[string]map[string]bool
+}
+
+func (mr *mockRedis) Do(_ context.Context, out interface{}, op string, args ...string) error {
+	mr.Lock()
+	defer mr.Unlock()
+
+	switch op {
+	case redis.OpSET:
+		mr.values[args[0]] = args[1]
+		delete(mr.expires, args[0])
+		if len(args) == 4 && args[2] == "PX" {
+			ms, err := strconv.ParseInt(args[3], 10, 64)
+			if err != nil {
+				return err
+			}
+			mr.expires[args[0]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		}
+	case redis.OpGET:
+		if expires, ok := mr.expires[args[0]]; ok && expires.Before(time.Now()) {
+			delete(mr.values, args[0])
+		}
+		*out.(*string) = mr.values[args[0]]
+	case redis.OpDEL:
+		var removed int64
+		for _, key := range args {
+			if _, ok := mr.values[key]; ok {
+				delete(mr.values, key)
+				removed++
+			}
+			if _, ok := mr.sets[key]; ok {
+				delete(mr.sets, key)
+				removed++
+			}
+			delete(mr.expires, key)
+		}
+		if out != nil {
+			*out.(*int64) = removed
+		}
+	case redis.OpPTTL:
+		mr.expireSet(args[0])
+		ttl := int64(-2)
+		if _, ok := mr.sets[args[0]]; ok {
+			ttl = -1
+			if expires, ok := mr.expires[args[0]]; ok {
+				ttl = time.Until(expires).Milliseconds()
+			}
+		}
+		*out.(*int64) = ttl
+	case redis.OpPEXPIRE:
+		ms, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return err
+		}
+		mr.expires[args[0]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+	case redis.OpPERSIST:
+		delete(mr.expires, args[0])
+	case redis.OpSADD:
+		mr.expireSet(args[0])
+		if mr.sets[args[0]] == nil {
+			mr.sets[args[0]] = map[string]bool{}
+		}
+		for _, member := range args[1:] {
+			mr.sets[args[0]][member] = true
+		}
+	case redis.OpSREM:
+		for _, member := range args[1:] {
+			delete(mr.sets[args[0]], member)
+		}
+	case redis.OpSMEMBERS:
+		mr.expireSet(args[0])
+		var members []string
+		for member := range mr.sets[args[0]] {
+			members = append(members, member)
+		}
+		*out.(*[]string) = members
+	default:
+		return fmt.Errorf("unexpected op: %s", op)
+	}
+	return nil
+}
+
+func (mr *mockRedis) expireSet(key string) {
+	if expires, ok := mr.expires[key]; ok && expires.Before(time.Now()) {
+		delete(mr.sets, key)
+		delete(mr.expires, key)
+	}
+}
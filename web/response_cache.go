@@ -0,0 +1,293 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blend/go-sdk/cache"
+	"github.com/blend/go-sdk/webutil"
+)
+
+// ResponseCache returns a middleware that buffers the output of an action, adds a strong etag,
+// and responds to conditional requests (`If-None-Match` and `If-Modified-Since`) with a 304.
+//
+// If a cache is provided with `OptResponseCacheStore`, successful responses are stored keyed by
+// method, path, query and the configured `Vary` headers, and served from the cache until they expire.
+// Requests with `Authorization` or `Cookie` headers bypass the cache, unless those headers are
+// configured to vary on, as do responses with a `Cache-Control: private` header set by the action.
+//
+// Only `GET` and `HEAD` requests with a `200` response are considered. Responses that set cookies,
+// or that have a `Cache-Control: no-store` header set by the action, are written through untouched.
+func ResponseCache(opts ...ResponseCacheOption) Middleware {
+	var options ResponseCacheOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return func(action Action) Action {
+		return func(r *Ctx) Result {
+			if r.Request.Method != webutil.MethodGet && r.Request.Method != http.MethodHead {
+				return action(r)
+			}
+
+			var key string
+			if options.Cache != nil && options.shared(r.Request) {
+				key = options.cacheKey(r.Request)
+				if value, ok := options.Cache.Get(key); ok {
+					if entry, ok := value.(*responseCacheEntry); ok {
+						return entry
+					}
+				}
+			}
+
+			buffer := &responseCacheWriter{
+				innerResponse: r.Response,
+				body:          new(bytes.Buffer),
+			}
+			r.Response = buffer
+			result := action(r)
+			r.Response = buffer.innerResponse
+
+			return &responseCacheResult{
+				Result:  result,
+				Options: options,
+				Key:     key,
+				Writer:  buffer,
+			}
+		}
+	}
+}
+
+// shared returns if responses to a request can be shared with other requests, i.e.
+// if it does not carry credentials that are not part of the cache key.
+func (rco ResponseCacheOptions) shared(req *http.Request) bool {
+	for _, header := range []string{webutil.HeaderAuthorization, webutil.HeaderCookie} {
+		if len(req.Header.Values(header)) == 0 {
+			continue
+		}
+		var varies bool
+		for _, vary := range rco.Vary {
+			if vary == header {
+				varies = true
+				break
+			}
+		}
+		if !varies {
+			return false
+		}
+	}
+	return true
+}
+
+// cacheKey returns the cache key for a given request.
+func (rco ResponseCacheOptions) cacheKey(req *http.Request) string {
+	key := new(strings.Builder)
+	key.WriteString(req.Method)
+	key.WriteString(" ")
+	key.WriteString(req.URL.Path)
+	key.WriteString("?")
+	key.WriteString(req.URL.RawQuery)
+	for _, header := range rco.Vary {
+		key.WriteString("\n")
+		key.WriteString(header)
+		key.WriteString(": ")
+		key.WriteString(strings.Join(req.Header.Values(header), ","))
+	}
+	return key.String()
+}
+
+// responseCacheResult renders a result into a buffer and then writes it as a cache entry.
+type responseCacheResult struct {
+	Result  Result
+	Options ResponseCacheOptions
+	Key     string
+	Writer  *responseCacheWriter
+}
+
+// PreRender implements ResultPreRender by delegating to the inner result.
+func (rcr *responseCacheResult) PreRender(ctx *Ctx) error {
+	if typed, ok := rcr.Result.(ResultPreRender); ok {
+		return typed.PreRender(ctx)
+	}
+	return nil
+}
+
+// PostRender implements ResultPostRender by delegating to the inner result.
+func (rcr *responseCacheResult) PostRender(ctx *Ctx) error {
+	if typed, ok := rcr.Result.(ResultPostRender); ok {
+		return typed.PostRender(ctx)
+	}
+	return nil
+}
+
+// Render implements Result.
+func (rcr *responseCacheResult) Render(ctx *Ctx) (err error) {
+	if rcr.Result != nil {
+		ctx.Response = rcr.Writer
+		err = rcr.Result.Render(ctx)
+		ctx.Response = rcr.Writer.innerResponse
+	}
+
+	header := ctx.Response.Header()
+	statusCode := rcr.Writer.StatusCode()
+	if err != nil || statusCode != http.StatusOK ||
+		len(header.Values(webutil.HeaderSetCookie)) > 0 ||
+		webutil.HeaderAny(header, webutil.HeaderCacheControl, "no-store") {
+		if statusCode != http.StatusOK {
+			ctx.Response.WriteHeader(statusCode)
+		}
+		_, _ = ctx.Response.Write(rcr.Writer.body.Bytes())
+		return
+	}
+
+	entry := &responseCacheEntry{
+		Header: header.Clone(),
+		Body:   rcr.Writer.body.Bytes(),
+	}
+	if entry.Header.Get(webutil.HeaderETag) == "" {
+		entry.Header.Set(webutil.HeaderETag, strconv.Quote(webutil.ETag(entry.Body)))
+	}
+	for _, vary := range rcr.Options.Vary {
+		if !webutil.HeaderAny(entry.Header, webutil.HeaderVary, vary) {
+			entry.Header.Add(webutil.HeaderVary, vary)
+		}
+	}
+	if entry.Header.Get(webutil.HeaderLastModified) == "" {
+		entry.Header.Set(webutil.HeaderLastModified, time.Now().UTC().Format(http.TimeFormat))
+	}
+	if rcr.Key != "" && !webutil.HeaderAny(entry.Header, webutil.HeaderCacheControl, "private") {
+		var valueOptions []cache.ValueOption
+		if rcr.Options.TTL > 0 {
+			valueOptions = append(valueOptions, cache.OptValueTTL(rcr.Options.TTL))
+		}
+		rcr.Options.Cache.Set(rcr.Key, entry, valueOptions...)
+	}
+	return entry.Render(ctx)
+}
+
+// responseCacheEntry is a buffered response.
+type responseCacheEntry struct {
+	Header http.Header
+	Body   []byte
+}
+
+// Render implements Result.
+func (rce *responseCacheEntry) Render(ctx *Ctx) error {
+	header := ctx.Response.Header()
+	for key, values := range rce.Header {
+		header[key] = append([]string(nil), values...)
+	}
+	if rce.NotModified(ctx.Request) {
+		header.Del(webutil.HeaderContentType)
+		header.Del(webutil.HeaderContentLength)
+		ctx.Response.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+	header.Set(webutil.HeaderContentLength, strconv.Itoa(len(rce.Body)))
+	ctx.Response.WriteHeader(http.StatusOK)
+	if ctx.Request.Method == http.MethodHead {
+		return nil
+	}
+	_, err := ctx.Response.Write(rce.Body)
+	return err
+}
+
+// NotModified returns if the request preconditions indicate the client already has the entry.
+//
+// Per RFC 7232, `If-Modified-Since` is only evaluated if `If-None-Match` is not present.
+func (rce *responseCacheEntry) NotModified(req *http.Request) bool {
+	if ifNoneMatch := req.Header.Get(webutil.HeaderIfNoneMatch); ifNoneMatch != "" {
+		return etagMatches(ifNoneMatch, rce.Header.Get(webutil.HeaderETag))
+	}
+	ifModifiedSince := req.Header.Get(webutil.HeaderIfModifiedSince)
+	lastModified := rce.Header.Get(webutil.HeaderLastModified)
+	if ifModifiedSince == "" || lastModified == "" {
+		return false
+	}
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.After(since)
+}
+
+// etagMatches returns if any of the etags in an `If-None-Match` header value
+// match a given etag using the weak comparison function.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	_ ResponseWriter = (*responseCacheWriter)(nil)
+)
+
+// responseCacheWriter buffers the body and status code of a response.
+//
+// Headers are written directly to the inner response.
+type responseCacheWriter struct {
+	innerResponse ResponseWriter
+	statusCode    int
+	body          *bytes.Buffer
+}
+
+// Header implements http.ResponseWriter.
+func (rcw *responseCacheWriter) Header() http.Header {
+	return rcw.innerResponse.Header()
+}
+
+// Write implements http.ResponseWriter.
+func (rcw *responseCacheWriter) Write(contents []byte) (int, error) {
+	return rcw.body.Write(contents)
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (rcw *responseCacheWriter) WriteHeader(statusCode int) {
+	rcw.statusCode = statusCode
+}
+
+// Flush is a no-op; the response is written once it has been fully buffered.
+func (rcw *responseCacheWriter) Flush() {}
+
+// Close is a no-op.
+func (rcw *responseCacheWriter) Close() error { return nil }
+
+// StatusCode returns the status code, defaulting to 200.
+func (rcw *responseCacheWriter) StatusCode() int {
+	if rcw.statusCode == 0 {
+		return http.StatusOK
+	}
+	return rcw.statusCode
+}
+
+// ContentLength returns the buffered content length.
+func (rcw *responseCacheWriter) ContentLength() int {
+	return rcw.body.Len()
+}
+
+// InnerResponse returns the inner response.
+func (rcw *responseCacheWriter) InnerResponse() http.ResponseWriter {
+	return rcw.innerResponse
+}
@@ -0,0 +1,56 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/blend/go-sdk/cache"
+)
+
+// ResponseCacheOption is an option for the response cache middleware.
+type ResponseCacheOption func(*ResponseCacheOptions)
+
+// OptResponseCacheStore sets the cache responses are stored in.
+//
+// If unset, responses are not stored, but etags are still computed
+// and conditional requests are still honored.
+func OptResponseCacheStore(store cache.Cache) ResponseCacheOption {
+	return func(rco *ResponseCacheOptions) {
+		rco.Cache = store
+	}
+}
+
+// OptResponseCacheTTL sets the time to live for stored responses.
+func OptResponseCacheTTL(ttl time.Duration) ResponseCacheOption {
+	return func(rco *ResponseCacheOptions) {
+		rco.TTL = ttl
+	}
+}
+
+// OptResponseCacheVary sets the request headers that are included in the cache key.
+//
+// The headers are also added to the `Vary` response header.
+func OptResponseCacheVary(headers ...string) ResponseCacheOption {
+	return func(rco *ResponseCacheOptions) {
+		for _, header := range headers {
+			rco.Vary = append(rco.Vary, http.CanonicalHeaderKey(header))
+		}
+	}
+}
+
+// ResponseCacheOptions are the options for the response cache middleware.
+type ResponseCacheOptions struct {
+	// Cache is the optional store for rendered responses.
+	Cache cache.Cache
+	// TTL is how long responses are stored for; if unset they are stored until evicted.
+	TTL time.Duration
+	// Vary are request headers that distinguish otherwise identical requests.
+	Vary []string
+}
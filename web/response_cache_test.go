@@ -0,0 +1,205 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/cache"
+	"github.com/blend/go-sdk/r2"
+	"github.com/blend/go-sdk/webutil"
+)
+
+func TestResponseCacheETag(t *testing.T) {
+	assert := assert.New(t)
+
+	var calls int32
+	app := MustNew()
+	app.GET("/", func(_ *Ctx) Result {
+		atomic.AddInt32(&calls, 1)
+		return JSON.Result(map[string]string{"foo": "bar"})
+	}, ResponseCache())
+
+	contents, meta, err := MockGet(app, "/").Bytes()
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, meta.StatusCode)
+	assert.Equal("{\"foo\":\"bar\"}\n", string(contents))
+	assert.Equal(webutil.ContentTypeApplicationJSON, meta.Header.Get(webutil.HeaderContentType))
+	etag := meta.Header.Get(webutil.HeaderETag)
+	assert.NotEmpty(etag)
+	assert.Equal(`"`+webutil.ETag(contents)+`"`, etag)
+	lastModified := meta.Header.Get(webutil.HeaderLastModified)
+	assert.NotEmpty(lastModified)
+
+	contents, meta, err = MockGet(app, "/", r2.OptHeaderValue(webutil.HeaderIfNoneMatch, `"nope", `+etag)).Bytes()
+	assert.Nil(err)
+	assert.Equal(http.StatusNotModified, meta.StatusCode)
+	assert.Empty(contents)
+
+	_, meta, err = MockGet(app, "/", r2.OptHeaderValue(webutil.HeaderIfNoneMatch, `"nope"`)).Bytes()
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, meta.StatusCode)
+
+	_, meta, err = MockGet(app, "/", r2.OptHeaderValue(webutil.HeaderIfModifiedSince, lastModified)).Bytes()
+	assert.Nil(err)
+	assert.Equal(http.StatusNotModified, meta.StatusCode)
+	assert.Equal(4, atomic.LoadInt32(&calls), "responses should not be stored without a cache")
+}
+
+func TestResponseCacheStore(t *testing.T) {
+	assert := assert.New(t)
+
+	var calls int32
+	app := MustNew()
+	app.GET("/", func(r *Ctx) Result {
+		atomic.AddInt32(&calls, 1)
+		return Text.Result(r.Request.Header.Get("X-Tenant"))
+	}, ResponseCache(
+		OptResponseCacheStore(cache.New()),
+		OptResponseCacheTTL(time.Minute),
+		OptResponseCacheVary("x-tenant"),
+	))
+
+	contents, meta, err := MockGet(app, "/", r2.OptHeaderValue("X-Tenant", "one")).Bytes()
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, meta.StatusCode)
+	assert.Equal("one", string(contents))
+	assert.Equal("X-Tenant", meta.Header.Get(webutil.HeaderVary))
+	lastModified := meta.Header.Get(webutil.HeaderLastModified)
+	assert.NotEmpty(lastModified)
+
+	contents, meta, err = MockGet(app, "/", r2.OptHeaderValue("X-Tenant", "one")).Bytes()
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, meta.StatusCode)
+	assert.Equal("one", string(contents))
+	assert.Equal(1, atomic.LoadInt32(&calls))
+
+	contents, _, err = MockGet(app, "/", r2.OptHeaderValue("X-Tenant", "two")).Bytes()
+	assert.Nil(err)
+	assert.Equal("two", string(contents))
+	assert.Equal(2, atomic.LoadInt32(&calls))
+
+	contents, _, err = MockGet(app, "/", r2.OptQueryValue("foo", "bar"), r2.OptHeaderValue("X-Tenant", "one")).Bytes()
+	assert.Nil(err)
+	assert.Equal("one", string(contents))
+	assert.Equal(3, atomic.LoadInt32(&calls), "the query should be part of the key")
+
+	_, meta, err = MockGet(app, "/",
+		r2.OptHeaderValue("X-Tenant", "one"),
+		r2.OptHeaderValue(webutil.HeaderIfModifiedSince, lastModified),
+	).Bytes()
+	assert.Nil(err)
+	assert.Equal(http.StatusNotModified, meta.StatusCode)
+	assert.Equal(3, atomic.LoadInt32(&calls))
+}
+
+func TestResponseCacheNoStore(t *testing.T) {
+	assert := assert.New(t)
+
+	var calls int32
+	store := cache.New()
+	app := MustNew()
+	app.GET("/no-store", func(r *Ctx) Result {
+		atomic.AddInt32(&calls, 1)
+		r.Response.Header().Set(webutil.HeaderCacheControl, "no-store")
+		return Text.Result("no-store")
+	}, ResponseCache(OptResponseCacheStore(store)))
+	app.GET("/not-found", func(r *Ctx) Result {
+		atomic.AddInt32(&calls, 1)
+		return Text.NotFound()
+	}, ResponseCache(OptResponseCacheStore(store)))
+	app.POST("/post", func(r *Ctx) Result {
+		atomic.AddInt32(&calls, 1)
+		return Text.Result("post")
+	}, ResponseCache(OptResponseCacheStore(store)))
+
+	for x := 0; x < 2; x++ {
+		contents, meta, err := MockGet(app, "/no-store").Bytes()
+		assert.Nil(err)
+		assert.Equal(http.StatusOK, meta.StatusCode)
+		assert.Equal("no-store", string(contents))
+		assert.Empty(meta.Header.Get(webutil.HeaderETag))
+
+		_, meta, err = MockGet(app, "/not-found").Bytes()
+		assert.Nil(err)
+		assert.Equal(http.StatusNotFound, meta.StatusCode)
+
+		contents, meta, err = MockMethod(app, http.MethodPost, "/post").Bytes()
+		assert.Nil(err)
+		assert.Equal(http.StatusOK, meta.StatusCode)
+		assert.Equal("post", string(contents))
+	}
+	assert.Equal(6, atomic.LoadInt32(&calls))
+	assert.Zero(store.Stats().Count)
+}
+
+func TestResponseCachePrivate(t *testing.T) {
+	assert := assert.New(t)
+
+	var calls int32
+	store := cache.New()
+	app := MustNew()
+	app.GET("/user", func(r *Ctx) Result {
+		atomic.AddInt32(&calls, 1)
+		return Text.Result("user " + r.Request.Header.Get(webutil.HeaderAuthorization) + r.Request.Header.Get(webutil.HeaderCookie))
+	}, ResponseCache(OptResponseCacheStore(store)))
+	app.GET("/private", func(r *Ctx) Result {
+		atomic.AddInt32(&calls, 1)
+		r.Response.Header().Set(webutil.HeaderCacheControl, "private, max-age=60")
+		return Text.Result("private")
+	}, ResponseCache(OptResponseCacheStore(store)))
+	app.GET("/vary", func(r *Ctx) Result {
+		atomic.AddInt32(&calls, 1)
+		return Text.Result("vary " + r.Request.Header.Get(webutil.HeaderAuthorization))
+	}, ResponseCache(OptResponseCacheStore(store), OptResponseCacheVary(webutil.HeaderAuthorization)))
+
+	contents, meta, err := MockGet(app, "/user", r2.OptHeaderValue(webutil.HeaderAuthorization, "Bearer one")).Bytes()
+	assert.Nil(err)
+	assert.Equal("user Bearer one", string(contents))
+	assert.NotEmpty(meta.Header.Get(webutil.HeaderETag), "etags are still computed")
+	contents, _, err = MockGet(app, "/user", r2.OptHeaderValue(webutil.HeaderCookie, "session=two")).Bytes()
+	assert.Nil(err)
+	assert.Equal("user session=two", string(contents))
+	contents, _, err = MockGet(app, "/user").Bytes()
+	assert.Nil(err)
+	assert.Equal("user ", string(contents))
+	assert.Equal(3, atomic.LoadInt32(&calls))
+	assert.Equal(1, store.Stats().Count, "only the anonymous response should be stored")
+
+	for x := 0; x < 2; x++ {
+		contents, _, err = MockGet(app, "/private").Bytes()
+		assert.Nil(err)
+		assert.Equal("private", string(contents))
+	}
+	assert.Equal(5, atomic.LoadInt32(&calls))
+
+	for x := 0; x < 2; x++ {
+		contents, _, err = MockGet(app, "/vary", r2.OptHeaderValue(webutil.HeaderAuthorization, "Bearer one")).Bytes()
+		assert.Nil(err)
+		assert.Equal("vary Bearer one", string(contents))
+	}
+	contents, _, err = MockGet(app, "/vary", r2.OptHeaderValue(webutil.HeaderAuthorization, "Bearer two")).Bytes()
+	assert.Nil(err)
+	assert.Equal("vary Bearer two", string(contents))
+	assert.Equal(7, atomic.LoadInt32(&calls), "credentials that are varied on should be stored per value")
+}
+
+func TestETagMatches(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(etagMatches(`"foo"`, `"foo"`))
+	assert.True(etagMatches(`W/"foo"`, `"foo"`))
+	assert.True(etagMatches(`"bar", "foo"`, `"foo"`))
+	assert.True(etagMatches(`*`, `"foo"`))
+	assert.False(etagMatches(`"bar"`, `"foo"`))
+	assert.False(etagMatches(`"foo"`, ""))
+}
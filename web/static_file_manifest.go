@@ -0,0 +1,106 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"encoding/json"
+	"io/fs"
+	"mime"
+	"path"
+	"strings"
+
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/webutil"
+)
+
+// StaticFileManifestPath is the path of the manifest `App.ServeStaticCachedFS` reads from each filesystem.
+const StaticFileManifestPath = "static-manifest.json"
+
+// NewStaticFileManifest computes a manifest for every file in the given filesystem(s).
+//
+// If a path exists in more than one filesystem, the entry is for the first, matching the
+// order the static fileserver searches them in.
+//
+// It can be run at build time (e.g. from a `go:generate` step) with the result written
+// to a file that is embedded next to the assets (see `StaticFileManifestPath`), so that etags
+// do not need to be computed on start.
+func NewStaticFileManifest(filesystems ...fs.FS) (StaticFileManifest, error) {
+	manifest := make(StaticFileManifest)
+	for _, fsys := range filesystems {
+		err := fs.WalkDir(fsys, ".", func(filePath string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			if _, ok := manifest[filePath]; ok {
+				return nil
+			}
+			contents, err := fs.ReadFile(fsys, filePath)
+			if err != nil {
+				return err
+			}
+			manifest[filePath] = StaticFileManifestEntry{
+				ETag:        webutil.ETag(contents),
+				ContentType: mime.TypeByExtension(path.Ext(filePath)),
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, ex.New(err)
+		}
+	}
+	return manifest, nil
+}
+
+// ReadStaticFileManifest reads a json manifest at a given path from a filesystem.
+func ReadStaticFileManifest(fsys fs.FS, manifestPath string) (StaticFileManifest, error) {
+	contents, err := fs.ReadFile(fsys, manifestPath)
+	if err != nil {
+		return nil, ex.New(err)
+	}
+	var manifest StaticFileManifest
+	if err = json.Unmarshal(contents, &manifest); err != nil {
+		return nil, ex.New(err)
+	}
+	return manifest, nil
+}
+
+// StaticFileManifest holds precomputed metadata for static files by path.
+//
+// Paths are relative to the root of the filesystem and do not have a leading slash.
+type StaticFileManifest map[string]StaticFileManifestEntry
+
+// Get returns the entry for a given file path.
+func (sfm StaticFileManifest) Get(filePath string) (entry StaticFileManifestEntry, ok bool) {
+	if sfm == nil {
+		return
+	}
+	entry, ok = sfm[strings.TrimPrefix(path.Clean("/"+filePath), "/")]
+	return
+}
+
+// Merge returns a manifest with the entries of both manifests; entries already
+// in the manifest take precedence.
+func (sfm StaticFileManifest) Merge(other StaticFileManifest) StaticFileManifest {
+	output := make(StaticFileManifest, len(sfm)+len(other))
+	for key, value := range other {
+		output[key] = value
+	}
+	for key, value := range sfm {
+		output[key] = value
+	}
+	return output
+}
+
+// StaticFileManifestEntry is the precomputed metadata for a static file.
+type StaticFileManifestEntry struct {
+	ETag        string `json:"etag,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+}
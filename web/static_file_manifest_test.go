@@ -0,0 +1,72 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/webutil"
+)
+
+func TestStaticFileManifest(t *testing.T) {
+	assert := assert.New(t)
+
+	assets := fstest.MapFS{
+		"index.html":    &fstest.MapFile{Data: []byte("<html></html>")},
+		"css/site.css":  &fstest.MapFile{Data: []byte("body {}")},
+		"img/empty.bin": &fstest.MapFile{Data: []byte{}},
+	}
+
+	manifest, err := NewStaticFileManifest(assets)
+	assert.Nil(err)
+	assert.Len(manifest, 3)
+
+	entry, ok := manifest.Get("/css/site.css")
+	assert.True(ok)
+	assert.Equal(webutil.ETag([]byte("body {}")), entry.ETag)
+	assert.Equal("text/css; charset=utf-8", entry.ContentType)
+
+	_, ok = manifest.Get("css/../index.html")
+	assert.True(ok)
+	_, ok = manifest.Get("missing.html")
+	assert.False(ok)
+	_, ok = StaticFileManifest(nil).Get("index.html")
+	assert.False(ok)
+
+	contents, err := json.Marshal(manifest)
+	assert.Nil(err)
+	assets["manifest.json"] = &fstest.MapFile{Data: contents}
+
+	read, err := ReadStaticFileManifest(assets, "manifest.json")
+	assert.Nil(err)
+	assert.Equal(manifest, read)
+
+	_, err = ReadStaticFileManifest(assets, "not-a-manifest.json")
+	assert.NotNil(err)
+}
+
+func TestStaticFileManifestFilesystems(t *testing.T) {
+	assert := assert.New(t)
+
+	first := fstest.MapFS{
+		"index.html": &fstest.MapFile{Data: []byte("first")},
+	}
+	second := fstest.MapFS{
+		"index.html": &fstest.MapFile{Data: []byte("second")},
+		"site.css":   &fstest.MapFile{Data: []byte("body {}")},
+	}
+
+	manifest, err := NewStaticFileManifest(first, second)
+	assert.Nil(err)
+	assert.Len(manifest, 2)
+	assert.Equal(webutil.ETag([]byte("first")), manifest["index.html"].ETag)
+	assert.Equal(webutil.ETag([]byte("body {}")), manifest["site.css"].ETag)
+}
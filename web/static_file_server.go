@@ -10,6 +10,7 @@ package web
 import (
 	"bytes"
 	"io"
+	"io/fs"
 	"net/http"
 	"os"
 	"regexp"
@@ -38,6 +39,22 @@ func OptStaticFileServerSearchPaths(searchPaths ...http.FileSystem) StaticFilese
 	}
 }
 
+// OptStaticFileServerFS adds filesystems, such as an `embed.FS` or `os.DirFS`, to the static fileserver search paths.
+func OptStaticFileServerFS(filesystems ...fs.FS) StaticFileserverOption {
+	return func(sfs *StaticFileServer) {
+		for _, fsys := range filesystems {
+			sfs.SearchPaths = append(sfs.SearchPaths, http.FS(fsys))
+		}
+	}
+}
+
+// OptStaticFileServerManifest sets the static fileserver precomputed file metadata.
+func OptStaticFileServerManifest(manifest StaticFileManifest) StaticFileserverOption {
+	return func(sfs *StaticFileServer) {
+		sfs.Manifest = manifest
+	}
+}
+
 // OptStaticFileServerHeaders sets the static fileserver default headers..
 func OptStaticFileServerHeaders(headers http.Header) StaticFileserverOption {
 	return func(sfs *StaticFileServer) {
@@ -56,12 +73,15 @@ func OptStaticFileServerCacheDisabled(cacheDisabled bool) StaticFileserverOption
 // It can operate in cached mode, or with `CacheDisabled` set to `true`
 // it will read from disk for each request.
 // In cached mode, it automatically adds etags for files it caches.
+// If a manifest is provided, etags and content types are read from it
+// in both modes instead of being computed.
 type StaticFileServer struct {
 	sync.RWMutex
 
 	SearchPaths   []http.FileSystem
 	RewriteRules  []RewriteRule
 	Headers       http.Header
+	Manifest      StaticFileManifest
 	CacheDisabled bool
 	Cache         map[string]*CachedStaticFile
 }
@@ -133,6 +153,15 @@ func (sc *StaticFileServer) ServeFile(r *Ctx, filePath string) Result {
 		return r.DefaultProvider.NotFound()
 	}
 
+	if entry, ok := sc.Manifest.Get(sc.rewrite(filePath)); ok {
+		if entry.ETag != "" {
+			r.Response.Header().Set(webutil.HeaderETag, entry.ETag)
+		}
+		if entry.ContentType != "" {
+			r.Response.Header().Set(webutil.HeaderContentType, entry.ContentType)
+		}
+	}
+
 	r.WithContext(logger.WithLabel(r.Context(), "web.static_file", finalPath))
 	http.ServeContent(r.Response, r.Request, filePath, finfo.ModTime(), f)
 	return nil
@@ -156,11 +185,7 @@ func (sc *StaticFileServer) ServeCachedFile(r *Ctx, filepath string) Result {
 // First the file path is modified according to the rewrite rules.
 // Then each search path is checked for the resolved file path.
 func (sc *StaticFileServer) ResolveFile(filePath string) (f http.File, finalPath string, err error) {
-	for _, rule := range sc.RewriteRules {
-		if matched, newFilePath := rule.Apply(filePath); matched {
-			filePath = newFilePath
-		}
-	}
+	filePath = sc.rewrite(filePath)
 	for _, searchPath := range sc.SearchPaths {
 		f, err = searchPath.Open(filePath)
 		if typed, ok := f.(*os.File); ok && typed != nil {
@@ -234,14 +259,29 @@ func (sc *StaticFileServer) ResolveCachedFile(filepath string) (*CachedStaticFil
 		Path:     filepath,
 		Contents: bytes.NewReader(contents),
 		ModTime:  finfo.ModTime(),
-		ETag:     webutil.ETag(contents),
 		Size:     len(contents),
 	}
+	if entry, ok := sc.Manifest.Get(sc.rewrite(filepath)); ok {
+		file.ETag = entry.ETag
+		file.ContentType = entry.ContentType
+	}
+	if file.ETag == "" {
+		file.ETag = webutil.ETag(contents)
+	}
 
 	sc.Cache[filepath] = file
 	return file, nil
 }
 
+func (sc *StaticFileServer) rewrite(filePath string) string {
+	for _, rule := range sc.RewriteRules {
+		if matched, newFilePath := rule.Apply(filePath); matched {
+			filePath = newFilePath
+		}
+	}
+	return filePath
+}
+
 func (sc *StaticFileServer) fileError(r *Ctx, err error) Result {
 	if os.IsNotExist(err) {
 		if r.DefaultProvider != nil {
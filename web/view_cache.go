@@ -9,6 +9,7 @@ package web
 
 import (
 	"html/template"
+	"io/fs"
 	"net/http"
 	"sync"
 
@@ -81,6 +82,8 @@ type ViewCache struct {
 	LiveReload bool
 	FuncMap    template.FuncMap
 	Paths      []string
+	FS         fs.FS
+	FSPaths    []string
 	Literals   []string
 	Templates  *template.Template
 	BufferPool *bufferutil.Pool
@@ -103,6 +106,8 @@ func (vc *ViewCache) Initialize() error {
 }
 
 // Parse parses the view tree.
+//
+// `Paths` are read from disk, and `FSPaths` are glob patterns within `FS`.
 func (vc *ViewCache) Parse() (views *template.Template, err error) {
 	views = template.New("").Funcs(vc.FuncMap)
 	if len(vc.Paths) > 0 {
@@ -112,6 +117,13 @@ func (vc *ViewCache) Parse() (views *template.Template, err error) {
 			return
 		}
 	}
+	if vc.FS != nil && len(vc.FSPaths) > 0 {
+		views, err = views.ParseFS(vc.FS, vc.FSPaths...)
+		if err != nil {
+			err = ex.New(err)
+			return
+		}
+	}
 
 	if len(vc.Literals) > 0 {
 		for _, viewLiteral := range vc.Literals {
@@ -288,7 +300,7 @@ func (vc *ViewCache) viewError(err error) Result {
 }
 
 func (vc *ViewCache) initialize() error {
-	if len(vc.Paths) == 0 && len(vc.Literals) == 0 {
+	if len(vc.Paths) == 0 && len(vc.FSPaths) == 0 && len(vc.Literals) == 0 {
 		return nil
 	}
 	views, err := vc.Parse()
@@ -9,6 +9,7 @@ package web
 
 import (
 	"context"
+	"io/fs"
 
 	"github.com/blend/go-sdk/env"
 )
@@ -19,6 +20,9 @@ type ViewCacheConfig struct {
 	LiveReload bool `json:"liveReload,omitempty" yaml:"liveReload,omitempty" env:"LIVE_RELOAD"`
 	// Paths are a list of view paths to include in the templates list.
 	Paths []string `json:"paths,omitempty" yaml:"paths,omitempty"`
+	// FS is an optional filesystem, such as an `embed.FS`, that paths are resolved against.
+	// If unset, paths are read from disk.
+	FS fs.FS `json:"-" yaml:"-"`
 	// BufferPoolSize is the size of the re-usable buffer pool for rendering views.
 	BufferPoolSize int `json:"bufferPoolSize,omitempty" yaml:"bufferPoolSize,omitempty"`
 
@@ -7,7 +7,10 @@ Use of this source code is governed by a MIT license that can be found in the LI
 
 package web
 
-import "html/template"
+import (
+	"html/template"
+	"io/fs"
+)
 
 // ViewCacheOption is an option for ViewCache.
 type ViewCacheOption func(*ViewCache) error
@@ -17,6 +20,14 @@ func OptViewCachePaths(paths ...string) ViewCacheOption {
 	return func(vc *ViewCache) error { vc.Paths = append(vc.Paths, paths...); return nil }
 }
 
+// OptViewCacheFS sets the filesystem views are read from, and the paths (or glob patterns) within it.
+//
+// It replaces any filesystem and filesystem paths set before; paths on disk set with `OptViewCachePaths` are kept.
+// Use an `embed.FS` to ship views in the binary, or `os.DirFS` with live reload during development.
+func OptViewCacheFS(fsys fs.FS, paths ...string) ViewCacheOption {
+	return func(vc *ViewCache) error { vc.FS = fsys; vc.FSPaths = paths; return nil }
+}
+
 // OptViewCacheLiterals sets the view cache literals.
 func OptViewCacheLiterals(literals ...string) ViewCacheOption {
 	return func(vc *ViewCache) error { vc.Literals = append(vc.Literals, literals...); return nil }
@@ -71,7 +82,12 @@ func OptViewCacheStatusTemplateName(name string) ViewCacheOption {
 // OptViewCacheConfig sets options based on a config.
 func OptViewCacheConfig(cfg *ViewCacheConfig) ViewCacheOption {
 	return func(vc *ViewCache) error {
-		vc.Paths = cfg.Paths
+		if cfg.FS != nil {
+			vc.FS = cfg.FS
+			vc.FSPaths = cfg.Paths
+		} else {
+			vc.Paths = cfg.Paths
+		}
 		vc.LiveReload = cfg.LiveReload
 		vc.InternalErrorTemplateName = cfg.InternalErrorTemplateNameOrDefault()
 		vc.BadRequestTemplateName = cfg.BadRequestTemplateNameOrDefault()
@@ -12,7 +12,10 @@ import (
 	"fmt"
 	"html/template"
 	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
+	"testing/fstest"
 
 	"github.com/blend/go-sdk/assert"
 	"github.com/blend/go-sdk/ex"
@@ -313,3 +316,71 @@ func TestViewCacheFuncs(t *testing.T) {
 	assert.Nil(opt(vc))
 	assert.Empty(vc.FuncMap)
 }
+
+func TestViewCacheFS(t *testing.T) {
+	assert := assert.New(t)
+
+	views := fstest.MapFS{
+		"_views/index.html":  &fstest.MapFile{Data: []byte(`{{ define "index" }}index {{ template "footer" . }}{{ end }}`)},
+		"_views/footer.html": &fstest.MapFile{Data: []byte(`{{ define "footer" }}footer {{ .ViewModel }}{{ end }}`)},
+	}
+
+	vc := MustNewViewCache(OptViewCacheFS(views, "_views/*.html"))
+	assert.Equal(views, vc.FS)
+	assert.Nil(vc.Initialize())
+	assert.NotNil(vc.Templates)
+
+	buffer := new(bytes.Buffer)
+	result := vc.View("index", "foo")
+	assert.Nil(result.Render(MockCtxWithBuffer(http.MethodGet, "/", buffer)))
+	assert.Equal("index footer foo", buffer.String())
+}
+
+func TestViewCacheFSAndPaths(t *testing.T) {
+	assert := assert.New(t)
+
+	footerPath := filepath.Join(t.TempDir(), "footer.html")
+	assert.Nil(os.WriteFile(footerPath, []byte(`{{ define "footer" }}disk footer{{ end }}`), 0644))
+
+	views := fstest.MapFS{
+		"_views/index.html": &fstest.MapFile{Data: []byte(`{{ define "index" }}index {{ template "footer" . }}{{ end }}`)},
+	}
+	vc := MustNewViewCache(
+		OptViewCachePaths(footerPath),
+		OptViewCacheFS(fstest.MapFS{"other.html": &fstest.MapFile{Data: []byte(`{{ define "other" }}other{{ end }}`)}}, "other.html"),
+		OptViewCacheFS(views, "_views/*.html"),
+	)
+	assert.Equal([]string{footerPath}, vc.Paths)
+	assert.Equal([]string{"_views/*.html"}, vc.FSPaths)
+	assert.Nil(vc.Initialize())
+
+	buffer := new(bytes.Buffer)
+	assert.Nil(vc.View("index", nil).Render(MockCtxWithBuffer(http.MethodGet, "/", buffer)))
+	assert.Equal("index disk footer", buffer.String())
+	assert.Nil(vc.Templates.Lookup("other"))
+}
+
+func TestViewCacheFSLiveReload(t *testing.T) {
+	assert := assert.New(t)
+
+	views := fstest.MapFS{
+		"index.html": &fstest.MapFile{Data: []byte(`{{ define "index" }}before{{ end }}`)},
+	}
+	vc := MustNewViewCache(OptViewCacheConfig(&ViewCacheConfig{
+		FS:         views,
+		Paths:      []string{"index.html"},
+		LiveReload: true,
+	}))
+	assert.Nil(vc.Initialize())
+	assert.Nil(vc.Templates)
+
+	buffer := new(bytes.Buffer)
+	assert.Nil(vc.View("index", nil).Render(MockCtxWithBuffer(http.MethodGet, "/", buffer)))
+	assert.Equal("before", buffer.String())
+
+	views["index.html"] = &fstest.MapFile{Data: []byte(`{{ define "index" }}after{{ end }}`)}
+
+	buffer.Reset()
+	assert.Nil(vc.View("index", nil).Render(MockCtxWithBuffer(http.MethodGet, "/", buffer)))
+	assert.Equal("after", buffer.String())
+}
@@ -58,6 +58,13 @@ var (
 	HeaderDate                    = http.CanonicalHeaderKey("Date")
 	HeaderETag                    = http.CanonicalHeaderKey("etag")
 	HeaderForwarded               = http.CanonicalHeaderKey("Forwarded")
+	HeaderIfModifiedSince         = http.CanonicalHeaderKey("If-Modified-Since")
+	HeaderIfNoneMatch             = http.CanonicalHeaderKey("If-None-Match")
+	HeaderLastModified            = http.CanonicalHeaderKey("Last-Modified")
+	HeaderLink                    = http.CanonicalHeaderKey("Link")
+	HeaderLastEventID             = http.CanonicalHeaderKey("Last-Event-ID")
+	HeaderRetryAfter              = http.CanonicalHeaderKey("Retry-After")
+	HeaderIdempotencyKey          = http.CanonicalHeaderKey("Idempotency-Key")
 	HeaderServer                  = http.CanonicalHeaderKey("Server")
 	HeaderSetCookie               = http.CanonicalHeaderKey("Set-Cookie")
 	HeaderStrictTransportSecurity = http.CanonicalHeaderKey("Strict-Transport-Security")
@@ -72,6 +79,8 @@ var (
 	HeaderXFrameOptions           = http.CanonicalHeaderKey("X-Frame-Options")
 	HeaderXRealIP                 = http.CanonicalHeaderKey("X-Real-IP")
 	HeaderXServedBy               = http.CanonicalHeaderKey("X-Served-By")
+	HeaderXSignature              = http.CanonicalHeaderKey("X-Signature")
+	HeaderXSignatureTimestamp     = http.CanonicalHeaderKey("X-Signature-Timestamp")
 	HeaderXXSSProtection          = http.CanonicalHeaderKey("X-Xss-Protection")
 )
 
@@ -124,6 +133,9 @@ const (
 	// We specify chartset=utf-8 so that clients know to use the UTF-8 string encoding.
 	ContentTypeApplicationJSON = "application/json; charset=utf-8"
 
+	// ContentTypeApplicationProblemJSON is a content type for RFC 7807 problem details responses.
+	ContentTypeApplicationProblemJSON = "application/problem+json"
+
 	// ContentTypeApplicationXML is a content type header value.
 	ContentTypeApplicationXML = "application/xml"
 
@@ -15,6 +15,11 @@ const (
 	ErrParameterMissing       ex.Class = "parameter missing"
 	ErrUnauthorized           ex.Class = "unauthorized"
 	ErrInvalidSplitColonInput ex.Class = `split colon input string is not of the form "<first>:<second>"`
+	ErrEventBrokerSlowClient  ex.Class = "event broker; client dropped for falling behind"
+	ErrRequestBodyTooLarge    ex.Class = "request body too large"
+	ErrPostedFileTooLarge     ex.Class = "posted file too large"
+	ErrInvalidSignature       ex.Class = "invalid request signature"
+	ErrSignatureExpired       ex.Class = "request signature timestamp outside allowed skew"
 )
 
 // ErrIsInvalidSameSite returns if an error is `ErrInvalidSameSite`
@@ -36,3 +41,28 @@ func ErrIsUnauthorized(err error) bool {
 func ErrIsInvalidSplitColonInput(err error) bool {
 	return ex.Is(err, ErrInvalidSplitColonInput)
 }
+
+// ErrIsEventBrokerSlowClient returns if an error is `ErrEventBrokerSlowClient`
+func ErrIsEventBrokerSlowClient(err error) bool {
+	return ex.Is(err, ErrEventBrokerSlowClient)
+}
+
+// ErrIsRequestBodyTooLarge returns if an error is `ErrRequestBodyTooLarge`
+func ErrIsRequestBodyTooLarge(err error) bool {
+	return ex.Is(err, ErrRequestBodyTooLarge)
+}
+
+// ErrIsPostedFileTooLarge returns if an error is `ErrPostedFileTooLarge`
+func ErrIsPostedFileTooLarge(err error) bool {
+	return ex.Is(err, ErrPostedFileTooLarge)
+}
+
+// ErrIsInvalidSignature returns if an error is `ErrInvalidSignature`
+func ErrIsInvalidSignature(err error) bool {
+	return ex.Is(err, ErrInvalidSignature)
+}
+
+// ErrIsSignatureExpired returns if an error is `ErrSignatureExpired`
+func ErrIsSignatureExpired(err error) bool {
+	return ex.Is(err, ErrSignatureExpired)
+}
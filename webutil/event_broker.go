@@ -0,0 +1,310 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package webutil
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/blend/go-sdk/collections"
+	"github.com/blend/go-sdk/ex"
+)
+
+// Event broker defaults.
+const (
+	DefaultEventBrokerReplaySize        = 64
+	DefaultEventBrokerReplayTTL         = 5 * time.Minute
+	DefaultEventBrokerClientBufferSize  = 32
+	DefaultEventBrokerHeartbeatInterval = 15 * time.Second
+)
+
+// NewEventBroker returns a new event broker.
+func NewEventBroker(opts ...EventBrokerOption) *EventBroker {
+	eb := &EventBroker{
+		ReplaySize:        DefaultEventBrokerReplaySize,
+		ReplayTTL:         DefaultEventBrokerReplayTTL,
+		ClientBufferSize:  DefaultEventBrokerClientBufferSize,
+		HeartbeatInterval: DefaultEventBrokerHeartbeatInterval,
+		topics:            make(map[string]*eventBrokerTopic),
+	}
+	for _, opt := range opts {
+		opt(eb)
+	}
+	return eb
+}
+
+// EventBrokerOption mutates an event broker.
+type EventBrokerOption func(*EventBroker)
+
+// OptEventBrokerReplaySize sets the number of events retained per topic for `Last-Event-ID` replay.
+func OptEventBrokerReplaySize(size int) EventBrokerOption {
+	return func(eb *EventBroker) { eb.ReplaySize = size }
+}
+
+// OptEventBrokerReplayTTL sets how long a topic without subscribers retains its events for replay
+// after it was last published to or subscribed to; the topic is then evicted.
+func OptEventBrokerReplayTTL(d time.Duration) EventBrokerOption {
+	return func(eb *EventBroker) { eb.ReplayTTL = d }
+}
+
+// OptEventBrokerClientBufferSize sets the number of events that can be queued for a client before it is dropped.
+func OptEventBrokerClientBufferSize(size int) EventBrokerOption {
+	return func(eb *EventBroker) { eb.ClientBufferSize = size }
+}
+
+// OptEventBrokerHeartbeatInterval sets the interval between heartbeat pings.
+func OptEventBrokerHeartbeatInterval(d time.Duration) EventBrokerOption {
+	return func(eb *EventBroker) { eb.HeartbeatInterval = d }
+}
+
+// BrokerEvent is an event published to a topic.
+type BrokerEvent struct {
+	ID    uint64
+	Topic string
+	Name  string
+	Data  string
+}
+
+// EventBroker fans events out to `EventSource` subscribers by topic.
+//
+// Each topic retains its most recent events so clients that reconnect with a
+// `Last-Event-ID` header receive the events they missed. Topics without subscribers
+// are evicted once they have been idle for the replay ttl, so per user topics do not accumulate.
+// Clients that fall behind by more than the client buffer size are dropped rather than blocking publishers.
+type EventBroker struct {
+	ReplaySize        int
+	ReplayTTL         time.Duration
+	ClientBufferSize  int
+	HeartbeatInterval time.Duration
+
+	mu        sync.Mutex
+	lastID    uint64
+	lastSweep time.Time
+	topics    map[string]*eventBrokerTopic
+}
+
+type eventBrokerTopic struct {
+	events      *collections.RingBuffer
+	subscribers map[*eventBrokerSubscriber]struct{}
+	// lastActive is when the topic was last published to, or last had a subscriber.
+	lastActive time.Time
+}
+
+type eventBrokerSubscriber struct {
+	events  chan BrokerEvent
+	dropped chan struct{}
+}
+
+// Publish publishes an event to a topic, returning the event with its assigned id.
+//
+// Event ids are assigned in publish order across all topics.
+func (eb *EventBroker) Publish(topic, name, data string) BrokerEvent {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	eb.lastID++
+	event := BrokerEvent{ID: eb.lastID, Topic: topic, Name: name, Data: data}
+
+	now := time.Now()
+	defer eb.sweepUnsafe(now)
+	t, ok := eb.topics[topic]
+	if !ok {
+		// without subscribers, the topic is only needed to retain the event for replay.
+		if eb.ReplaySize <= 0 || eb.ReplayTTL <= 0 {
+			return event
+		}
+		t = eb.topicUnsafe(topic)
+	}
+	t.lastActive = now
+	if eb.ReplaySize > 0 {
+		t.events.Enqueue(event)
+		for t.events.Len() > eb.ReplaySize {
+			t.events.Dequeue()
+		}
+	}
+	for subscriber := range t.subscribers {
+		select {
+		case subscriber.events <- event:
+		default:
+			eb.dropUnsafe(subscriber)
+		}
+	}
+	return event
+}
+
+// SubscriberCount returns the number of subscribers for a topic.
+func (eb *EventBroker) SubscriberCount(topic string) int {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	if t, ok := eb.topics[topic]; ok {
+		return len(t.subscribers)
+	}
+	return 0
+}
+
+// Serve starts an event source session on the response and streams events for the given topics to it.
+//
+// Events published after `lastEventID` that are still retained are replayed first.
+// It blocks until the context is cancelled (e.g. the client disconnects), a write fails,
+// or the client is dropped for falling behind, in which case it returns `ErrEventBrokerSlowClient`.
+func (eb *EventBroker) Serve(ctx context.Context, rw http.ResponseWriter, lastEventID string, topics ...string) error {
+	es := NewEventSource(rw)
+	if err := es.StartSession(); err != nil {
+		return err
+	}
+
+	subscriber, replay := eb.subscribe(lastEventID, topics)
+	defer eb.unsubscribe(subscriber, topics)
+
+	for _, event := range replay {
+		if err := eb.send(es, event); err != nil {
+			return err
+		}
+	}
+
+	var heartbeat <-chan time.Time
+	if eb.HeartbeatInterval > 0 {
+		ticker := time.NewTicker(eb.HeartbeatInterval)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-subscriber.dropped:
+			return ex.New(ErrEventBrokerSlowClient)
+		case <-heartbeat:
+			if err := es.Ping(); err != nil {
+				return err
+			}
+		case event := <-subscriber.events:
+			if err := eb.send(es, event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+//
+// internal helpers
+//
+
+func (eb *EventBroker) send(es *EventSource, event BrokerEvent) error {
+	name := event.Name
+	if name == "" {
+		name = "message"
+	}
+	return es.EventDataWithID(name, event.Data, strconv.FormatUint(event.ID, 10))
+}
+
+// subscribe registers a subscriber and collects the events to replay while holding
+// the lock, so no event is both replayed and delivered, or missed entirely.
+func (eb *EventBroker) subscribe(lastEventID string, topics []string) (*eventBrokerSubscriber, []BrokerEvent) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	subscriber := &eventBrokerSubscriber{
+		events:  make(chan BrokerEvent, eb.ClientBufferSize),
+		dropped: make(chan struct{}),
+	}
+
+	after, parseErr := strconv.ParseUint(lastEventID, 10, 64)
+	var replay []BrokerEvent
+	now := time.Now()
+	seen := make(map[string]bool, len(topics))
+	for _, topic := range topics {
+		// a topic given more than once must not replay its events more than once
+		if seen[topic] {
+			continue
+		}
+		seen[topic] = true
+		t := eb.topicUnsafe(topic)
+		t.subscribers[subscriber] = struct{}{}
+		t.lastActive = now
+		if lastEventID == "" || parseErr != nil {
+			continue
+		}
+		t.events.Each(func(value interface{}) {
+			if event := value.(BrokerEvent); event.ID > after {
+				replay = append(replay, event)
+			}
+		})
+	}
+	sort.Slice(replay, func(i, j int) bool { return replay[i].ID < replay[j].ID })
+	return subscriber, replay
+}
+
+func (eb *EventBroker) unsubscribe(subscriber *eventBrokerSubscriber, topics []string) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	now := time.Now()
+	for _, topic := range topics {
+		if t, ok := eb.topics[topic]; ok {
+			eb.removeSubscriberUnsafe(topic, t, subscriber, now)
+		}
+	}
+	eb.sweepUnsafe(now)
+}
+
+// dropUnsafe removes a subscriber from every topic; it is called from `Publish`, which sweeps afterwards.
+func (eb *EventBroker) dropUnsafe(subscriber *eventBrokerSubscriber) {
+	now := time.Now()
+	for topic, t := range eb.topics {
+		eb.removeSubscriberUnsafe(topic, t, subscriber, now)
+	}
+	close(subscriber.dropped)
+}
+
+// removeSubscriberUnsafe removes a subscriber from a topic, evicting the topic
+// if it has no subscribers left and no events to retain.
+func (eb *EventBroker) removeSubscriberUnsafe(topic string, t *eventBrokerTopic, subscriber *eventBrokerSubscriber, now time.Time) {
+	if _, ok := t.subscribers[subscriber]; !ok {
+		return
+	}
+	delete(t.subscribers, subscriber)
+	if len(t.subscribers) > 0 {
+		return
+	}
+	t.lastActive = now
+	if t.events.Len() == 0 || eb.ReplayTTL <= 0 {
+		delete(eb.topics, topic)
+	}
+}
+
+// sweepUnsafe evicts topics without subscribers that have been idle for the replay ttl.
+//
+// It runs at most once per replay ttl, so publishing stays constant time.
+func (eb *EventBroker) sweepUnsafe(now time.Time) {
+	if now.Sub(eb.lastSweep) < eb.ReplayTTL {
+		return
+	}
+	eb.lastSweep = now
+	for topic, t := range eb.topics {
+		if len(t.subscribers) == 0 && now.Sub(t.lastActive) >= eb.ReplayTTL {
+			delete(eb.topics, topic)
+		}
+	}
+}
+
+func (eb *EventBroker) topicUnsafe(topic string) *eventBrokerTopic {
+	t, ok := eb.topics[topic]
+	if !ok {
+		t = &eventBrokerTopic{
+			events:      collections.NewRingBuffer(),
+			subscribers: make(map[*eventBrokerSubscriber]struct{}),
+		}
+		eb.topics[topic] = t
+	}
+	return t
+}
@@ -0,0 +1,148 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package webutil
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func TestEventBrokerReplay(t *testing.T) {
+	its := assert.New(t)
+
+	broker := NewEventBroker(OptEventBrokerReplaySize(2), OptEventBrokerHeartbeatInterval(0))
+	broker.Publish("foo", "update", "one")
+	broker.Publish("bar", "update", "two")
+	broker.Publish("foo", "update", "three")
+	broker.Publish("foo", "", "four")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	buffer := new(bytes.Buffer)
+	err := broker.Serve(ctx, NewMockResponse(buffer), "1", "foo", "bar")
+	its.Nil(err)
+	its.Equal(
+		"event: ping\n\n"+
+			"event: update\ndata: two\nid: 2\n\n"+
+			"event: update\ndata: three\nid: 3\n\n"+
+			"event: message\ndata: four\nid: 4\n\n",
+		buffer.String(),
+	)
+	its.Zero(broker.SubscriberCount("foo"))
+}
+
+func TestEventBrokerReplayDuplicateTopics(t *testing.T) {
+	its := assert.New(t)
+
+	broker := NewEventBroker(OptEventBrokerHeartbeatInterval(0))
+	broker.Publish("foo", "update", "one")
+	broker.Publish("foo", "update", "two")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	buffer := new(bytes.Buffer)
+	its.Nil(broker.Serve(ctx, NewMockResponse(buffer), "0", "foo", "foo"))
+	its.Equal(
+		"event: ping\n\n"+
+			"event: update\ndata: one\nid: 1\n\n"+
+			"event: update\ndata: two\nid: 2\n\n",
+		buffer.String(),
+	)
+	its.Zero(broker.SubscriberCount("foo"))
+}
+
+func TestEventBrokerServe(t *testing.T) {
+	its := assert.New(t)
+
+	broker := NewEventBroker()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_ = broker.Serve(req.Context(), rw, req.Header.Get(HeaderLastEventID), "foo")
+	}))
+	defer server.Close()
+
+	res, err := http.Get(server.URL)
+	its.Nil(err)
+	defer res.Body.Close()
+	its.Equal("text/event-stream", res.Header.Get(HeaderContentType))
+
+	for broker.SubscriberCount("foo") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	broker.Publish("bar", "update", "ignored")
+	broker.Publish("foo", "update", "hello")
+
+	reader := bufio.NewReader(res.Body)
+	var lines []string
+	for len(lines) < 5 {
+		line, err := reader.ReadString('\n')
+		its.Nil(err)
+		lines = append(lines, strings.TrimSpace(line))
+	}
+	its.Equal([]string{"event: ping", "", "event: update", "data: hello", "id: 2"}, lines)
+}
+
+func TestEventBrokerDropsSlowClients(t *testing.T) {
+	its := assert.New(t)
+
+	broker := NewEventBroker(OptEventBrokerClientBufferSize(1))
+	subscriber, replay := broker.subscribe("", []string{"foo", "bar"})
+	its.Empty(replay)
+	its.Equal(1, broker.SubscriberCount("foo"))
+
+	broker.Publish("foo", "", "one")
+	select {
+	case <-subscriber.dropped:
+		its.FailNow("subscriber should not be dropped yet")
+	default:
+	}
+
+	broker.Publish("bar", "", "two")
+	<-subscriber.dropped
+	its.Zero(broker.SubscriberCount("foo"))
+	its.Zero(broker.SubscriberCount("bar"))
+
+	// further publishes should not panic on the dropped subscriber.
+	broker.Publish("foo", "", "three")
+}
+
+func TestEventBrokerEvictsTopics(t *testing.T) {
+	its := assert.New(t)
+
+	broker := NewEventBroker(OptEventBrokerReplayTTL(50 * time.Millisecond))
+	broker.Publish("user-1", "", "one")
+	its.Len(broker.topics, 1, "topics retain events for replay without subscribers")
+
+	subscriber, _ := broker.subscribe("", []string{"user-2", "user-3"})
+	broker.unsubscribe(subscriber, []string{"user-2"})
+	its.Len(broker.topics, 2, "topics without events are evicted once their last subscriber leaves")
+
+	time.Sleep(60 * time.Millisecond)
+	broker.Publish("user-4", "", "four")
+	its.Len(broker.topics, 2)
+	_, ok := broker.topics["user-1"]
+	its.False(ok, "idle topics without subscribers should be evicted")
+	_, ok = broker.topics["user-3"]
+	its.True(ok, "topics with subscribers should not be evicted")
+
+	broker.unsubscribe(subscriber, []string{"user-3"})
+	its.Len(broker.topics, 1)
+
+	noReplay := NewEventBroker(OptEventBrokerReplayTTL(0))
+	noReplay.Publish("user-1", "", "one")
+	its.Empty(noReplay.topics, "topics are not retained without a replay ttl")
+}
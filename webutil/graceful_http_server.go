@@ -15,6 +15,7 @@ import (
 
 	"github.com/blend/go-sdk/async"
 	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/graceful"
 	"github.com/blend/go-sdk/logger"
 )
 
@@ -43,6 +44,14 @@ func OptGracefulHTTPServerListener(listener net.Listener) GracefulHTTPServerOpti
 	return func(g *GracefulHTTPServer) { g.Listener = listener }
 }
 
+// OptGracefulHTTPServerHandoff sets the handoff the server listener is created with, so the
+// listener is passed to a new process on a graceful restart (see `graceful.ShutdownWithHandoff`).
+//
+// It has no effect if a listener is set with `OptGracefulHTTPServerListener`.
+func OptGracefulHTTPServerHandoff(handoff *graceful.Handoff) GracefulHTTPServerOption {
+	return func(g *GracefulHTTPServer) { g.Handoff = handoff }
+}
+
 // OptGracefulHTTPServerLog sets the logger.
 func OptGracefulHTTPServerLog(log logger.Log) GracefulHTTPServerOption {
 	return func(g *GracefulHTTPServer) { g.Log = log }
@@ -55,6 +64,7 @@ type GracefulHTTPServer struct {
 	Server              *http.Server
 	ShutdownGracePeriod time.Duration
 	Listener            net.Listener
+	Handoff             *graceful.Handoff
 }
 
 // Start implements graceful.Graceful.Start.
@@ -72,6 +82,21 @@ func (gs *GracefulHTTPServer) Start() (err error) {
 	if gs.Listener != nil {
 		logger.MaybeInfof(gs.Log, "http server listening on %s", gs.Listener.Addr().String())
 		shutdownErr = gs.Server.Serve(gs.Listener)
+	} else if gs.Handoff != nil {
+		addr := gs.Server.Addr
+		if addr == "" {
+			addr = ":http"
+		}
+		// listen through the handoff so the socket can be passed to a new process on restart;
+		// the listener is not kept, as the server closes it on stop.
+		var listener net.Listener
+		listener, err = gs.Handoff.Listen("tcp", addr)
+		if err != nil {
+			return
+		}
+		defer gs.Handoff.Release(listener)
+		logger.MaybeInfof(gs.Log, "http server listening on %s", listener.Addr().String())
+		shutdownErr = gs.Server.Serve(listener)
 	} else {
 		logger.MaybeInfof(gs.Log, "http server listening on %s", gs.Server.Addr)
 		shutdownErr = gs.Server.ListenAndServe()
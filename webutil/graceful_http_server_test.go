@@ -9,10 +9,12 @@ package webutil
 
 import (
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/blend/go-sdk/assert"
 	"github.com/blend/go-sdk/graceful"
@@ -54,3 +56,60 @@ func TestGracefulServer(t *testing.T) {
 	stopSignal <- os.Interrupt
 	<-didShutdown
 }
+
+func TestGracefulServerHandoffRestart(t *testing.T) {
+	assert := assert.New(t)
+
+	// reserve a free port so the server can be started on the same address twice
+	reserved, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(err)
+	addr := reserved.Addr().String()
+	assert.Nil(reserved.Close())
+
+	newServer := func(body string) *http.Server {
+		return &http.Server{
+			Addr: addr,
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Connection", "close")
+				fmt.Fprint(w, body)
+			}),
+		}
+	}
+	gs := NewGracefulHTTPServer(newServer("first"), OptGracefulHTTPServerHandoff(graceful.NewHandoff()))
+
+	for _, body := range []string{"first", "second"} {
+		// a stopped latch must be reset, and an http server cannot be reused once shut down
+		gs.Latch.Reset()
+		gs.Server = newServer(body)
+		errors := make(chan error, 1)
+		go func() { errors <- gs.Start() }()
+		<-gs.NotifyStarted()
+
+		assert.Equal(body, gracefulServerTestGet(t, addr))
+		assert.Nil(gs.Stop())
+		assert.Nil(<-errors)
+		assert.Nil(gs.Listener, "the handoff listener should not be kept once the server stops")
+	}
+}
+
+// gracefulServerTestGet gets a path from a server, retrying until it is listening.
+func gracefulServerTestGet(t *testing.T, addr string) string {
+	t.Helper()
+	client := &http.Client{Timeout: time.Second}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		res, err := client.Get("http://" + addr)
+		if err == nil {
+			defer res.Body.Close()
+			contents, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return string(contents)
+		}
+		if time.Now().After(deadline) {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package webutil
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+// HMACCanonicalizer returns the canonical form of a request that is signed.
+//
+// The body is passed separately as it has already been read from the request.
+type HMACCanonicalizer func(req *http.Request, body []byte) []byte
+
+// DefaultHMACCanonicalizer is the default canonical form of a request.
+//
+// It is the method, the request uri (path and query), the signature timestamp
+// header and the hex encoded sha256 of the body, each separated by a newline.
+func DefaultHMACCanonicalizer(req *http.Request, body []byte) []byte {
+	bodyHash := sha256.Sum256(body)
+	return []byte(strings.Join([]string{
+		req.Method,
+		req.URL.RequestURI(),
+		req.Header.Get(HeaderXSignatureTimestamp),
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n"))
+}
+
+// HMACSignature returns the hex encoded hmac-sha256 of the canonical form of a request.
+func HMACSignature(key, canonical []byte) string {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(canonical)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignRequestHMAC signs a request, setting the signature timestamp header to a given time
+// and the signature to a given header.
+//
+// The timestamp is set each time a request is signed, so a request that is sent again
+// (e.g. when it is retried) is signed with the time it is sent.
+//
+// If the header name is unset, `X-Signature` is used; if the canonicalizer
+// is unset, `DefaultHMACCanonicalizer` is used.
+func SignRequestHMAC(req *http.Request, body, key []byte, headerName string, canonicalizer HMACCanonicalizer, now time.Time) {
+	if headerName == "" {
+		headerName = HeaderXSignature
+	}
+	if canonicalizer == nil {
+		canonicalizer = DefaultHMACCanonicalizer
+	}
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	req.Header.Set(HeaderXSignatureTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(headerName, HMACSignature(key, canonicalizer(req, body)))
+}
+
+// VerifyRequestHMAC verifies the signature of a request.
+//
+// It returns `ErrInvalidSignature` if the signature is missing or doesn't match, and
+// `ErrSignatureExpired` if max skew is set and the signature timestamp is further than
+// the max skew from now in either direction.
+func VerifyRequestHMAC(req *http.Request, body, key []byte, headerName string, canonicalizer HMACCanonicalizer, maxSkew time.Duration, now time.Time) error {
+	if headerName == "" {
+		headerName = HeaderXSignature
+	}
+	if canonicalizer == nil {
+		canonicalizer = DefaultHMACCanonicalizer
+	}
+	signature := req.Header.Get(headerName)
+	if signature == "" {
+		return ex.New(ErrInvalidSignature, ex.OptMessagef("header missing: %s", headerName))
+	}
+	if maxSkew > 0 {
+		timestamp, err := strconv.ParseInt(req.Header.Get(HeaderXSignatureTimestamp), 10, 64)
+		if err != nil {
+			return ex.New(ErrInvalidSignature, ex.OptMessagef("header invalid: %s", HeaderXSignatureTimestamp))
+		}
+		skew := now.Sub(time.Unix(timestamp, 0))
+		if skew < 0 {
+			skew = -skew
+		}
+		if skew > maxSkew {
+			return ex.New(ErrSignatureExpired, ex.OptMessagef("skew: %v, max skew: %v", skew, maxSkew))
+		}
+	}
+	expected := HMACSignature(key, canonicalizer(req, body))
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return ex.New(ErrInvalidSignature)
+	}
+	return nil
+}
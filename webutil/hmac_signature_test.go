@@ -0,0 +1,71 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package webutil
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func TestSignVerifyRequestHMAC(t *testing.T) {
+	its := assert.New(t)
+
+	key := []byte("secret")
+	now := time.Date(2022, 01, 01, 12, 00, 00, 00, time.UTC)
+	body := []byte(`{"foo":"bar"}`)
+
+	req, _ := http.NewRequest(http.MethodPost, "http://localhost/hooks?type=foo", nil)
+	SignRequestHMAC(req, body, key, "", nil, now)
+	its.NotEmpty(req.Header.Get(HeaderXSignature))
+	its.Equal("1641038400", req.Header.Get(HeaderXSignatureTimestamp))
+
+	its.Nil(VerifyRequestHMAC(req, body, key, "", nil, time.Minute, now.Add(30*time.Second)))
+	its.Nil(VerifyRequestHMAC(req, body, key, "", nil, time.Minute, now.Add(-30*time.Second)))
+
+	err := VerifyRequestHMAC(req, body, key, "", nil, time.Minute, now.Add(2*time.Minute))
+	its.True(ErrIsSignatureExpired(err))
+
+	err = VerifyRequestHMAC(req, []byte(`{"foo":"baz"}`), key, "", nil, time.Minute, now)
+	its.True(ErrIsInvalidSignature(err))
+
+	err = VerifyRequestHMAC(req, body, []byte("not-secret"), "", nil, time.Minute, now)
+	its.True(ErrIsInvalidSignature(err))
+
+	err = VerifyRequestHMAC(req, body, key, "X-Other-Signature", nil, time.Minute, now)
+	its.True(ErrIsInvalidSignature(err))
+}
+
+func TestSignRequestHMACResign(t *testing.T) {
+	its := assert.New(t)
+
+	key := []byte("secret")
+	now := time.Date(2022, 01, 01, 12, 00, 00, 00, time.UTC)
+
+	req, _ := http.NewRequest(http.MethodPost, "http://localhost/hooks", nil)
+	SignRequestHMAC(req, nil, key, "", nil, now)
+
+	// e.g. a retry after a backoff is signed with the time it is sent
+	later := now.Add(5 * time.Minute)
+	SignRequestHMAC(req, nil, key, "", nil, later)
+	its.Equal("1641038700", req.Header.Get(HeaderXSignatureTimestamp))
+	its.Nil(VerifyRequestHMAC(req, nil, key, "", nil, time.Minute, later))
+}
+
+func TestSignRequestHMACCanonicalizer(t *testing.T) {
+	its := assert.New(t)
+
+	canonicalizer := func(req *http.Request, _ []byte) []byte {
+		return []byte(req.URL.Path)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost/foo", nil)
+	SignRequestHMAC(req, nil, []byte("secret"), "X-Hub-Signature", canonicalizer, time.Now())
+	its.Equal(HMACSignature([]byte("secret"), []byte("/foo")), req.Header.Get("X-Hub-Signature"))
+}
@@ -99,6 +99,11 @@ func OptHTTPRequestHeader(header http.Header) HTTPRequestEventOption {
 	return func(hre *HTTPRequestEvent) { hre.Header = header }
 }
 
+// OptHTTPRequestBodyLimitExceeded sets a field.
+func OptHTTPRequestBodyLimitExceeded(bodyLimitExceeded bool) HTTPRequestEventOption {
+	return func(hre *HTTPRequestEvent) { hre.BodyLimitExceeded = bodyLimitExceeded }
+}
+
 // HTTPRequestEvent is an event type for http requests.
 type HTTPRequestEvent struct {
 	Request         *http.Request
@@ -109,6 +114,8 @@ type HTTPRequestEvent struct {
 	StatusCode      int
 	Elapsed         time.Duration
 	Header          http.Header
+	// BodyLimitExceeded is set if the request body or a posted file was larger than its size limit.
+	BodyLimitExceeded bool
 }
 
 // GetFlag implements event.
@@ -133,11 +140,15 @@ func (e HTTPRequestEvent) WriteText(tf logger.TextFormatter, wr io.Writer) {
 	}
 	fmt.Fprint(wr, logger.Space)
 	fmt.Fprint(wr, stringutil.FileSize(e.ContentLength))
+	if e.BodyLimitExceeded {
+		fmt.Fprint(wr, logger.Space)
+		fmt.Fprint(wr, tf.Colorize("body limit exceeded", ansi.ColorRed))
+	}
 }
 
 // Decompose implements JSONWritable.
 func (e HTTPRequestEvent) Decompose() map[string]interface{} {
-	return map[string]interface{}{
+	output := map[string]interface{}{
 		"ip":              GetRemoteAddr(e.Request),
 		"userAgent":       GetUserAgent(e.Request),
 		"verb":            e.Request.Method,
@@ -151,4 +162,8 @@ func (e HTTPRequestEvent) Decompose() map[string]interface{} {
 		"statusCode":      e.StatusCode,
 		"elapsed":         timeutil.Milliseconds(e.Elapsed),
 	}
+	if e.BodyLimitExceeded {
+		output["bodyLimitExceeded"] = true
+	}
+	return output
 }
@@ -14,6 +14,11 @@ import (
 	"net"
 	"net/http"
 	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	"github.com/blend/go-sdk/ex"
 )
 
 // HTTPServerOption is a mutator for an http server.
@@ -98,3 +103,34 @@ func OptHTTPServerErrorLog(log *log.Logger) HTTPServerOption {
 		return nil
 	}
 }
+
+// OptHTTPServerHTTP2 configures the server's http2 (over tls) support with a given http2 server,
+// which controls tuning parameters like `MaxConcurrentStreams` and `MaxReadFrameSize`.
+//
+// Unlike `http2.ConfigureServer` it does not set a tls config on servers that don't have one.
+func OptHTTPServerHTTP2(h2s *http2.Server) HTTPServerOption {
+	return func(s *http.Server) error {
+		tlsConfig := s.TLSConfig
+		if err := http2.ConfigureServer(s, h2s); err != nil {
+			return ex.New(err)
+		}
+		if tlsConfig == nil {
+			s.TLSConfig = nil
+		}
+		return nil
+	}
+}
+
+// OptHTTPServerH2C wraps the server handler so it also serves http2 without tls (h2c),
+// alongside http/1.1 on the same port, using a given http2 server.
+//
+// It must be applied _after_ the handler is set.
+func OptHTTPServerH2C(h2s *http2.Server) HTTPServerOption {
+	return func(s *http.Server) error {
+		if s.Handler == nil {
+			return ex.New(ErrParameterMissing, ex.OptMessage("h2c requires a server handler"))
+		}
+		s.Handler = h2c.NewHandler(s.Handler, h2s)
+		return nil
+	}
+}
@@ -0,0 +1,112 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package webutil
+
+import (
+	"strings"
+)
+
+// Link is a web link parsed from a `Link` header as described in RFC 5988.
+type Link struct {
+	URL    string
+	Rel    string
+	Params map[string]string
+}
+
+// ParseLinkHeader parses the values of a `Link` header, e.g.
+//
+//	<https://api.example.com/foos?page=2>; rel="next", <https://api.example.com/foos?page=5>; rel="last"
+//
+// Malformed links are skipped.
+func ParseLinkHeader(values ...string) (output []Link) {
+	for _, value := range values {
+		for _, part := range splitLinkHeader(value) {
+			part = strings.TrimSpace(part)
+			if !strings.HasPrefix(part, "<") {
+				continue
+			}
+			end := strings.Index(part, ">")
+			if end < 0 {
+				continue
+			}
+			link := Link{
+				URL:    part[1:end],
+				Params: make(map[string]string),
+			}
+			for _, param := range splitOutsideQuotes(part[end+1:], ';') {
+				param = strings.TrimSpace(param)
+				if param == "" {
+					continue
+				}
+				key, value := param, ""
+				if index := strings.Index(param, "="); index >= 0 {
+					key, value = strings.TrimSpace(param[:index]), strings.TrimSpace(param[index+1:])
+				}
+				key = strings.ToLower(key)
+				value = strings.Trim(value, `"`)
+				link.Params[key] = value
+				if key == "rel" {
+					link.Rel = value
+				}
+			}
+			output = append(output, link)
+		}
+	}
+	return
+}
+
+// FindLink returns the first link with a given relation type.
+//
+// Relation types are matched case insensitively, and a link with
+// multiple space separated relation types matches each of them.
+func FindLink(links []Link, rel string) (Link, bool) {
+	for _, link := range links {
+		for _, linkRel := range strings.Fields(link.Rel) {
+			if strings.EqualFold(linkRel, rel) {
+				return link, true
+			}
+		}
+	}
+	return Link{}, false
+}
+
+// splitLinkHeader splits a header value on the commas between links,
+// ignoring commas within urls or quoted parameter values.
+func splitLinkHeader(value string) (output []string) {
+	var inURL, inQuotes bool
+	var start int
+	for index, r := range value {
+		switch {
+		case r == '"' && !inURL:
+			inQuotes = !inQuotes
+		case r == '<' && !inQuotes:
+			inURL = true
+		case r == '>' && !inQuotes:
+			inURL = false
+		case r == ',' && !inURL && !inQuotes:
+			output = append(output, value[start:index])
+			start = index + 1
+		}
+	}
+	return append(output, value[start:])
+}
+
+func splitOutsideQuotes(value string, separator rune) (output []string) {
+	var inQuotes bool
+	var start int
+	for index, r := range value {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case r == separator && !inQuotes:
+			output = append(output, value[start:index])
+			start = index + 1
+		}
+	}
+	return append(output, value[start:])
+}
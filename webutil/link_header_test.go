@@ -0,0 +1,40 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package webutil
+
+import (
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func TestParseLinkHeader(t *testing.T) {
+	its := assert.New(t)
+
+	links := ParseLinkHeader(
+		`<https://api.example.com/foos?page=2&tags=a,b>; rel="next"; title="next, page", <https://api.example.com/foos?page=5>; rel=last`,
+		`<https://api.example.com/foos?page=1>; rel="first prev"`,
+		`malformed; rel="next"`,
+	)
+	its.Len(links, 3)
+	its.Equal("https://api.example.com/foos?page=2&tags=a,b", links[0].URL)
+	its.Equal("next", links[0].Rel)
+	its.Equal("next, page", links[0].Params["title"])
+	its.Equal("last", links[1].Rel)
+
+	next, ok := FindLink(links, "NEXT")
+	its.True(ok)
+	its.Equal("https://api.example.com/foos?page=2&tags=a,b", next.URL)
+
+	prev, ok := FindLink(links, "prev")
+	its.True(ok)
+	its.Equal("https://api.example.com/foos?page=1", prev.URL)
+
+	_, ok = FindLink(links, "self")
+	its.False(ok)
+}
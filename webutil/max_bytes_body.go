@@ -0,0 +1,84 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package webutil
+
+import (
+	"io"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+var (
+	_ io.ReadCloser = (*MaxBytesBody)(nil)
+)
+
+// NewMaxBytesBody returns a new request body that errors with `ErrRequestBodyTooLarge`
+// once more than a given number of bytes are read from it.
+//
+// The content length is the declared length of the body (-1 if unknown); if it is over
+// the limit the first read fails without reading anything from the body.
+func NewMaxBytesBody(body io.ReadCloser, contentLength, limit int64) *MaxBytesBody {
+	return &MaxBytesBody{Body: body, ContentLength: contentLength, Limit: limit}
+}
+
+// MaxBytesBody limits the size of a request body.
+//
+// Unlike `http.MaxBytesReader` the limit can be changed before the body is read,
+// and it tracks if the limit was exceeded so it can be reported after the fact.
+type MaxBytesBody struct {
+	Body          io.ReadCloser
+	ContentLength int64
+	Limit         int64
+
+	read     int64
+	exceeded bool
+}
+
+// Read implements io.Reader.
+func (mbb *MaxBytesBody) Read(p []byte) (n int, err error) {
+	if mbb.exceeded {
+		return 0, mbb.err()
+	}
+	if mbb.Limit > 0 {
+		if mbb.ContentLength > mbb.Limit {
+			mbb.exceeded = true
+			return 0, mbb.err()
+		}
+		remaining := mbb.Limit - mbb.read + 1 // read one extra byte to detect going over the limit
+		if remaining < 1 {
+			mbb.exceeded = true
+			return 0, mbb.err()
+		}
+		if int64(len(p)) > remaining {
+			p = p[:remaining]
+		}
+	}
+	n, err = mbb.Body.Read(p)
+	mbb.read += int64(n)
+	if mbb.Limit > 0 && mbb.read > mbb.Limit {
+		n -= int(mbb.read - mbb.Limit)
+		mbb.read = mbb.Limit
+		mbb.exceeded = true
+		return n, mbb.err()
+	}
+	return n, err
+}
+
+// Close implements io.Closer.
+func (mbb *MaxBytesBody) Close() error {
+	return mbb.Body.Close()
+}
+
+// Exceeded returns if the body was larger than the limit, or its declared content length is over the limit.
+func (mbb *MaxBytesBody) Exceeded() bool {
+	return mbb.exceeded || (mbb.Limit > 0 && mbb.ContentLength > mbb.Limit)
+}
+
+func (mbb *MaxBytesBody) err() error {
+	return ex.New(ErrRequestBodyTooLarge, ex.OptMessagef("limit: %d bytes", mbb.Limit))
+}
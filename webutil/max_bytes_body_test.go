@@ -0,0 +1,46 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package webutil
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func TestMaxBytesBody(t *testing.T) {
+	its := assert.New(t)
+
+	body := NewMaxBytesBody(io.NopCloser(strings.NewReader("0123456789")), -1, 10)
+	contents, err := io.ReadAll(body)
+	its.Nil(err)
+	its.Equal("0123456789", string(contents))
+	its.False(body.Exceeded())
+
+	body = NewMaxBytesBody(io.NopCloser(strings.NewReader("0123456789")), -1, 5)
+	contents, err = io.ReadAll(body)
+	its.True(ErrIsRequestBodyTooLarge(err))
+	its.Equal("01234", string(contents))
+	its.True(body.Exceeded())
+
+	// the declared content length is checked before reading.
+	body = NewMaxBytesBody(io.NopCloser(strings.NewReader("0123456789")), 10, 5)
+	contents, err = io.ReadAll(body)
+	its.True(ErrIsRequestBodyTooLarge(err))
+	its.Empty(contents)
+	its.True(body.Exceeded())
+
+	// the limit can be raised before reading.
+	body = NewMaxBytesBody(io.NopCloser(strings.NewReader("0123456789")), 10, 5)
+	body.Limit = 20
+	contents, err = io.ReadAll(body)
+	its.Nil(err)
+	its.Equal("0123456789", string(contents))
+}
@@ -0,0 +1,204 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package webutil
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/fileutil"
+)
+
+// NewMultipartReader returns a new streaming reader for the parts of a multipart request body.
+//
+// Unlike `PostedFiles` the parts are not buffered in memory; each part must be read before calling `Next`,
+// unless a spool threshold is set (see `OptMultipartSpoolThreshold`).
+func NewMultipartReader(r *http.Request, opts ...MultipartReaderOption) (*MultipartReader, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, ex.New(err)
+	}
+	mr := &MultipartReader{reader: reader}
+	for _, opt := range opts {
+		opt(mr)
+	}
+	return mr, nil
+}
+
+// MultipartReaderOption mutates a multipart reader.
+type MultipartReaderOption func(*MultipartReader)
+
+// OptMultipartMaxFileSize sets the maximum size of any one part; reading past it returns `ErrPostedFileTooLarge`.
+func OptMultipartMaxFileSize(maxFileSize int64) MultipartReaderOption {
+	return func(mr *MultipartReader) { mr.MaxFileSize = maxFileSize }
+}
+
+// OptMultipartSpoolThreshold sets the reader to read each part fully when it is returned by `Next`,
+// holding parts up to the threshold in memory and spooling larger parts to temporary files.
+//
+// Spooled parts remain readable after subsequent calls to `Next`, and must be closed to remove their temporary files,
+// either individually or all at once by closing the reader.
+func OptMultipartSpoolThreshold(spoolThreshold int64) MultipartReaderOption {
+	return func(mr *MultipartReader) { mr.SpoolThreshold = spoolThreshold }
+}
+
+// MultipartReader reads the parts of a multipart request body one at a time.
+type MultipartReader struct {
+	MaxFileSize    int64
+	SpoolThreshold int64
+
+	reader   *multipart.Reader
+	exceeded bool
+	spooled  []*MultipartPart
+}
+
+// Exceeded returns if any part was larger than the max file size.
+func (mr *MultipartReader) Exceeded() bool {
+	return mr.exceeded
+}
+
+// Next returns the next part of the body, or `io.EOF` if there are no more parts.
+func (mr *MultipartReader) Next() (*MultipartPart, error) {
+	part, err := mr.reader.NextPart()
+	if err == io.EOF {
+		return nil, err
+	}
+	if err != nil {
+		return nil, ex.New(err)
+	}
+
+	mp := &MultipartPart{
+		Key:         part.FormName(),
+		FileName:    part.FileName(),
+		ContentType: part.Header.Get(HeaderContentType),
+		Header:      part.Header,
+		Size:        -1,
+	}
+	var contents io.Reader = part
+	if mr.MaxFileSize > 0 {
+		contents = &multipartPartLimiter{mr: mr, reader: part, remaining: mr.MaxFileSize}
+	}
+	if mr.SpoolThreshold <= 0 {
+		mp.reader = contents
+		return mp, nil
+	}
+	if err = mp.spool(contents, mr.SpoolThreshold); err != nil {
+		return nil, err
+	}
+	if mp.temp != nil {
+		mr.spooled = append(mr.spooled, mp)
+	}
+	return mp, nil
+}
+
+// Close removes the temporary files of any spooled parts that have not already been closed.
+func (mr *MultipartReader) Close() error {
+	var err error
+	for _, part := range mr.spooled {
+		if closeErr := part.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	mr.spooled = nil
+	return err
+}
+
+// MultipartPart is a single part of a multipart request body.
+type MultipartPart struct {
+	Key         string
+	FileName    string
+	ContentType string
+	Header      textproto.MIMEHeader
+	// Size is the size of the part contents in bytes if it was spooled, and -1 otherwise.
+	Size int64
+
+	reader io.Reader
+	temp   *fileutil.Temp
+}
+
+// IsFile returns if the part is a file.
+func (mp *MultipartPart) IsFile() bool {
+	return mp.FileName != ""
+}
+
+// IsSpooled returns if the part contents were spooled to a temporary file.
+func (mp *MultipartPart) IsSpooled() bool {
+	return mp.temp != nil
+}
+
+// Read implements io.Reader.
+func (mp *MultipartPart) Read(p []byte) (int, error) {
+	return mp.reader.Read(p)
+}
+
+// Close removes the part's temporary file if it was spooled.
+func (mp *MultipartPart) Close() error {
+	if mp.temp == nil {
+		return nil
+	}
+	temp := mp.temp
+	mp.temp = nil
+	return temp.Close()
+}
+
+// spool reads the contents into memory up to a threshold, and then into a temp file.
+func (mp *MultipartPart) spool(contents io.Reader, threshold int64) error {
+	buffer := new(bytes.Buffer)
+	read, err := io.CopyN(buffer, contents, threshold+1)
+	if err != nil && err != io.EOF {
+		return ex.New(err)
+	}
+	if read <= threshold {
+		mp.Size = read
+		mp.reader = buffer
+		return nil
+	}
+
+	temp, err := fileutil.NewTemp(buffer.Bytes())
+	if err != nil {
+		return err
+	}
+	remaining, err := io.Copy(temp, contents)
+	if err != nil {
+		_ = temp.Close()
+		return ex.New(err)
+	}
+	if _, err = temp.Seek(0, io.SeekStart); err != nil {
+		_ = temp.Close()
+		return err
+	}
+	mp.Size = read + remaining
+	mp.reader = temp
+	mp.temp = temp
+	return nil
+}
+
+type multipartPartLimiter struct {
+	mr        *MultipartReader
+	reader    io.Reader
+	remaining int64
+}
+
+func (mpl *multipartPartLimiter) Read(p []byte) (n int, err error) {
+	if int64(len(p)) > mpl.remaining+1 {
+		p = p[:mpl.remaining+1]
+	}
+	n, err = mpl.reader.Read(p)
+	if int64(n) > mpl.remaining {
+		n = int(mpl.remaining)
+		mpl.remaining = 0
+		mpl.mr.exceeded = true
+		return n, ex.New(ErrPostedFileTooLarge, ex.OptMessagef("limit: %d bytes", mpl.mr.MaxFileSize))
+	}
+	mpl.remaining -= int64(n)
+	return n, err
+}
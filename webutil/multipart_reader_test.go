@@ -0,0 +1,168 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package webutil
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func newMultipartTestRequest(its *assert.Assertions, files map[string]string) *http.Request {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	its.Nil(writer.WriteField("name", "value"))
+	for _, key := range []string{"small", "large"} {
+		contents, ok := files[key]
+		if !ok {
+			continue
+		}
+		part, err := writer.CreateFormFile(key, key+".txt")
+		its.Nil(err)
+		_, err = io.WriteString(part, contents)
+		its.Nil(err)
+	}
+	its.Nil(writer.Close())
+
+	req, err := http.NewRequest(http.MethodPost, "/", body)
+	its.Nil(err)
+	req.Header.Set(HeaderContentType, writer.FormDataContentType())
+	return req
+}
+
+func TestMultipartReader(t *testing.T) {
+	its := assert.New(t)
+
+	req := newMultipartTestRequest(its, map[string]string{"small": "hello", "large": strings.Repeat("a", 1024)})
+	mr, err := NewMultipartReader(req)
+	its.Nil(err)
+
+	var parts []string
+	for {
+		part, err := mr.Next()
+		if err == io.EOF {
+			break
+		}
+		its.Nil(err)
+		contents, err := io.ReadAll(part)
+		its.Nil(err)
+		its.Equal(-1, part.Size)
+		parts = append(parts, part.Key+":"+part.FileName+":"+string(contents[:5]))
+	}
+	its.Equal([]string{"name::value", "small:small.txt:hello", "large:large.txt:aaaaa"}, parts)
+	its.False(mr.Exceeded())
+}
+
+func TestMultipartReaderMaxFileSize(t *testing.T) {
+	its := assert.New(t)
+
+	req := newMultipartTestRequest(its, map[string]string{"small": "hello", "large": strings.Repeat("a", 1024)})
+	mr, err := NewMultipartReader(req, OptMultipartMaxFileSize(512))
+	its.Nil(err)
+
+	for _, expected := range []string{"name", "small"} {
+		part, err := mr.Next()
+		its.Nil(err)
+		its.Equal(expected, part.Key)
+		_, err = io.ReadAll(part)
+		its.Nil(err)
+	}
+	part, err := mr.Next()
+	its.Nil(err)
+	contents, err := io.ReadAll(part)
+	its.True(ErrIsPostedFileTooLarge(err))
+	its.Len(contents, 512)
+	its.True(mr.Exceeded())
+}
+
+func TestMultipartReaderSpool(t *testing.T) {
+	its := assert.New(t)
+
+	large := strings.Repeat("a", 1024)
+	req := newMultipartTestRequest(its, map[string]string{"small": "hello", "large": large})
+	mr, err := NewMultipartReader(req, OptMultipartSpoolThreshold(64))
+	its.Nil(err)
+
+	var parts []*MultipartPart
+	for {
+		part, err := mr.Next()
+		if err == io.EOF {
+			break
+		}
+		its.Nil(err)
+		parts = append(parts, part)
+	}
+	its.Len(parts, 3)
+
+	// spooled parts can be read after reading later parts.
+	its.False(parts[1].IsSpooled())
+	its.Equal(5, parts[1].Size)
+	contents, err := io.ReadAll(parts[1])
+	its.Nil(err)
+	its.Equal("hello", string(contents))
+
+	its.True(parts[2].IsSpooled())
+	its.Equal(1024, parts[2].Size)
+	tempPath := parts[2].temp.Name()
+	contents, err = io.ReadAll(parts[2])
+	its.Nil(err)
+	its.Equal(large, string(contents))
+
+	for _, part := range parts {
+		its.Nil(part.Close())
+	}
+	_, err = os.Stat(tempPath)
+	its.True(os.IsNotExist(err))
+}
+
+func TestMultipartReaderSpoolMaxFileSize(t *testing.T) {
+	its := assert.New(t)
+
+	req := newMultipartTestRequest(its, map[string]string{"large": strings.Repeat("a", 1024)})
+	mr, err := NewMultipartReader(req, OptMultipartSpoolThreshold(64), OptMultipartMaxFileSize(512))
+	its.Nil(err)
+
+	_, err = mr.Next()
+	its.Nil(err)
+	_, err = mr.Next()
+	its.True(ErrIsPostedFileTooLarge(err))
+	its.True(mr.Exceeded())
+}
+
+func TestMultipartReaderClose(t *testing.T) {
+	its := assert.New(t)
+
+	req := newMultipartTestRequest(its, map[string]string{"large": strings.Repeat("a", 1024)})
+	mr, err := NewMultipartReader(req, OptMultipartSpoolThreshold(64))
+	its.Nil(err)
+
+	var parts []*MultipartPart
+	for {
+		part, err := mr.Next()
+		if err == io.EOF {
+			break
+		}
+		its.Nil(err)
+		parts = append(parts, part)
+	}
+	its.Len(parts, 2)
+	its.True(parts[1].IsSpooled())
+	tempPath := parts[1].temp.Name()
+
+	its.Nil(mr.Close())
+	_, err = os.Stat(tempPath)
+	its.True(os.IsNotExist(err))
+	its.False(parts[1].IsSpooled())
+	its.Nil(parts[1].Close())
+}
@@ -0,0 +1,115 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package webutil
+
+import (
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AcceptValue is a single media range from an `Accept` header.
+type AcceptValue struct {
+	MediaType string
+	Q         float64
+}
+
+// ParseAccept parses an `Accept` header into media ranges ordered by preference.
+//
+// Values are ordered by quality (q-value), then by specificity, so `text/html`
+// sorts before `text/*`, which sorts before `*/*`. Ranges with a q-value of zero are kept,
+// as they explicitly mark a media type as not acceptable.
+func ParseAccept(header string) []AcceptValue {
+	var values []AcceptValue
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		value := AcceptValue{MediaType: mediaType, Q: 1}
+		if q, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil && parsed >= 0 && parsed <= 1 {
+				value.Q = parsed
+			}
+		}
+		values = append(values, value)
+	}
+	sort.SliceStable(values, func(i, j int) bool {
+		if values[i].Q != values[j].Q {
+			return values[i].Q > values[j].Q
+		}
+		return mediaTypeSpecificity(values[i].MediaType) > mediaTypeSpecificity(values[j].MediaType)
+	})
+	return values
+}
+
+// NegotiateContentType returns the offered media type that best matches an `Accept` header.
+//
+// If the header is empty, the first offer is returned. If no offer is acceptable, an empty string is returned.
+// Offers earlier in the list are preferred when the client considers several equally acceptable.
+func NegotiateContentType(header string, offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	if strings.TrimSpace(header) == "" {
+		return offers[0]
+	}
+
+	accepted := ParseAccept(header)
+	bestOffer, bestQ, bestSpecificity := "", 0.0, -1
+	for _, offer := range offers {
+		// find the most specific range that matches this offer, it determines the offer's q-value.
+		q, specificity := 0.0, -1
+		for _, value := range accepted {
+			if !mediaTypeMatches(value.MediaType, offer) {
+				continue
+			}
+			if valueSpecificity := mediaTypeSpecificity(value.MediaType); valueSpecificity > specificity {
+				q, specificity = value.Q, valueSpecificity
+			}
+		}
+		if q > bestQ || (q == bestQ && q > 0 && specificity > bestSpecificity) {
+			bestOffer, bestQ, bestSpecificity = offer, q, specificity
+		}
+	}
+	return bestOffer
+}
+
+func mediaTypeMatches(mediaRange, mediaType string) bool {
+	if mediaRange == "*/*" {
+		return true
+	}
+	rangeType, rangeSubtype := splitMediaType(mediaRange)
+	offerType, offerSubtype := splitMediaType(mediaType)
+	if rangeType != offerType {
+		return false
+	}
+	return rangeSubtype == "*" || rangeSubtype == offerSubtype
+}
+
+func mediaTypeSpecificity(mediaRange string) int {
+	if mediaRange == "*/*" {
+		return 0
+	}
+	if _, subtype := splitMediaType(mediaRange); subtype == "*" {
+		return 1
+	}
+	return 2
+}
+
+func splitMediaType(mediaType string) (string, string) {
+	if index := strings.IndexByte(mediaType, '/'); index >= 0 {
+		return mediaType[:index], mediaType[index+1:]
+	}
+	return mediaType, ""
+}
@@ -0,0 +1,48 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package webutil
+
+import (
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func TestParseAccept(t *testing.T) {
+	its := assert.New(t)
+
+	values := ParseAccept("text/*;q=0.5, */*;q=0.1, application/json, text/html;q=0.5, bogus/;")
+	its.Equal([]AcceptValue{
+		{MediaType: "application/json", Q: 1},
+		{MediaType: "text/html", Q: 0.5},
+		{MediaType: "text/*", Q: 0.5},
+		{MediaType: "*/*", Q: 0.1},
+	}, values)
+}
+
+func TestNegotiateContentType(t *testing.T) {
+	its := assert.New(t)
+
+	offers := []string{"application/json", "application/xml", "text/plain", "text/html"}
+	testCases := [...]struct {
+		Accept   string
+		Expected string
+	}{
+		{Accept: "", Expected: "application/json"},
+		{Accept: "*/*", Expected: "application/json"},
+		{Accept: "application/xml", Expected: "application/xml"},
+		{Accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", Expected: "text/html"},
+		{Accept: "text/*", Expected: "text/plain"},
+		{Accept: "text/*, text/plain;q=0", Expected: "text/html"},
+		{Accept: "application/json;q=0.2, application/xml;q=0.8", Expected: "application/xml"},
+		{Accept: "image/png", Expected: ""},
+	}
+	for _, tc := range testCases {
+		its.Equal(tc.Expected, NegotiateContentType(tc.Accept, offers...), tc.Accept)
+	}
+}